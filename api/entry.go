@@ -0,0 +1,36 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/arpangoswami/backend-golang-dev/service"
+	"github.com/gin-gonic/gin"
+)
+
+type listEntriesRequest struct {
+	listFilterRequest
+	AccountID int64  `form:"account_id" binding:"required,min=1"`
+	Cursor    string `form:"cursor"`
+	PageSize  int32  `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+func (server *Server) listEntries(ctx *gin.Context) {
+	var req listEntriesRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	arg := service.ListEntriesParams{
+		AccountIDs: []int64{req.AccountID},
+		Filter:     req.filter(),
+		Cursor:     req.Cursor,
+		PageSize:   req.PageSize,
+	}
+	page, err := server.bank.ListEntries(ctx, arg)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, page)
+}
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+	"github.com/arpangoswami/backend-golang-dev/pagination"
+	"github.com/arpangoswami/backend-golang-dev/service"
+	"github.com/arpangoswami/backend-golang-dev/webhook"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Postgres error codes that are mapped to client errors
+const (
+	foreignKeyViolation = "23503"
+	uniqueViolation     = "23505"
+	checkViolation      = "23514"
+)
+
+// errorResponse is the JSON body returned for every failed request
+func errorResponse(err error) gin.H {
+	return gin.H{"error": err.Error()}
+}
+
+// errorStatus maps an error returned by the store to the HTTP status code sent to the client
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrUnauthenticated):
+		return http.StatusUnauthorized
+	case errors.Is(err, service.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, service.ErrCurrencyMismatch), errors.Is(err, pagination.ErrInvalidCursor),
+		errors.Is(err, webhook.ErrForbiddenDestination):
+		return http.StatusBadRequest
+	case errors.Is(err, db.ErrAccountFrozen):
+		return http.StatusForbidden
+	case errors.Is(err, db.ErrTransferArchived):
+		return http.StatusGone
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		// the same classes as the gRPC codes of gapi: AlreadyExists and FailedPrecondition,
+		// which the gateway also answers with 409 and 400
+		switch pgErr.Code {
+		case uniqueViolation:
+			return http.StatusConflict
+		case foreignKeyViolation, checkViolation:
+			return http.StatusBadRequest
+		}
+	}
+	return http.StatusInternalServerError
+}
+
+// abortWithError writes the error body with the status derived from a store error
+func abortWithError(ctx *gin.Context, err error) {
+	ctx.JSON(errorStatus(err), errorResponse(err))
+}
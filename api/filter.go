@@ -0,0 +1,31 @@
+package api
+
+import (
+	"time"
+
+	"github.com/arpangoswami/backend-golang-dev/service"
+)
+
+// listFilterRequest holds the query parameters shared by the entry and transfer listings
+type listFilterRequest struct {
+	Direction string    `form:"direction" binding:"omitempty,oneof=in out"`
+	StartTime time.Time `form:"start_time" time_format:"2006-01-02T15:04:05Z07:00"`
+	EndTime   time.Time `form:"end_time" time_format:"2006-01-02T15:04:05Z07:00" binding:"omitempty,gtfield=StartTime"`
+	MinAmount float64   `form:"min_amount" binding:"min=0"`
+	MaxAmount float64   `form:"max_amount" binding:"omitempty,gtefield=MinAmount"`
+	Order     string    `form:"order" binding:"omitempty,oneof=asc desc"`
+	// IncludeArchived also lists the rows moved to the archive
+	IncludeArchived bool `form:"include_archived"`
+}
+
+func (req listFilterRequest) filter() service.ListFilter {
+	return service.ListFilter{
+		Direction:       service.Direction(req.Direction),
+		StartTime:       req.StartTime,
+		EndTime:         req.EndTime,
+		MinAmount:       req.MinAmount,
+		MaxAmount:       req.MaxAmount,
+		Descending:      req.Order == "desc",
+		IncludeArchived: req.IncludeArchived,
+	}
+}
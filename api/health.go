@@ -0,0 +1,43 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReadinessCheck reports whether the dependencies needed to serve traffic are available
+type ReadinessCheck func(ctx context.Context) error
+
+var errDraining = errors.New("server is shutting down")
+
+// SetReadinessCheck installs the check run by the readiness endpoint
+func (server *Server) SetReadinessCheck(check ReadinessCheck) {
+	server.readinessCheck = check
+}
+
+// Drain makes the readiness endpoint fail so that load balancers stop sending new requests
+func (server *Server) Drain() {
+	server.draining.Store(true)
+}
+
+// liveness only tells that the process is up and serving HTTP
+func (server *Server) liveness(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+func (server *Server) readiness(ctx *gin.Context) {
+	if server.draining.Load() {
+		ctx.JSON(http.StatusServiceUnavailable, errorResponse(errDraining))
+		return
+	}
+	if server.readinessCheck != nil {
+		if err := server.readinessCheck(ctx); err != nil {
+			ctx.JSON(http.StatusServiceUnavailable, errorResponse(err))
+			return
+		}
+	}
+	ctx.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	mockdb "github.com/arpangoswami/backend-golang-dev/database/mock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestServer_Liveness(t *testing.T) {
+	server := newTestServer(t, mockdb.NewMockStore(gomock.NewController(t)))
+	server.SetReadinessCheck(func(ctx context.Context) error {
+		return errors.New("database is down")
+	})
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "/livez", nil)
+	assert.NoError(t, err)
+	server.router.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestServer_Readiness(t *testing.T) {
+	testCases := []struct {
+		name  string
+		check ReadinessCheck
+		drain bool
+		code  int
+	}{
+		{
+			name: "NoCheck",
+			code: http.StatusOK,
+		},
+		{
+			name:  "Ready",
+			check: func(ctx context.Context) error { return nil },
+			code:  http.StatusOK,
+		},
+		{
+			name:  "CheckFails",
+			check: func(ctx context.Context) error { return errors.New("schema version 3, want 4") },
+			code:  http.StatusServiceUnavailable,
+		},
+		{
+			name:  "Draining",
+			check: func(ctx context.Context) error { return nil },
+			drain: true,
+			code:  http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := newTestServer(t, mockdb.NewMockStore(gomock.NewController(t)))
+			if tc.check != nil {
+				server.SetReadinessCheck(tc.check)
+			}
+			if tc.drain {
+				server.Drain()
+			}
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, "/readyz", nil)
+			assert.NoError(t, err)
+			server.router.ServeHTTP(recorder, request)
+			assert.Equal(t, tc.code, recorder.Code)
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+	"github.com/arpangoswami/backend-golang-dev/token"
+	"github.com/arpangoswami/backend-golang-dev/util"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestServer(t *testing.T, store db.Store) *Server {
+	t.Helper()
+	tokenMaker, err := token.NewPasetoMaker(util.RandomString(32))
+	assert.NoError(t, err)
+	return NewServer(store, tokenMaker, time.Minute, time.Hour)
+}
+
+func addAuthorization(
+	t *testing.T,
+	request *http.Request,
+	tokenMaker token.Maker,
+	authorizationType string,
+	username string,
+	duration time.Duration,
+) {
+	t.Helper()
+	accessToken, payload, err := tokenMaker.CreateToken(username, token.TokenTypeAccess, duration)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, payload)
+
+	authorizationHeader := fmt.Sprintf("%s %s", authorizationType, accessToken)
+	request.Header.Set(authorizationHeaderKey, authorizationHeader)
+}
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	os.Exit(m.Run())
+}
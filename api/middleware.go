@@ -0,0 +1,76 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+	"github.com/arpangoswami/backend-golang-dev/token"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	authorizationHeaderKey  = "authorization"
+	authorizationTypeBearer = "bearer"
+	authorizationPayloadKey = "authorization_payload"
+	requestIDHeaderKey      = "X-Request-ID"
+)
+
+// requestIDMiddleware tags the request with the client's X-Request-ID, or a new one when it is
+// missing or not a valid ID (see db.ValidRequestID), and echoes it in the response. The ID ends
+// up in the audit log of the changes made by the request.
+func requestIDMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		requestID := ctx.GetHeader(requestIDHeaderKey)
+		if !db.ValidRequestID(requestID) {
+			requestID = uuid.NewString()
+		}
+		ctx.Header(requestIDHeaderKey, requestID)
+		ctx.Request = ctx.Request.WithContext(db.WithRequestID(ctx.Request.Context(), requestID))
+		ctx.Next()
+	}
+}
+
+// authMiddleware verifies the bearer token of the request and injects the authenticated
+// user into both the gin context and the request context, where it is also the audited actor
+func authMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authorizationHeader := ctx.GetHeader(authorizationHeaderKey)
+		if len(authorizationHeader) == 0 {
+			err := errors.New("authorization header is not provided")
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+
+		fields := strings.Fields(authorizationHeader)
+		if len(fields) < 2 {
+			err := errors.New("invalid authorization header format")
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+
+		authorizationType := strings.ToLower(fields[0])
+		if authorizationType != authorizationTypeBearer {
+			err := fmt.Errorf("unsupported authorization type %s", authorizationType)
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+
+		payload, err := tokenMaker.VerifyToken(fields[1])
+		if err == nil && payload.TokenType != token.TokenTypeAccess {
+			err = token.ErrWrongTokenType
+		}
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+
+		ctx.Set(authorizationPayloadKey, payload)
+		requestCtx := db.WithActor(ctx.Request.Context(), payload.Username)
+		ctx.Request = ctx.Request.WithContext(token.NewContext(requestCtx, payload))
+		ctx.Next()
+	}
+}
@@ -0,0 +1,145 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+	"github.com/arpangoswami/backend-golang-dev/token"
+	"github.com/arpangoswami/backend-golang-dev/util"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthMiddleware(t *testing.T) {
+	username := util.RandomOwner()
+
+	testCases := []struct {
+		name          string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, username, time.Minute)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "NoAuthorization",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "UnsupportedAuthorization",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, "unsupported", username, time.Minute)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidAuthorizationFormat",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, "", username, time.Minute)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "ExpiredToken",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, username, -time.Minute)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "RefreshToken",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				refreshToken, _, err := tokenMaker.CreateToken(username, token.TokenTypeRefresh, time.Minute)
+				assert.NoError(t, err)
+				request.Header.Set(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationTypeBearer, refreshToken))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := newTestServer(t, nil)
+
+			authPath := "/auth"
+			server.router.GET(authPath, authMiddleware(server.tokenMaker), func(ctx *gin.Context) {
+				payload, ok := token.FromContext(ctx)
+				assert.True(t, ok)
+				assert.Equal(t, username, payload.Username)
+				assert.Equal(t, username, db.AuditFromContext(ctx).Actor)
+				ctx.JSON(http.StatusOK, gin.H{})
+			})
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, authPath, nil)
+			assert.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestRequestIDMiddleware(t *testing.T) {
+	server := newTestServer(t, nil)
+
+	var requestIDs []string
+	server.router.GET("/request_id", func(ctx *gin.Context) {
+		requestIDs = append(requestIDs, db.AuditFromContext(ctx).RequestID)
+		ctx.JSON(http.StatusOK, gin.H{})
+	})
+
+	// the client's ID is kept
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "/request_id", nil)
+	assert.NoError(t, err)
+	request.Header.Set(requestIDHeaderKey, "req-123")
+	server.router.ServeHTTP(recorder, request)
+	assert.Equal(t, "req-123", recorder.Header().Get(requestIDHeaderKey))
+
+	// a new one is generated otherwise
+	recorder = httptest.NewRecorder()
+	request, err = http.NewRequest(http.MethodGet, "/request_id", nil)
+	assert.NoError(t, err)
+	server.router.ServeHTTP(recorder, request)
+	assert.NotEmpty(t, recorder.Header().Get(requestIDHeaderKey))
+
+	assert.Equal(t, []string{"req-123", recorder.Header().Get(requestIDHeaderKey)}, requestIDs)
+
+	// and in place of an ID that is too long or has other characters than letters, digits and dashes
+	for _, invalid := range []string{strings.Repeat("a", 65), "req-123\nforged: yes", "req 123"} {
+		recorder = httptest.NewRecorder()
+		request, err = http.NewRequest(http.MethodGet, "/request_id", nil)
+		assert.NoError(t, err)
+		request.Header.Set(requestIDHeaderKey, invalid)
+		server.router.ServeHTTP(recorder, request)
+		requestID := recorder.Header().Get(requestIDHeaderKey)
+		assert.NoError(t, uuid.Validate(requestID))
+		assert.Equal(t, requestID, requestIDs[len(requestIDs)-1])
+	}
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+	"github.com/arpangoswami/backend-golang-dev/service"
+	"github.com/arpangoswami/backend-golang-dev/token"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// Server serves HTTP requests for our banking service
+type Server struct {
+	store                db.Store
+	bank                 *service.Bank
+	tokenMaker           token.Maker
+	accessTokenDuration  time.Duration
+	refreshTokenDuration time.Duration
+	router               *gin.Engine
+	readinessCheck       ReadinessCheck
+	draining             atomic.Bool
+}
+
+// NewServer creates a new HTTP server and sets up routing
+func NewServer(store db.Store, tokenMaker token.Maker, accessTokenDuration, refreshTokenDuration time.Duration) *Server {
+	server := &Server{
+		store:                store,
+		bank:                 service.NewBank(store),
+		tokenMaker:           tokenMaker,
+		accessTokenDuration:  accessTokenDuration,
+		refreshTokenDuration: refreshTokenDuration,
+	}
+
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterValidation("currency", validCurrency)
+		v.RegisterValidation("event_type", validEventType)
+	}
+
+	server.setupRouter()
+	return server
+}
+
+func (server *Server) setupRouter() {
+	router := gin.Default()
+	// let handlers pass the gin context down to the store while keeping
+	// values injected into the request context, such as the token payload
+	router.ContextWithFallback = true
+	router.Use(requestIDMiddleware())
+
+	router.GET("/livez", server.liveness)
+	router.GET("/readyz", server.readiness)
+
+	router.POST("/users", server.createUser)
+	router.POST("/users/login", server.loginUser)
+	router.POST("/tokens/renew_access", server.renewAccessToken)
+
+	authRoutes := router.Group("/").Use(authMiddleware(server.tokenMaker))
+
+	authRoutes.POST("/accounts", server.createAccount)
+	authRoutes.GET("/accounts/:id", server.getAccount)
+	authRoutes.GET("/accounts", server.listAccounts)
+
+	authRoutes.GET("/entries", server.listEntries)
+
+	authRoutes.POST("/transfers", server.createTransfer)
+	authRoutes.GET("/transfers/:id", server.getTransfer)
+	authRoutes.GET("/transfers", server.listTransfers)
+
+	authRoutes.GET("/sessions", server.listSessions)
+	authRoutes.DELETE("/sessions/:id", server.revokeSession)
+
+	authRoutes.POST("/webhooks", server.createWebhookSubscription)
+	authRoutes.GET("/webhooks", server.listWebhookSubscriptions)
+	authRoutes.DELETE("/webhooks/:id", server.deleteWebhookSubscription)
+	authRoutes.GET("/webhooks/:id/deliveries", server.listWebhookDeliveries)
+	authRoutes.POST("/webhooks/:id/deliveries/:delivery_id/replay", server.replayWebhookDelivery)
+
+	server.router = router
+}
+
+// Handler returns the routes of the server, to be served by an http.Server
+func (server *Server) Handler() http.Handler {
+	return server.router
+}
+
+// Start runs the HTTP server on a specific address
+func (server *Server) Start(address string) error {
+	return server.router.Run(address)
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/arpangoswami/backend-golang-dev/database/mock"
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+	"github.com/arpangoswami/backend-golang-dev/util"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestServer_ListSessions(t *testing.T) {
+	username := util.RandomOwner()
+	sessions := []db.Session{
+		{ID: uuid.New(), Username: username, RefreshToken: util.RandomString(32)},
+		{ID: uuid.New(), Username: username, RefreshToken: util.RandomString(32)},
+	}
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	arg := db.ListSessionsParams{Username: username, Limit: 5, Offset: 0}
+	store.EXPECT().ListSessions(gomock.Any(), gomock.Eq(arg)).Times(1).Return(sessions, nil)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "/sessions?page_id=1&page_size=5", nil)
+	assert.NoError(t, err)
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, username, time.Minute)
+
+	server.router.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.NotContains(t, recorder.Body.String(), sessions[0].RefreshToken)
+
+	var got []sessionResponse
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+	assert.Len(t, got, len(sessions))
+}
+
+func TestServer_RevokeSession(t *testing.T) {
+	username := util.RandomOwner()
+	sessionID := uuid.New()
+
+	testCases := []struct {
+		name       string
+		sessionID  string
+		buildStubs func(store *mockdb.MockStore)
+		code       int
+	}{
+		{
+			name:      "OK",
+			sessionID: sessionID.String(),
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.RevokeSessionParams{ID: sessionID, Username: username}
+				store.EXPECT().RevokeSession(gomock.Any(), gomock.Eq(arg)).Times(1).
+					Return(db.Session{ID: sessionID, Username: username, IsRevoked: true}, nil)
+			},
+			code: http.StatusOK,
+		},
+		{
+			name:      "NotFound",
+			sessionID: sessionID.String(),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().RevokeSession(gomock.Any(), gomock.Any()).Times(1).Return(db.Session{}, sql.ErrNoRows)
+			},
+			code: http.StatusNotFound,
+		},
+		{
+			name:      "InvalidID",
+			sessionID: "not-a-uuid",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().RevokeSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/sessions/%s", tc.sessionID), nil)
+			assert.NoError(t, err)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, username, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			assert.Equal(t, tc.code, recorder.Code)
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/arpangoswami/backend-golang-dev/service"
+	"github.com/gin-gonic/gin"
+)
+
+type createTransferRequest struct {
+	FromAccountID int64   `json:"from_account_id" binding:"required,min=1"`
+	ToAccountID   int64   `json:"to_account_id" binding:"required,min=1,nefield=FromAccountID"`
+	Amount        float64 `json:"amount" binding:"required,gt=0"`
+	Currency      string  `json:"currency" binding:"required,currency"`
+}
+
+func (server *Server) createTransfer(ctx *gin.Context) {
+	var req createTransferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	arg := service.TransferParams{
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+		Currency:      req.Currency,
+	}
+	result, err := server.bank.Transfer(ctx, arg)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
+}
+
+type getTransferRequest struct {
+	ID              int64 `uri:"id" binding:"required,min=1"`
+	IncludeArchived bool  `form:"include_archived"`
+}
+
+func (server *Server) getTransfer(ctx *gin.Context) {
+	var req getTransferRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	transfer, err := server.bank.GetTransfer(ctx, req.ID, req.IncludeArchived)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, transfer)
+}
+
+type listTransfersRequest struct {
+	listFilterRequest
+	AccountID int64  `form:"account_id" binding:"required,min=1"`
+	Cursor    string `form:"cursor"`
+	PageSize  int32  `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+func (server *Server) listTransfers(ctx *gin.Context) {
+	var req listTransfersRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	arg := service.ListTransfersParams{
+		AccountID: req.AccountID,
+		Filter:    req.filter(),
+		Cursor:    req.Cursor,
+		PageSize:  req.PageSize,
+	}
+	page, err := server.bank.ListTransfers(ctx, arg)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, page)
+}
@@ -0,0 +1,134 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+	"github.com/arpangoswami/backend-golang-dev/service"
+	"github.com/gin-gonic/gin"
+)
+
+type webhookSubscriptionResponse struct {
+	ID         int64     `json:"id"`
+	Url        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	Secret     string    `json:"secret,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// newWebhookSubscriptionResponse leaves out the secret, which is only handed out when the subscription is created
+func newWebhookSubscriptionResponse(subscription db.WebhookSubscription) webhookSubscriptionResponse {
+	return webhookSubscriptionResponse{
+		ID:         subscription.ID,
+		Url:        subscription.Url,
+		EventTypes: subscription.EventTypes,
+		CreatedAt:  subscription.CreatedAt,
+	}
+}
+
+type createWebhookSubscriptionRequest struct {
+	Url        string   `json:"url" binding:"required,http_url"`
+	EventTypes []string `json:"event_types" binding:"required,min=1,unique,dive,event_type"`
+}
+
+func (server *Server) createWebhookSubscription(ctx *gin.Context) {
+	var req createWebhookSubscriptionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	subscription, err := server.bank.CreateWebhookSubscription(ctx, req.Url, req.EventTypes)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+	rsp := newWebhookSubscriptionResponse(subscription)
+	rsp.Secret = subscription.Secret
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+func (server *Server) listWebhookSubscriptions(ctx *gin.Context) {
+	subscriptions, err := server.bank.ListWebhookSubscriptions(ctx)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	rsp := make([]webhookSubscriptionResponse, len(subscriptions))
+	for i, subscription := range subscriptions {
+		rsp[i] = newWebhookSubscriptionResponse(subscription)
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type webhookSubscriptionURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) deleteWebhookSubscription(ctx *gin.Context) {
+	var req webhookSubscriptionURI
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	subscription, err := server.bank.DeleteWebhookSubscription(ctx, req.ID)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, newWebhookSubscriptionResponse(subscription))
+}
+
+type listWebhookDeliveriesRequest struct {
+	Status   string `form:"status" binding:"omitempty,oneof=pending succeeded dead"`
+	AfterID  int64  `form:"after_id" binding:"min=0"`
+	PageSize int32  `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+func (server *Server) listWebhookDeliveries(ctx *gin.Context) {
+	var uri webhookSubscriptionURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	var req listWebhookDeliveriesRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	deliveries, err := server.bank.ListWebhookDeliveries(ctx, service.ListWebhookDeliveriesParams{
+		SubscriptionID: uri.ID,
+		Status:         req.Status,
+		AfterID:        req.AfterID,
+		PageSize:       req.PageSize,
+	})
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, deliveries)
+}
+
+type replayWebhookDeliveryRequest struct {
+	ID         int64 `uri:"id" binding:"required,min=1"`
+	DeliveryID int64 `uri:"delivery_id" binding:"required,min=1"`
+}
+
+func (server *Server) replayWebhookDelivery(ctx *gin.Context) {
+	var req replayWebhookDeliveryRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	delivery, err := server.bank.ReplayWebhookDelivery(ctx, req.ID, req.DeliveryID)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, delivery)
+}
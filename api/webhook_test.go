@@ -0,0 +1,224 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/arpangoswami/backend-golang-dev/database/mock"
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+	"github.com/arpangoswami/backend-golang-dev/util"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestServer_CreateWebhookSubscription(t *testing.T) {
+	username := util.RandomOwner()
+
+	testCases := []struct {
+		name          string
+		body          map[string]any
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: map[string]any{"url": "https://example.com/hook", "event_types": []string{db.EventTransferCompleted}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhookSubscription(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateWebhookSubscriptionParams) (db.WebhookSubscription, error) {
+						return db.WebhookSubscription{ID: 1, Owner: arg.Owner, Url: arg.Url, EventTypes: arg.EventTypes, Secret: arg.Secret}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				var got webhookSubscriptionResponse
+				assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				assert.Equal(t, "https://example.com/hook", got.Url)
+				assert.NotEmpty(t, got.Secret)
+			},
+		},
+		{
+			name: "InvalidURL",
+			body: map[string]any{"url": "ftp://example.com", "event_types": []string{db.EventTransferCompleted}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhookSubscription(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "PrivateURL",
+			body: map[string]any{"url": "https://10.0.0.7/hook", "event_types": []string{db.EventTransferCompleted}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhookSubscription(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "UnknownEventType",
+			body: map[string]any{"url": "https://example.com/hook", "event_types": []string{"transfer.exploded"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhookSubscription(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NoEventType",
+			body: map[string]any{"url": "https://example.com/hook", "event_types": []string{}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhookSubscription(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+			data, err := json.Marshal(tc.body)
+			assert.NoError(t, err)
+			request, err := http.NewRequest(http.MethodPost, "/webhooks", bytes.NewReader(data))
+			assert.NoError(t, err)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, username, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestServer_ListWebhookSubscriptions(t *testing.T) {
+	username := util.RandomOwner()
+	subscriptions := []db.WebhookSubscription{
+		{ID: 1, Owner: username, Url: "https://example.com/a", Secret: "whsec_a"},
+		{ID: 2, Owner: username, Url: "https://example.com/b", Secret: "whsec_b"},
+	}
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().ListWebhookSubscriptions(gomock.Any(), gomock.Eq(username)).Times(1).Return(subscriptions, nil)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "/webhooks", nil)
+	assert.NoError(t, err)
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, username, time.Minute)
+
+	server.router.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.NotContains(t, recorder.Body.String(), "whsec_")
+
+	var got []webhookSubscriptionResponse
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+	assert.Len(t, got, len(subscriptions))
+}
+
+func TestServer_ListWebhookDeliveries(t *testing.T) {
+	subscription := db.WebhookSubscription{ID: 4, Owner: util.RandomOwner()}
+
+	testCases := []struct {
+		name       string
+		query      string
+		username   string
+		buildStubs func(store *mockdb.MockStore)
+		code       int
+	}{
+		{
+			name:     "OK",
+			query:    "status=dead&after_id=3&page_size=5",
+			username: subscription.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetWebhookSubscription(gomock.Any(), gomock.Eq(subscription.ID)).Times(1).Return(subscription, nil)
+				store.EXPECT().ListWebhookDeliveries(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, arg db.ListWebhookDeliveriesParams) ([]db.WebhookDelivery, error) {
+						assert.Equal(t, db.DeliveryDead, arg.Status.String)
+						assert.Equal(t, int64(3), arg.AfterID)
+						return []db.WebhookDelivery{{ID: 4, SubscriptionID: subscription.ID, Status: db.DeliveryDead}}, nil
+					})
+			},
+			code: http.StatusOK,
+		},
+		{
+			name:     "Forbidden",
+			query:    "page_size=5",
+			username: "someoneelse",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetWebhookSubscription(gomock.Any(), gomock.Eq(subscription.ID)).Times(1).Return(subscription, nil)
+				store.EXPECT().ListWebhookDeliveries(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: http.StatusForbidden,
+		},
+		{
+			name:     "InvalidStatus",
+			query:    "status=lost&page_size=5",
+			username: subscription.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListWebhookDeliveries(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+			url := fmt.Sprintf("/webhooks/%d/deliveries?%s", subscription.ID, tc.query)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			assert.NoError(t, err)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			assert.Equal(t, tc.code, recorder.Code)
+		})
+	}
+}
+
+func TestServer_ReplayWebhookDelivery(t *testing.T) {
+	subscription := db.WebhookSubscription{ID: 4, Owner: util.RandomOwner()}
+	delivery := db.WebhookDelivery{ID: 6, SubscriptionID: subscription.ID, Status: db.DeliveryDead, Attempts: 8}
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetWebhookSubscription(gomock.Any(), gomock.Eq(subscription.ID)).Times(1).Return(subscription, nil)
+	store.EXPECT().GetWebhookDelivery(gomock.Any(), gomock.Eq(delivery.ID)).Times(1).Return(delivery, nil)
+	store.EXPECT().ReplayWebhookDelivery(gomock.Any(), gomock.Eq(delivery.ID)).Times(1).
+		Return(db.WebhookDelivery{ID: delivery.ID, SubscriptionID: subscription.ID, Status: db.DeliveryPending}, nil)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+	url := fmt.Sprintf("/webhooks/%d/deliveries/%d/replay", subscription.ID, delivery.ID)
+	request, err := http.NewRequest(http.MethodPost, url, nil)
+	assert.NoError(t, err)
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, subscription.Owner, time.Minute)
+
+	server.router.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var got db.WebhookDelivery
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+	assert.Equal(t, db.DeliveryPending, got.Status)
+	assert.Zero(t, got.Attempts)
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+	"github.com/arpangoswami/backend-golang-dev/util"
+)
+
+func (cli *CLI) accounts(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: missing accounts subcommand", errUsage)
+	}
+
+	subcommand, args := args[0], args[1:]
+	switch subcommand {
+	case "create":
+		return cli.createAccount(ctx, args)
+	case "list":
+		return cli.listAccounts(ctx, args)
+	case "show":
+		return cli.showAccount(ctx, args)
+	case "freeze":
+		return cli.setAccountFrozen(ctx, "freeze", args, true)
+	case "unfreeze":
+		return cli.setAccountFrozen(ctx, "unfreeze", args, false)
+	}
+	return fmt.Errorf("%w: unknown accounts subcommand %q", errUsage, subcommand)
+}
+
+func (cli *CLI) createAccount(ctx context.Context, args []string) error {
+	flags := cli.newFlagSet("accounts create")
+	owner := flags.String("owner", "", "username owning the account")
+	currency := flags.String("currency", "", "currency of the account")
+	balance := flags.Float64("balance", 0, "opening balance, recorded as an entry")
+	if err := parse(flags, args, "owner", "currency"); err != nil {
+		return err
+	}
+	if !util.IsSupportedCurrency(*currency) {
+		return fmt.Errorf("%w: unsupported currency %q", errUsage, *currency)
+	}
+	if *balance < 0 {
+		return fmt.Errorf("%w: opening balance must not be negative", errUsage)
+	}
+
+	account, err := cli.store.OpenAccountTransaction(ctx, db.CreateAccountParams{
+		Owner:       *owner,
+		Balance:     *balance,
+		Currency:    *currency,
+		CountryCode: util.CountryCodeForCurrency(*currency),
+	})
+	if err != nil {
+		return err
+	}
+	return cli.printAccounts([]db.Account{account})
+}
+
+func (cli *CLI) listAccounts(ctx context.Context, args []string) error {
+	flags := cli.newFlagSet("accounts list")
+	owner := flags.String("owner", "", "only list the accounts of this username")
+	limit := flags.Int("limit", 50, "maximum number of accounts")
+	offset := flags.Int("offset", 0, "number of accounts to skip")
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+
+	var accounts []db.Account
+	var err error
+	if *owner != "" {
+		accounts, err = cli.store.ListAccountsByOwner(ctx, db.ListAccountsByOwnerParams{
+			Owner:  *owner,
+			Limit:  int32(*limit),
+			Offset: int32(*offset),
+		})
+	} else {
+		accounts, err = cli.store.ListAccounts(ctx, db.ListAccountsParams{
+			Limit:  int32(*limit),
+			Offset: int32(*offset),
+		})
+	}
+	if err != nil {
+		return err
+	}
+	return cli.printAccounts(accounts)
+}
+
+type accountDetails struct {
+	Account       db.Account `json:"account"`
+	RecentEntries []db.Entry `json:"recent_entries"`
+}
+
+func (cli *CLI) showAccount(ctx context.Context, args []string) error {
+	flags := cli.newFlagSet("accounts show")
+	id := flags.Int64("id", 0, "account id")
+	entries := flags.Int("entries", 10, "number of recent entries to show")
+	if err := parse(flags, args, "id"); err != nil {
+		return err
+	}
+
+	account, err := cli.store.GetAccount(ctx, *id)
+	if err != nil {
+		return fmt.Errorf("account [%d]: %w", *id, err)
+	}
+	recentEntries, err := cli.store.ListLatestEntries(ctx, db.ListLatestEntriesParams{
+		AccountID: account.ID,
+		Limit:     int32(*entries),
+	})
+	if err != nil {
+		return err
+	}
+
+	details := accountDetails{Account: account, RecentEntries: recentEntries}
+	return cli.print(details, func(w io.Writer) {
+		writeAccounts(w, []db.Account{account})
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "ENTRY\tAMOUNT\tCREATED AT")
+		for _, entry := range recentEntries {
+			fmt.Fprintf(w, "%d\t%.2f\t%s\n", entry.ID, entry.Amount, entry.CreatedAt.Format(time.RFC3339))
+		}
+	})
+}
+
+func (cli *CLI) setAccountFrozen(ctx context.Context, name string, args []string, frozen bool) error {
+	flags := cli.newFlagSet("accounts " + name)
+	id := flags.Int64("id", 0, "account id")
+	if err := parse(flags, args, "id"); err != nil {
+		return err
+	}
+
+	account, err := cli.store.SetAccountFrozen(ctx, db.SetAccountFrozenParams{ID: *id, IsFrozen: frozen})
+	if err != nil {
+		return fmt.Errorf("account [%d]: %w", *id, err)
+	}
+	return cli.printAccounts([]db.Account{account})
+}
+
+func (cli *CLI) printAccounts(accounts []db.Account) error {
+	return cli.print(accounts, func(w io.Writer) {
+		writeAccounts(w, accounts)
+	})
+}
+
+func writeAccounts(w io.Writer, accounts []db.Account) {
+	fmt.Fprintln(w, "ID\tOWNER\tBALANCE\tCURRENCY\tFROZEN\tCREATED AT")
+	for _, account := range accounts {
+		fmt.Fprintf(w, "%d\t%s\t%.2f\t%s\t%t\t%s\n", account.ID, account.Owner, account.Balance,
+			account.Currency, account.IsFrozen, account.CreatedAt.Format(time.RFC3339))
+	}
+}
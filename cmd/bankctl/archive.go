@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/arpangoswami/backend-golang-dev/maintenance"
+)
+
+func (cli *CLI) archive(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: missing archive subcommand", errUsage)
+	}
+
+	subcommand, args := args[0], args[1:]
+	switch subcommand {
+	case "run":
+		return cli.runArchive(ctx, args)
+	case "restore":
+		return cli.restoreArchive(ctx, args)
+	}
+	return fmt.Errorf("%w: unknown archive subcommand %q", errUsage, subcommand)
+}
+
+// runArchive moves the entries and transfers created before a time to the archive tables
+func (cli *CLI) runArchive(ctx context.Context, args []string) error {
+	flags := cli.newFlagSet("archive run")
+	before := flags.String("before", "", "archive the rows created before this RFC 3339 time")
+	age := flags.Duration("age", 0, "archive the rows older than this, instead of -before")
+	batch := flags.Int("batch", maintenance.DefaultArchiveBatch, "rows moved per statement")
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+	if (*before == "") == (*age <= 0) {
+		return fmt.Errorf("%w: archive run needs either -before or a positive -age", errUsage)
+	}
+
+	cutoff := time.Now().Add(-*age)
+	if *before != "" {
+		t, err := parseTime("before", *before)
+		if err != nil {
+			return err
+		}
+		cutoff = t.Time
+	}
+	result, err := maintenance.ArchiveBefore(ctx, cli.store, cutoff, int32(*batch))
+	if err != nil {
+		return err
+	}
+	return cli.printArchiveResult(result)
+}
+
+// restoreArchive moves the archived entries and transfers created since a time back to the hot tables
+func (cli *CLI) restoreArchive(ctx context.Context, args []string) error {
+	flags := cli.newFlagSet("archive restore")
+	since := flags.String("since", "", "restore the rows created at or after this RFC 3339 time")
+	batch := flags.Int("batch", maintenance.DefaultArchiveBatch, "rows moved per statement")
+	if err := parse(flags, args, "since"); err != nil {
+		return err
+	}
+
+	t, err := parseTime("since", *since)
+	if err != nil {
+		return err
+	}
+	result, err := maintenance.RestoreSince(ctx, cli.store, t.Time, int32(*batch))
+	if err != nil {
+		return err
+	}
+	return cli.printArchiveResult(result)
+}
+
+func (cli *CLI) printArchiveResult(result maintenance.ArchiveResult) error {
+	return cli.print(result, func(w io.Writer) {
+		fmt.Fprintln(w, "ENTRIES\tTRANSFERS")
+		fmt.Fprintf(w, "%d\t%d\n", result.Entries, result.Transfers)
+	})
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"time"
+
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+)
+
+// audit lists audit_log rows, oldest first, optionally narrowed down to an entity and a time range
+func (cli *CLI) audit(ctx context.Context, args []string) error {
+	flags := cli.newFlagSet("audit")
+	entity := flags.String("entity", "", "only list changes of this entity type, account, transfer or entry")
+	id := flags.Int64("id", 0, "only list changes of the entity with this id, requires -entity")
+	since := flags.String("since", "", "only list changes made at or after this RFC 3339 time")
+	until := flags.String("until", "", "only list changes made before this RFC 3339 time")
+	after := flags.Int64("after", 0, "only list rows with a larger id, to page through the log")
+	limit := flags.Int("limit", 50, "maximum number of rows")
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+	if *id != 0 && *entity == "" {
+		return fmt.Errorf("%w: audit -id requires -entity", errUsage)
+	}
+
+	arg := db.ListAuditLogsParams{AfterID: *after, LimitCount: int32(*limit)}
+	if *entity != "" {
+		arg.EntityType = sql.NullString{String: *entity, Valid: true}
+	}
+	if *id != 0 {
+		arg.EntityID = sql.NullInt64{Int64: *id, Valid: true}
+	}
+	var err error
+	if arg.StartTime, err = parseTime("since", *since); err != nil {
+		return err
+	}
+	if arg.EndTime, err = parseTime("until", *until); err != nil {
+		return err
+	}
+
+	logs, err := cli.store.ListAuditLogs(ctx, arg)
+	if err != nil {
+		return err
+	}
+	return cli.print(logs, func(w io.Writer) {
+		fmt.Fprintln(w, "ID\tTIME\tACTOR\tACTION\tENTITY\tREQUEST\tBEFORE\tAFTER")
+		for _, log := range logs {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s %d\t%s\t%s\t%s\n", log.ID, log.CreatedAt.Format(time.RFC3339),
+				log.Actor, log.Action, log.EntityType, log.EntityID, log.RequestID, log.Before, log.After)
+		}
+	})
+}
+
+func parseTime(name, value string) (sql.NullTime, error) {
+	if value == "" {
+		return sql.NullTime{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return sql.NullTime{}, fmt.Errorf("%w: -%s: %v", errUsage, name, err)
+	}
+	return sql.NullTime{Time: t, Valid: true}, nil
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/arpangoswami/backend-golang-dev/database/migration"
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+)
+
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+)
+
+var errUsage = errors.New("invalid usage")
+
+// CLI runs the bankctl commands against a Store
+type CLI struct {
+	store    db.Store
+	migrator *migration.Migrator
+	out      io.Writer
+	format   string
+}
+
+// Execute runs the command named by the first argument
+func (cli *CLI) Execute(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: missing command", errUsage)
+	}
+
+	command, args := args[0], args[1:]
+	switch command {
+	case "accounts":
+		return cli.accounts(ctx, args)
+	case "transfer":
+		return cli.transfer(ctx, args)
+	case "transfers":
+		return cli.transfers(ctx, args)
+	case "reconcile":
+		return cli.reconcile(ctx, args)
+	case "audit":
+		return cli.audit(ctx, args)
+	case "import":
+		return cli.imports(ctx, args)
+	case "ledger":
+		return cli.ledger(ctx, args)
+	case "archive":
+		return cli.archive(ctx, args)
+	case "migrate":
+		return cli.migrate(ctx, args)
+	}
+	return fmt.Errorf("%w: unknown command %q", errUsage, command)
+}
+
+// newFlagSet returns the flags of a subcommand; errors are reported by Execute's caller
+func (cli *CLI) newFlagSet(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(cli.out)
+	return flags
+}
+
+// parse parses the flags of a subcommand and checks that the required ones are set
+func parse(flags *flag.FlagSet, args []string, required ...string) error {
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+	if flags.NArg() > 0 {
+		return fmt.Errorf("%w: unexpected arguments %v", errUsage, flags.Args())
+	}
+
+	set := make(map[string]bool)
+	flags.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	for _, name := range required {
+		if !set[name] {
+			return fmt.Errorf("%w: %s -%s is required", errUsage, flags.Name(), name)
+		}
+	}
+	return nil
+}
+
+// print writes v as indented JSON, or as the table written by writeTable
+func (cli *CLI) print(v any, writeTable func(w io.Writer)) error {
+	if cli.format == formatJSON {
+		encoder := json.NewEncoder(cli.out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	}
+
+	tw := tabwriter.NewWriter(cli.out, 0, 0, 2, ' ', 0)
+	writeTable(tw)
+	return tw.Flush()
+}
@@ -0,0 +1,372 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	mockdb "github.com/arpangoswami/backend-golang-dev/database/mock"
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+	"github.com/arpangoswami/backend-golang-dev/util"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func newTestCLI(t *testing.T, format string) (*CLI, *mockdb.MockStore, *bytes.Buffer) {
+	t.Helper()
+	store := mockdb.NewMockStore(gomock.NewController(t))
+	out := &bytes.Buffer{}
+	return &CLI{store: store, out: out, format: format}, store, out
+}
+
+func itoa(id int64) string {
+	return strconv.FormatInt(id, 10)
+}
+
+func randomAccount(currency string) db.Account {
+	return db.Account{
+		ID:       util.RandomInt(1, 1000),
+		Owner:    util.RandomOwner(),
+		Balance:  util.RandomMoney(1000),
+		Currency: currency,
+	}
+}
+
+func TestCLI_CreateAccount(t *testing.T) {
+	cli, store, out := newTestCLI(t, formatJSON)
+	owner := util.RandomOwner()
+	store.EXPECT().OpenAccountTransaction(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, arg db.CreateAccountParams) (db.Account, error) {
+			assert.Equal(t, owner, arg.Owner)
+			assert.Equal(t, util.USD, arg.Currency)
+			assert.Equal(t, float64(100), arg.Balance)
+			return db.Account{ID: 1, Owner: arg.Owner, Balance: arg.Balance, Currency: arg.Currency}, nil
+		})
+
+	err := cli.Execute(context.Background(), []string{"accounts", "create", "-owner", owner, "-currency", "USD", "-balance", "100"})
+	assert.NoError(t, err)
+
+	var accounts []db.Account
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &accounts))
+	assert.Len(t, accounts, 1)
+	assert.Equal(t, owner, accounts[0].Owner)
+}
+
+func TestCLI_CreateAccountInvalid(t *testing.T) {
+	cli, store, _ := newTestCLI(t, formatTable)
+	store.EXPECT().OpenAccountTransaction(gomock.Any(), gomock.Any()).Times(0)
+
+	err := cli.Execute(context.Background(), []string{"accounts", "create", "-owner", "alice"})
+	assert.ErrorIs(t, err, errUsage)
+
+	err = cli.Execute(context.Background(), []string{"accounts", "create", "-owner", "alice", "-currency", "XYZ"})
+	assert.ErrorIs(t, err, errUsage)
+}
+
+func TestCLI_ListAccounts(t *testing.T) {
+	cli, store, out := newTestCLI(t, formatTable)
+	account := randomAccount(util.EUR)
+	arg := db.ListAccountsByOwnerParams{Owner: account.Owner, Limit: 5, Offset: 0}
+	store.EXPECT().ListAccountsByOwner(gomock.Any(), gomock.Eq(arg)).Times(1).Return([]db.Account{account}, nil)
+
+	err := cli.Execute(context.Background(), []string{"accounts", "list", "-owner", account.Owner, "-limit", "5"})
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "OWNER")
+	assert.Contains(t, out.String(), account.Owner)
+}
+
+func TestCLI_ShowAccount(t *testing.T) {
+	cli, store, out := newTestCLI(t, formatJSON)
+	account := randomAccount(util.USD)
+	entries := []db.Entry{{ID: 2, AccountID: account.ID, Amount: -5}, {ID: 1, AccountID: account.ID, Amount: 10}}
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+	store.EXPECT().ListLatestEntries(gomock.Any(), gomock.Eq(db.ListLatestEntriesParams{AccountID: account.ID, Limit: 2})).
+		Times(1).Return(entries, nil)
+
+	err := cli.Execute(context.Background(), []string{"accounts", "show", "-id", itoa(account.ID), "-entries", "2"})
+	assert.NoError(t, err)
+
+	var details accountDetails
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &details))
+	assert.Equal(t, account.ID, details.Account.ID)
+	assert.Equal(t, entries, details.RecentEntries)
+}
+
+func TestCLI_FreezeAccount(t *testing.T) {
+	cli, store, out := newTestCLI(t, formatTable)
+	account := randomAccount(util.USD)
+	account.IsFrozen = true
+	store.EXPECT().SetAccountFrozen(gomock.Any(), gomock.Eq(db.SetAccountFrozenParams{ID: account.ID, IsFrozen: true})).
+		Times(1).Return(account, nil)
+
+	err := cli.Execute(context.Background(), []string{"accounts", "freeze", "-id", itoa(account.ID)})
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "true")
+
+	store.EXPECT().SetAccountFrozen(gomock.Any(), gomock.Any()).Times(1).Return(db.Account{}, sql.ErrNoRows)
+	err = cli.Execute(context.Background(), []string{"accounts", "unfreeze", "-id", "999"})
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestCLI_Transfer(t *testing.T) {
+	fromAccount := randomAccount(util.USD)
+	toAccount := randomAccount(util.USD)
+	toAccount.ID = fromAccount.ID + 1
+	otherCurrency := randomAccount(util.EUR)
+	otherCurrency.ID = fromAccount.ID + 2
+
+	testCases := []struct {
+		name       string
+		args       []string
+		buildStubs func(store *mockdb.MockStore)
+		checkErr   func(t *testing.T, err error)
+	}{
+		{
+			name: "OK",
+			args: []string{"transfer", "-from", itoa(fromAccount.ID), "-to", itoa(toAccount.ID), "-amount", "10"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				arg := db.TransferTransactionParams{FromAccountID: fromAccount.ID, ToAccountID: toAccount.ID, Amount: 10}
+				store.EXPECT().TransferTransaction(gomock.Any(), gomock.Eq(arg)).Times(1).
+					Return(db.TransferTransactionResult{Transfer: db.Transfer{ID: 1}}, nil)
+			},
+			checkErr: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "CurrencyMismatch",
+			args: []string{"transfer", "-from", itoa(fromAccount.ID), "-to", itoa(otherCurrency.ID), "-amount", "10"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(otherCurrency.ID)).Times(1).Return(otherCurrency, nil)
+				store.EXPECT().TransferTransaction(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkErr: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, "currency mismatch")
+			},
+		},
+		{
+			name: "FrozenAccount",
+			args: []string{"transfer", "-from", itoa(fromAccount.ID), "-to", itoa(toAccount.ID), "-amount", "10"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().TransferTransaction(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.TransferTransactionResult{}, db.ErrAccountFrozen)
+			},
+			checkErr: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, db.ErrAccountFrozen)
+			},
+		},
+		{
+			name: "NegativeAmount",
+			args: []string{"transfer", "-from", "1", "-to", "2", "-amount", "-10"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().TransferTransaction(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkErr: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, errUsage)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cli, store, _ := newTestCLI(t, formatTable)
+			tc.buildStubs(store)
+			tc.checkErr(t, cli.Execute(context.Background(), tc.args))
+		})
+	}
+}
+
+func TestCLI_ReverseTransfer(t *testing.T) {
+	cli, store, out := newTestCLI(t, formatTable)
+	result := db.TransferTransactionResult{
+		Transfer: db.Transfer{ID: 8, FromAccountID: 2, ToAccountID: 1, Amount: 10, ReversesTransferID: sql.NullInt64{Int64: 7, Valid: true}},
+	}
+	store.EXPECT().ReverseTransferTransaction(gomock.Any(), gomock.Eq(int64(7))).Times(1).Return(result, nil)
+
+	err := cli.Execute(context.Background(), []string{"transfers", "reverse", "-id", "7"})
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "REVERSES")
+
+	store.EXPECT().ReverseTransferTransaction(gomock.Any(), gomock.Eq(int64(7))).Times(1).
+		Return(db.TransferTransactionResult{}, db.ErrTransferReversed)
+	err = cli.Execute(context.Background(), []string{"transfers", "reverse", "-id", "7"})
+	assert.ErrorIs(t, err, db.ErrTransferReversed)
+}
+
+func TestCLI_Reconcile(t *testing.T) {
+	cli, store, out := newTestCLI(t, formatTable)
+	store.EXPECT().ListBalanceMismatches(gomock.Any(), gomock.Any()).Times(1).Return([]db.ListBalanceMismatchesRow{}, nil)
+
+	assert.NoError(t, cli.Execute(context.Background(), []string{"reconcile"}))
+	assert.Contains(t, out.String(), "all account balances match")
+
+	mismatch := db.ListBalanceMismatchesRow{ID: 1, Owner: "alice", Currency: util.USD, Balance: 100, EntriesTotal: 90}
+	store.EXPECT().ListBalanceMismatches(gomock.Any(), gomock.Eq(0.5)).Times(1).Return([]db.ListBalanceMismatchesRow{mismatch}, nil)
+
+	err := cli.Execute(context.Background(), []string{"reconcile", "-tolerance", "0.5"})
+	assert.ErrorIs(t, err, errBalanceMismatch)
+	assert.Contains(t, out.String(), "10.00")
+}
+
+func TestCLI_Audit(t *testing.T) {
+	cli, store, out := newTestCLI(t, formatTable)
+	arg := db.ListAuditLogsParams{
+		EntityType: sql.NullString{String: db.EntityAccount, Valid: true},
+		EntityID:   sql.NullInt64{Int64: 3, Valid: true},
+		StartTime:  sql.NullTime{Time: time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+		LimitCount: 50,
+	}
+	logs := []db.AuditLog{{
+		ID:         1,
+		Actor:      "bankctl:root",
+		Action:     db.ActionAccountFreeze,
+		EntityType: db.EntityAccount,
+		EntityID:   3,
+		Before:     json.RawMessage(`{"is_frozen":false}`),
+		After:      json.RawMessage(`{"is_frozen":true}`),
+	}}
+	store.EXPECT().ListAuditLogs(gomock.Any(), gomock.Eq(arg)).Times(1).Return(logs, nil)
+
+	err := cli.Execute(context.Background(), []string{"audit", "-entity", "account", "-id", "3", "-since", "2024-03-01T00:00:00Z"})
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), db.ActionAccountFreeze)
+
+	assert.ErrorIs(t, cli.Execute(context.Background(), []string{"audit", "-id", "3"}), errUsage)
+	assert.ErrorIs(t, cli.Execute(context.Background(), []string{"audit", "-since", "yesterday"}), errUsage)
+}
+
+func TestCLI_ImportAccounts(t *testing.T) {
+	cli, store, out := newTestCLI(t, formatTable)
+	file := filepath.Join(t.TempDir(), "legacy-accounts.csv")
+	input := "external_id,owner,currency,balance\nA-1,alice,USD,10\nA-2,bob,XYZ,5\n"
+	assert.NoError(t, os.WriteFile(file, []byte(input), 0o600))
+
+	imp := db.Import{ID: 1, Name: "legacy-accounts.csv", Kind: db.ImportAccounts}
+	store.EXPECT().GetOrCreateImport(gomock.Any(), gomock.Eq(db.GetOrCreateImportParams{Name: imp.Name, Kind: imp.Kind})).
+		Times(1).Return(imp, nil)
+	store.EXPECT().ImportAccountsTransaction(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, arg db.ImportAccountsParams) (db.ImportBatchResult, error) {
+			assert.Len(t, arg.Rows, 1)
+			assert.Len(t, arg.Errors, 1)
+			imp.RowsProcessed, imp.RowsImported, imp.RowsFailed = 2, 1, 1
+			return db.ImportBatchResult{Import: imp, Errors: arg.Errors}, nil
+		})
+	store.EXPECT().FinishImport(gomock.Any(), gomock.Eq(imp.ID)).Times(1).
+		DoAndReturn(func(_ context.Context, id int64) (db.Import, error) {
+			imp.FinishedAt = sql.NullTime{Time: time.Now(), Valid: true}
+			return imp, nil
+		})
+
+	err := cli.Execute(context.Background(), []string{"import", "accounts", "-file", file})
+	assert.ErrorIs(t, err, errRowsRejected)
+	assert.Contains(t, out.String(), "legacy-accounts.csv")
+	assert.Contains(t, out.String(), "PROCESSED")
+
+	assert.ErrorIs(t, cli.Execute(context.Background(), []string{"import", "accounts"}), errUsage)
+	assert.ErrorIs(t, cli.Execute(context.Background(), []string{"import", "users", "-file", file}), errUsage)
+}
+
+func TestCLI_ImportErrors(t *testing.T) {
+	cli, store, out := newTestCLI(t, formatJSON)
+	imp := db.Import{ID: 4, Name: "legacy-transfers.csv", Kind: db.ImportTransfers}
+	store.EXPECT().GetImport(gomock.Any(), gomock.Eq(imp.Name)).Times(1).Return(imp, nil)
+	errs := []db.ImportError{{ID: 9, ImportID: imp.ID, Line: 3, Message: "amount must be positive"}}
+	arg := db.ListImportErrorsParams{ImportID: imp.ID, AfterID: 8, LimitCount: 50}
+	store.EXPECT().ListImportErrors(gomock.Any(), gomock.Eq(arg)).Times(1).Return(errs, nil)
+
+	err := cli.Execute(context.Background(), []string{"import", "errors", "-name", imp.Name, "-after", "8"})
+	assert.NoError(t, err)
+
+	var got []db.ImportError
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &got))
+	assert.Equal(t, errs, got)
+}
+
+func TestCLI_Ledger(t *testing.T) {
+	cli, store, out := newTestCLI(t, formatTable)
+	file := filepath.Join(t.TempDir(), "ledger.jsonl")
+	account := randomAccount(util.USD)
+	account.Balance = 0
+	runInTransaction := func(_ context.Context, fn func(tx db.Store) error) error {
+		return fn(store)
+	}
+
+	store.EXPECT().ReadSnapshotTransaction(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(runInTransaction)
+	store.EXPECT().ExportAccounts(gomock.Any(), gomock.Any()).Times(1).Return([]db.Account{account}, nil)
+	store.EXPECT().ExportTransfers(gomock.Any(), gomock.Any()).Times(1).Return(nil, nil)
+	store.EXPECT().ExportEntries(gomock.Any(), gomock.Any()).Times(1).Return(nil, nil)
+	assert.NoError(t, cli.Execute(context.Background(), []string{"ledger", "export", "-file", file}))
+	assert.Contains(t, out.String(), "ACCOUNTS")
+
+	// an archive is never overwritten
+	assert.ErrorIs(t, cli.Execute(context.Background(), []string{"ledger", "export", "-file", file}), os.ErrExist)
+
+	store.EXPECT().ExecTransaction(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(runInTransaction)
+	store.EXPECT().HasLedger(gomock.Any()).Times(1).Return(false, nil)
+	store.EXPECT().RestoreAccounts(gomock.Any(), gomock.Eq([]db.RestoreAccountsParams{db.RestoreAccountsParams(account)})).
+		Times(1).Return(int64(1), nil)
+	store.EXPECT().ResetLedgerSequences(gomock.Any()).Times(1).Return(nil)
+	store.EXPECT().ListBalanceMismatches(gomock.Any(), gomock.Any()).Times(1).Return(nil, nil)
+	assert.NoError(t, cli.Execute(context.Background(), []string{"ledger", "import", "-file", file}))
+
+	assert.ErrorIs(t, cli.Execute(context.Background(), []string{"ledger", "import"}), errUsage)
+	assert.ErrorIs(t, cli.Execute(context.Background(), []string{"ledger", "verify"}), errUsage)
+}
+
+func TestCLI_LedgerExportFailed(t *testing.T) {
+	cli, store, _ := newTestCLI(t, formatTable)
+	file := filepath.Join(t.TempDir(), "ledger.jsonl")
+	store.EXPECT().ReadSnapshotTransaction(gomock.Any(), gomock.Any()).Times(1).Return(sql.ErrConnDone)
+
+	err := cli.Execute(context.Background(), []string{"ledger", "export", "-file", file})
+	assert.ErrorIs(t, err, sql.ErrConnDone)
+	// the partial archive is removed
+	_, err = os.Stat(file)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestCLI_Archive(t *testing.T) {
+	cli, store, out := newTestCLI(t, formatJSON)
+	before := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	store.EXPECT().ArchiveEntries(gomock.Any(), gomock.Eq(db.ArchiveEntriesParams{Before: before, LimitCount: 100})).
+		Times(1).Return(int64(7), nil)
+	store.EXPECT().ArchiveTransfers(gomock.Any(), gomock.Eq(db.ArchiveTransfersParams{Before: before, LimitCount: 100})).
+		Times(1).Return(int64(3), nil)
+
+	err := cli.Execute(context.Background(), []string{"archive", "run", "-before", "2024-01-01T00:00:00Z", "-batch", "100"})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"entries":7,"transfers":3}`, out.String())
+
+	out.Reset()
+	store.EXPECT().RestoreArchivedEntries(gomock.Any(), gomock.Any()).Times(1).Return(int64(7), nil)
+	store.EXPECT().RestoreArchivedTransfers(gomock.Any(), gomock.Any()).Times(1).Return(int64(3), nil)
+	assert.NoError(t, cli.Execute(context.Background(), []string{"archive", "restore", "-since", "2023-12-01T00:00:00Z"}))
+	assert.JSONEq(t, `{"entries":7,"transfers":3}`, out.String())
+
+	// exactly one of -before and -age
+	assert.ErrorIs(t, cli.Execute(context.Background(), []string{"archive", "run"}), errUsage)
+	assert.ErrorIs(t, cli.Execute(context.Background(), []string{"archive", "run", "-before", "2024-01-01T00:00:00Z", "-age", "24h"}), errUsage)
+	assert.ErrorIs(t, cli.Execute(context.Background(), []string{"archive", "restore"}), errUsage)
+	assert.ErrorIs(t, cli.Execute(context.Background(), []string{"archive", "purge"}), errUsage)
+}
+
+func TestCLI_UnknownCommand(t *testing.T) {
+	cli, _, _ := newTestCLI(t, formatTable)
+
+	assert.ErrorIs(t, cli.Execute(context.Background(), []string{"accounts", "delete"}), errUsage)
+	assert.ErrorIs(t, cli.Execute(context.Background(), []string{"payouts"}), errUsage)
+	assert.ErrorIs(t, cli.Execute(context.Background(), nil), errUsage)
+	assert.ErrorIs(t, cli.Execute(context.Background(), []string{"migrate"}), errUsage)
+	assert.ErrorContains(t, cli.Execute(context.Background(), []string{"migrate", "up"}), "migrations are not available")
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+	"github.com/arpangoswami/backend-golang-dev/importer"
+)
+
+// errRowsRejected makes bankctl exit with a failure when an import rejected records
+var errRowsRejected = errors.New("the import rejected records, list them with bankctl import errors")
+
+func (cli *CLI) imports(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: missing import subcommand", errUsage)
+	}
+
+	subcommand, args := args[0], args[1:]
+	switch subcommand {
+	case db.ImportAccounts, db.ImportTransfers:
+		return cli.importFile(ctx, subcommand, args)
+	case "errors":
+		return cli.importErrors(ctx, args)
+	}
+	return fmt.Errorf("%w: unknown import subcommand %q", errUsage, subcommand)
+}
+
+// importFile imports a CSV file of accounts or transfers, resuming the import of the same name
+func (cli *CLI) importFile(ctx context.Context, kind string, args []string) error {
+	flags := cli.newFlagSet("import " + kind)
+	file := flags.String("file", "", "CSV file to import")
+	name := flags.String("name", "", "name of the import, to resume it; the file name by default")
+	batchSize := flags.Int("batch", importer.DefaultBatchSize, "records imported per transaction")
+	if err := parse(flags, args, "file"); err != nil {
+		return err
+	}
+	if *batchSize <= 0 {
+		return fmt.Errorf("%w: batch must be positive", errUsage)
+	}
+	if *name == "" {
+		*name = filepath.Base(*file)
+	}
+
+	f, err := os.Open(*file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	imports := importer.New(cli.store, *batchSize)
+	var imp db.Import
+	if kind == db.ImportAccounts {
+		imp, err = imports.ImportAccounts(ctx, *name, f)
+	} else {
+		imp, err = imports.ImportTransfers(ctx, *name, f)
+	}
+	// an interrupted import is printed as well, it resumes where it stopped
+	if imp.ID != 0 {
+		if printErr := cli.printImport(imp); printErr != nil {
+			return errors.Join(err, printErr)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if imp.RowsFailed > 0 {
+		return fmt.Errorf("%w -name %s: %d record(s)", errRowsRejected, imp.Name, imp.RowsFailed)
+	}
+	return nil
+}
+
+// importErrors lists the records an import rejected, by line
+func (cli *CLI) importErrors(ctx context.Context, args []string) error {
+	flags := cli.newFlagSet("import errors")
+	name := flags.String("name", "", "name of the import")
+	after := flags.Int64("after", 0, "only list errors with a larger id, to page through them")
+	limit := flags.Int("limit", 50, "maximum number of errors")
+	if err := parse(flags, args, "name"); err != nil {
+		return err
+	}
+
+	imp, err := cli.store.GetImport(ctx, *name)
+	if err != nil {
+		return fmt.Errorf("import %q: %w", *name, err)
+	}
+	errs, err := cli.store.ListImportErrors(ctx, db.ListImportErrorsParams{
+		ImportID:   imp.ID,
+		AfterID:    *after,
+		LimitCount: int32(*limit),
+	})
+	if err != nil {
+		return err
+	}
+	return cli.print(errs, func(w io.Writer) {
+		fmt.Fprintln(w, "ID\tLINE\tERROR")
+		for _, rowErr := range errs {
+			fmt.Fprintf(w, "%d\t%d\t%s\n", rowErr.ID, rowErr.Line, rowErr.Message)
+		}
+	})
+}
+
+func (cli *CLI) printImport(imp db.Import) error {
+	return cli.print(imp, func(w io.Writer) {
+		fmt.Fprintln(w, "IMPORT\tKIND\tPROCESSED\tIMPORTED\tFAILED\tFINISHED AT")
+		finishedAt := "-"
+		if imp.FinishedAt.Valid {
+			finishedAt = imp.FinishedAt.Time.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%s\n", imp.Name, imp.Kind, imp.RowsProcessed, imp.RowsImported,
+			imp.RowsFailed, finishedAt)
+	})
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/arpangoswami/backend-golang-dev/ledger"
+)
+
+func (cli *CLI) ledger(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: missing ledger subcommand", errUsage)
+	}
+
+	subcommand, args := args[0], args[1:]
+	switch subcommand {
+	case "export":
+		return cli.exportLedger(ctx, args)
+	case "import":
+		return cli.importLedger(ctx, args)
+	}
+	return fmt.Errorf("%w: unknown ledger subcommand %q", errUsage, subcommand)
+}
+
+// exportLedger writes the accounts, transfers and entries to an archive, removed again when the export fails
+func (cli *CLI) exportLedger(ctx context.Context, args []string) error {
+	flags := cli.newFlagSet("ledger export")
+	file := flags.String("file", "", "archive to write, it must not exist")
+	if err := parse(flags, args, "file"); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(*file, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	counts, err := ledger.Export(ctx, cli.store, f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return errors.Join(err, os.Remove(*file))
+	}
+	return cli.printCounts(counts)
+}
+
+// importLedger restores an archive into an empty database
+func (cli *CLI) importLedger(ctx context.Context, args []string) error {
+	flags := cli.newFlagSet("ledger import")
+	file := flags.String("file", "", "archive written by bankctl ledger export")
+	if err := parse(flags, args, "file"); err != nil {
+		return err
+	}
+
+	f, err := os.Open(*file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	counts, err := ledger.Import(ctx, cli.store, f)
+	if err != nil {
+		return err
+	}
+	return cli.printCounts(counts)
+}
+
+func (cli *CLI) printCounts(counts ledger.Counts) error {
+	return cli.print(counts, func(w io.Writer) {
+		fmt.Fprintln(w, "ACCOUNTS\tTRANSFERS\tENTRIES")
+		fmt.Fprintf(w, "%d\t%d\t%d\n", counts.Accounts, counts.Transfers, counts.Entries)
+	})
+}
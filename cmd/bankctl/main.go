@@ -0,0 +1,134 @@
+// Command bankctl lets operators inspect and fix the bank's data without psql.
+//
+//	bankctl [-config app.env] [-o table|json] <command> [flags]
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"os/user"
+	"syscall"
+
+	"github.com/arpangoswami/backend-golang-dev/config"
+	"github.com/arpangoswami/backend-golang-dev/database/migration"
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+const usage = `usage: bankctl [-config file] [-o table|json] <command> [flags]
+
+commands:
+  accounts create    -owner NAME -currency CODE [-balance AMOUNT]
+  accounts list      [-owner NAME] [-limit N] [-offset N]
+  accounts show      -id ID [-entries N]
+  accounts freeze    -id ID
+  accounts unfreeze  -id ID
+  transfer           -from ID -to ID -amount AMOUNT
+  transfers reverse  -id ID
+  reconcile          [-tolerance AMOUNT]
+  audit              [-entity account|transfer [-id ID]] [-since TIME] [-until TIME] [-after ID] [-limit N]
+  import accounts    -file CSV [-name NAME] [-batch N]
+  import transfers   -file CSV [-name NAME] [-batch N]
+  import errors      -name NAME [-after ID] [-limit N]
+  ledger export      -file ARCHIVE
+  ledger import      -file ARCHIVE
+  archive run        -before TIME|-age DURATION [-batch N]
+  archive restore    -since TIME [-batch N]
+  migrate up
+  migrate down       [-n N]
+  migrate goto       -version V
+  migrate force      -version V
+  migrate status
+
+Run bankctl <command> -h for the flags of a command.
+`
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	os.Exit(run(ctx, os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run returns the exit code: 0 on success, 1 when the command failed or found problems, 2 on bad usage
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("bankctl", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() { fmt.Fprint(stderr, usage) }
+	configPath := flags.String("config", config.DefaultFile, "optional env file with the settings")
+	format := flags.String("o", formatTable, "output format, table or json")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *format != formatTable && *format != formatJSON {
+		fmt.Fprintf(stderr, "unknown output format %q\n", *format)
+		return 2
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "cannot load config: %v\n", err)
+		return 1
+	}
+	poolConfig, err := cfg.PoolConfig(cfg.DBSource)
+	if err != nil {
+		fmt.Fprintf(stderr, "%v\n", err)
+		return 1
+	}
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	if err != nil {
+		fmt.Fprintf(stderr, "cannot open database: %v\n", err)
+		return 1
+	}
+	defer pool.Close()
+	conn := stdlib.OpenDBFromPool(pool)
+	defer conn.Close()
+
+	migrator, err := migration.New(conn)
+	if err != nil {
+		fmt.Fprintf(stderr, "cannot load migrations: %v\n", err)
+		return 1
+	}
+
+	// changes made with bankctl are audited as the operator running it, one request ID per invocation
+	ctx = db.WithRequestID(db.WithActor(ctx, operator()), uuid.NewString())
+
+	cli := &CLI{store: db.NewStore(pool), migrator: migrator, out: stdout, format: *format}
+	return exitCode(cli.Execute(ctx, flags.Args()), stderr)
+}
+
+// operator names the person running bankctl in the audit log
+func operator() string {
+	if u, err := user.Current(); err == nil {
+		return "bankctl:" + u.Username
+	}
+	return "bankctl"
+}
+
+func exitCode(err error, stderr io.Writer) int {
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, flag.ErrHelp):
+		return 0
+	case errors.Is(err, errUsage):
+		fmt.Fprintf(stderr, "%v\n\n%s", err, usage)
+		return 2
+	case errors.Is(err, errBalanceMismatch), errors.Is(err, errRowsRejected):
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	fmt.Fprintf(stderr, "error: %v\n", err)
+	return 1
+}
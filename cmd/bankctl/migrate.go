@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+func (cli *CLI) migrate(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: missing migrate subcommand", errUsage)
+	}
+	if cli.migrator == nil {
+		return errors.New("migrations are not available")
+	}
+
+	subcommand, args := args[0], args[1:]
+	switch subcommand {
+	case "up":
+		if err := parse(cli.newFlagSet("migrate up"), args); err != nil {
+			return err
+		}
+		if err := cli.migrator.Up(ctx); err != nil {
+			return err
+		}
+	case "down":
+		flags := cli.newFlagSet("migrate down")
+		n := flags.Int("n", 1, "number of migrations to revert")
+		if err := parse(flags, args); err != nil {
+			return err
+		}
+		if err := cli.migrator.Down(ctx, *n); err != nil {
+			return err
+		}
+	case "goto":
+		flags := cli.newFlagSet("migrate goto")
+		version := flags.Uint("version", 0, "version to migrate to, 0 reverts everything")
+		if err := parse(flags, args, "version"); err != nil {
+			return err
+		}
+		if err := cli.migrator.Goto(ctx, *version); err != nil {
+			return err
+		}
+	case "force":
+		flags := cli.newFlagSet("migrate force")
+		version := flags.Uint("version", 0, "clean version to record after fixing a failed migration by hand")
+		if err := parse(flags, args, "version"); err != nil {
+			return err
+		}
+		if err := cli.migrator.Force(ctx, *version); err != nil {
+			return err
+		}
+	case "status":
+		if err := parse(cli.newFlagSet("migrate status"), args); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("%w: unknown migrate subcommand %q", errUsage, subcommand)
+	}
+	return cli.printMigrationStatus(ctx)
+}
+
+func (cli *CLI) printMigrationStatus(ctx context.Context) error {
+	status, err := cli.migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+	return cli.print(status, func(w io.Writer) {
+		fmt.Fprintf(w, "schema version %d", status.Version)
+		if status.Dirty {
+			fmt.Fprint(w, " (dirty, fix it by hand then run migrate force)")
+		}
+		fmt.Fprint(w, "\n\nVERSION\tNAME\tAPPLIED\n")
+		for _, migration := range status.Migrations {
+			fmt.Fprintf(w, "%d\t%s\t%t\n", migration.Version, migration.Name, migration.Applied)
+		}
+	})
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// errBalanceMismatch makes bankctl exit with a failure when reconciliation finds problems
+var errBalanceMismatch = errors.New("reconciliation found accounts whose balance does not match their entries")
+
+// reconcile lists the accounts whose balance differs from the sum of their entries
+func (cli *CLI) reconcile(ctx context.Context, args []string) error {
+	flags := cli.newFlagSet("reconcile")
+	tolerance := flags.Float64("tolerance", 0.000001, "largest difference ignored, to absorb float rounding")
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+
+	mismatches, err := cli.store.ListBalanceMismatches(ctx, *tolerance)
+	if err != nil {
+		return err
+	}
+
+	err = cli.print(mismatches, func(w io.Writer) {
+		if len(mismatches) == 0 {
+			fmt.Fprintln(w, "all account balances match their entries")
+			return
+		}
+		fmt.Fprintln(w, "ACCOUNT\tOWNER\tCURRENCY\tBALANCE\tENTRIES TOTAL\tDIFFERENCE")
+		for _, mismatch := range mismatches {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%.2f\t%.2f\t%.2f\n", mismatch.ID, mismatch.Owner, mismatch.Currency,
+				mismatch.Balance, mismatch.EntriesTotal, mismatch.Balance-mismatch.EntriesTotal)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if len(mismatches) > 0 {
+		return fmt.Errorf("%w: %d account(s)", errBalanceMismatch, len(mismatches))
+	}
+	return nil
+}
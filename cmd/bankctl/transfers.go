@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+)
+
+func (cli *CLI) transfer(ctx context.Context, args []string) error {
+	flags := cli.newFlagSet("transfer")
+	fromAccountID := flags.Int64("from", 0, "account the money is taken from")
+	toAccountID := flags.Int64("to", 0, "account the money is sent to")
+	amount := flags.Float64("amount", 0, "amount to transfer")
+	if err := parse(flags, args, "from", "to", "amount"); err != nil {
+		return err
+	}
+	if *amount <= 0 {
+		return fmt.Errorf("%w: amount must be positive", errUsage)
+	}
+	if *fromAccountID == *toAccountID {
+		return fmt.Errorf("%w: cannot transfer to the same account", errUsage)
+	}
+
+	// the store moves raw amounts, so check the currencies like the API does
+	fromAccount, err := cli.store.GetAccount(ctx, *fromAccountID)
+	if err != nil {
+		return fmt.Errorf("account [%d]: %w", *fromAccountID, err)
+	}
+	toAccount, err := cli.store.GetAccount(ctx, *toAccountID)
+	if err != nil {
+		return fmt.Errorf("account [%d]: %w", *toAccountID, err)
+	}
+	if fromAccount.Currency != toAccount.Currency {
+		return fmt.Errorf("currency mismatch: account [%d] holds %s, account [%d] holds %s",
+			fromAccount.ID, fromAccount.Currency, toAccount.ID, toAccount.Currency)
+	}
+
+	result, err := cli.store.TransferTransaction(ctx, db.TransferTransactionParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        *amount,
+	})
+	if err != nil {
+		return err
+	}
+	return cli.printTransferResult(result)
+}
+
+func (cli *CLI) transfers(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: missing transfers subcommand", errUsage)
+	}
+
+	subcommand, args := args[0], args[1:]
+	switch subcommand {
+	case "reverse":
+		return cli.reverseTransfer(ctx, args)
+	}
+	return fmt.Errorf("%w: unknown transfers subcommand %q", errUsage, subcommand)
+}
+
+func (cli *CLI) reverseTransfer(ctx context.Context, args []string) error {
+	flags := cli.newFlagSet("transfers reverse")
+	id := flags.Int64("id", 0, "transfer id")
+	if err := parse(flags, args, "id"); err != nil {
+		return err
+	}
+
+	result, err := cli.store.ReverseTransferTransaction(ctx, *id)
+	if err != nil {
+		return fmt.Errorf("transfer [%d]: %w", *id, err)
+	}
+	return cli.printTransferResult(result)
+}
+
+func (cli *CLI) printTransferResult(result db.TransferTransactionResult) error {
+	return cli.print(result, func(w io.Writer) {
+		transfer := result.Transfer
+		fmt.Fprintln(w, "TRANSFER\tFROM\tTO\tAMOUNT\tREVERSES\tCREATED AT")
+		reverses := "-"
+		if transfer.ReversesTransferID.Valid {
+			reverses = fmt.Sprint(transfer.ReversesTransferID.Int64)
+		}
+		fmt.Fprintf(w, "%d\t%d\t%d\t%.2f\t%s\t%s\n", transfer.ID, transfer.FromAccountID, transfer.ToAccountID,
+			transfer.Amount, reverses, transfer.CreatedAt.Format(time.RFC3339))
+		fmt.Fprintln(w)
+		writeAccounts(w, []db.Account{result.FromAccount, result.ToAccount})
+	})
+}
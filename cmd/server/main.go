@@ -0,0 +1,449 @@
+// Command server runs the HTTP and gRPC APIs of the bank
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/arpangoswami/backend-golang-dev/api"
+	"github.com/arpangoswami/backend-golang-dev/config"
+	"github.com/arpangoswami/backend-golang-dev/database/migration"
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+	"github.com/arpangoswami/backend-golang-dev/gapi"
+	"github.com/arpangoswami/backend-golang-dev/maintenance"
+	"github.com/arpangoswami/backend-golang-dev/outbox"
+	"github.com/arpangoswami/backend-golang-dev/queue"
+	"github.com/arpangoswami/backend-golang-dev/telemetry"
+	"github.com/arpangoswami/backend-golang-dev/token"
+	"github.com/arpangoswami/backend-golang-dev/webhook"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const pingTimeout = 5 * time.Second
+
+func main() {
+	configPath := flag.String("config", config.DefaultFile, "optional env file with the settings")
+	flag.Parse()
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatalf("cannot load config: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if err := run(ctx, cfg); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(ctx context.Context, cfg config.Config) error {
+	pool, err := openPool(ctx, cfg, cfg.DBSource)
+	if err != nil {
+		return err
+	}
+	// Close waits for the queries that already started, so it runs after the servers stopped
+	defer pool.Close()
+	// the migrations run on database/sql, over connections of the pool
+	conn := stdlib.OpenDBFromPool(pool)
+	defer conn.Close()
+
+	migrator, err := migration.New(conn)
+	if err != nil {
+		return fmt.Errorf("cannot load migrations: %w", err)
+	}
+	if cfg.MigrateOnStart {
+		if err := migrator.Up(ctx); err != nil {
+			return fmt.Errorf("cannot migrate database: %w", err)
+		}
+		log.Printf("database migrated to version %d", migrator.LatestVersion())
+	}
+
+	tracerProvider, err := startTracing(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	// the provider is shut down last, exporting the spans of the servers' last requests
+	defer stopTracing(tracerProvider)
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		db.NewPoolStatsCollector(pool, "primary"),
+	)
+	slowQueryLog := db.SlowQueryLog{Logger: slog.Default(), Threshold: cfg.SlowQueryThreshold}
+	if cfg.SlowQueryExplainThreshold > 0 {
+		slowQueryLog.Explain, slowQueryLog.ExplainThreshold = pool, cfg.SlowQueryExplainThreshold
+	}
+	storeOptions := []db.StoreOption{
+		db.WithTracerProvider(tracerProvider),
+		db.WithMetrics(db.NewMetrics(registry)),
+		db.WithSlowQueryLog(slowQueryLog),
+	}
+	if len(cfg.DBReplicaSources) > 0 {
+		router, closeReplicas, err := openReplicas(ctx, cfg, pool, registry)
+		if err != nil {
+			return err
+		}
+		defer closeReplicas()
+		storeOptions = append(storeOptions, db.WithReplicas(router))
+	}
+	store := db.NewStore(pool, storeOptions...)
+	stopRelay, err := startRelay(cfg, store)
+	if err != nil {
+		return err
+	}
+	// the relay stops after the servers, so it can publish the events of their last requests
+	defer stopRelay()
+	defer startMaintenance(cfg, store)()
+
+	tokenMaker, err := token.NewPasetoMaker(cfg.TokenSymmetricKey)
+	if err != nil {
+		return fmt.Errorf("cannot create token maker: %w", err)
+	}
+
+	httpAPI := api.NewServer(store, tokenMaker, cfg.AccessTokenDuration, cfg.RefreshTokenDuration)
+	httpAPI.SetReadinessCheck(readinessCheck(pool, migrator))
+	grpcAPI := gapi.NewServer(store, tokenMaker, cfg.AccessTokenDuration, cfg.RefreshTokenDuration)
+	httpServer, err := newHTTPServer(ctx, cfg.HTTPServerAddress, httpAPI, grpcAPI, tracerProvider)
+	if err != nil {
+		return err
+	}
+
+	adminServer := newAdminServer(cfg.AdminServerAddress, registry)
+
+	grpcServer := grpc.NewServer(grpc.StatsHandler(otelgrpc.NewServerHandler(otelgrpc.WithTracerProvider(tracerProvider))))
+	grpcAPI.Register(grpcServer)
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+
+	grpcListener, err := net.Listen("tcp", cfg.GRPCServerAddress)
+	if err != nil {
+		return fmt.Errorf("cannot listen on %s: %w", cfg.GRPCServerAddress, err)
+	}
+
+	errs := make(chan error, 3)
+	go func() {
+		log.Printf("HTTP server listening on %s", cfg.HTTPServerAddress)
+		if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errs <- fmt.Errorf("HTTP server: %w", err)
+		}
+	}()
+	go func() {
+		log.Printf("admin server listening on %s", cfg.AdminServerAddress)
+		if err := adminServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errs <- fmt.Errorf("admin server: %w", err)
+		}
+	}()
+	go func() {
+		log.Printf("gRPC server listening on %s", cfg.GRPCServerAddress)
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			errs <- fmt.Errorf("gRPC server: %w", err)
+		}
+	}()
+
+	var serveErr error
+	select {
+	case <-ctx.Done():
+		log.Print("shutting down")
+	case serveErr = <-errs:
+		log.Printf("shutting down: %v", serveErr)
+	}
+
+	shutdownErr := shutdown(cfg.ShutdownTimeout, httpAPI, httpServer, adminServer, healthServer, grpcServer)
+	return errors.Join(serveErr, shutdownErr)
+}
+
+// gatewayPrefix is the path under which the HTTP server serves the REST gateway of the gRPC services
+const gatewayPrefix = "/v1/"
+
+// newHTTPServer returns the server of the HTTP API, which also serves the REST gateway of the
+// gRPC services under gatewayPrefix, so that both are drained by its shutdown
+func newHTTPServer(
+	ctx context.Context,
+	address string,
+	httpAPI *api.Server,
+	grpcAPI *gapi.Server,
+	tracerProvider trace.TracerProvider,
+) (*http.Server, error) {
+	gateway, err := grpcAPI.NewGatewayHandler(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create gRPC gateway: %w", err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle(gatewayPrefix, gateway)
+	mux.Handle("/", httpAPI.Handler())
+
+	return &http.Server{
+		Addr:              address,
+		Handler:           otelhttp.NewHandler(mux, "http", otelhttp.WithTracerProvider(tracerProvider)),
+		ReadHeaderTimeout: 10 * time.Second,
+	}, nil
+}
+
+// newAdminServer returns the server of /metrics, which listens apart from the public APIs so
+// that only the operators' network reaches it
+func newAdminServer(address string, registry *prometheus.Registry) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	return &http.Server{
+		Addr:              address,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+}
+
+func openPool(ctx context.Context, cfg config.Config, source string) (*pgxpool.Pool, error) {
+	poolConfig, err := cfg.PoolConfig(source)
+	if err != nil {
+		return nil, err
+	}
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open database: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("cannot connect to database: %w", err)
+	}
+	return pool, nil
+}
+
+// openReplicas connects to the replicas of DB_REPLICA_SOURCES and checks their health in the
+// background. The returned func stops the checks and closes the replicas.
+func openReplicas(ctx context.Context, cfg config.Config, primary *pgxpool.Pool, registry prometheus.Registerer) (*db.ReplicaRouter, func(), error) {
+	var replicas []*pgxpool.Pool
+	closeReplicas := func() {
+		for _, replica := range replicas {
+			replica.Close()
+		}
+	}
+	for i, source := range cfg.DBReplicaSources {
+		replica, err := openPool(ctx, cfg, source)
+		if err != nil {
+			closeReplicas()
+			return nil, nil, fmt.Errorf("replica %d: %w", i+1, err)
+		}
+		replicas = append(replicas, replica)
+		registry.MustRegister(db.NewPoolStatsCollector(replica, fmt.Sprintf("replica-%d", i+1)))
+	}
+
+	router := db.NewReplicaRouter(primary, replicas, cfg.DBReplicaMaxLag, cfg.DBReplicaCheckInterval)
+	// the replicas serve reads only once they were found in sync
+	healthy, err := router.CheckOnce(ctx)
+	if err != nil {
+		log.Printf("replica router: %v", err)
+	}
+	log.Printf("%d of %d replicas healthy", healthy, len(replicas))
+	stopChecks := runInBackground("replica router", router.Run)
+	return router, func() {
+		stopChecks()
+		closeReplicas()
+	}, nil
+}
+
+// startTracing returns the provider exporting spans with TRACING_EXPORTER. The provider of
+// none records nothing, so that the instrumentation costs next to nothing.
+func startTracing(ctx context.Context, cfg config.Config) (trace.TracerProvider, error) {
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch cfg.TracingExporter {
+	case config.TracingExporterNone:
+		return noop.NewTracerProvider(), nil
+	case config.TracingExporterStdout:
+		exporter, err = stdouttrace.New()
+	case config.TracingExporterOTLP:
+		exporter, err = otlptracegrpc.New(ctx)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot create %s trace exporter: %w", cfg.TracingExporter, err)
+	}
+
+	provider, err := telemetry.NewTracerProvider(ctx, exporter)
+	if err != nil {
+		return nil, err
+	}
+	telemetry.SetGlobal(provider)
+	log.Printf("exporting traces to %s", cfg.TracingExporter)
+	return provider, nil
+}
+
+// stopTracing exports the buffered spans of an SDK provider
+func stopTracing(provider trace.TracerProvider) {
+	sdkProvider, ok := provider.(*sdktrace.TracerProvider)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
+	defer cancel()
+	if err := sdkProvider.Shutdown(ctx); err != nil {
+		log.Printf("cannot export the last spans: %v", err)
+	}
+}
+
+// startRelay runs the outbox relay in the background unless OUTBOX_PUBLISHER is none, along with
+// the webhook worker when the events go to the webhooks. The returned func stops them, waits
+// for their current batch and closes the publisher.
+func startRelay(cfg config.Config, store db.Store) (func(), error) {
+	var publisher outbox.Publisher
+	closePublisher := func() error { return nil }
+	var stopWorker func()
+	switch cfg.OutboxPublisher {
+	case config.OutboxPublisherNone:
+		return func() {}, nil
+	case config.OutboxPublisherStdout:
+		publisher = outbox.NewWriterPublisher(os.Stdout)
+	case config.OutboxPublisherFile:
+		filePublisher, err := outbox.OpenFilePublisher(cfg.OutboxFile)
+		if err != nil {
+			return nil, err
+		}
+		publisher, closePublisher = filePublisher, filePublisher.Close
+	case config.OutboxPublisherWebhook:
+		publisher = webhook.NewFanout(store)
+		worker := webhook.NewWorker(store, webhook.NewClient(cfg.WebhookTimeout), webhook.RetryPolicy{
+			MaxAttempts: int32(cfg.WebhookMaxAttempts),
+			MinBackoff:  cfg.WebhookMinBackoff,
+			MaxBackoff:  cfg.WebhookMaxBackoff,
+		}, cfg.OutboxPollInterval)
+		stopWorker = runInBackground("webhook worker", worker.Run)
+	}
+
+	stopRelay := runInBackground("outbox relay publishing to "+cfg.OutboxPublisher,
+		outbox.NewRelay(store, publisher, cfg.OutboxPollInterval).Run)
+	return func() {
+		stopRelay()
+		if stopWorker != nil {
+			stopWorker()
+		}
+		if err := closePublisher(); err != nil {
+			log.Printf("cannot close outbox publisher: %v", err)
+		}
+	}, nil
+}
+
+// startMaintenance runs the job worker in the background, along with the scheduler enqueueing
+// the maintenance jobs. The returned func stops them and waits for the jobs being run.
+func startMaintenance(cfg config.Config, store db.Store) func() {
+	registry := queue.NewRegistry()
+	maintenance.Register(registry, store)
+	stopWorker := runInBackground("job worker", queue.NewWorker(store, registry, cfg.JobTimeout, cfg.JobPollInterval).Run)
+
+	jobs := []queue.Args{maintenance.CreatePartitions{MonthsAhead: int32(cfg.PartitionMonthsAhead)}}
+	if cfg.ArchiveAfter > 0 {
+		jobs = append(jobs, maintenance.ArchiveLedger{Age: cfg.ArchiveAfter})
+	}
+	scheduler := maintenance.NewScheduler(store, maintenance.DefaultInterval, jobs...)
+	stopScheduler := runInBackground("maintenance scheduler", scheduler.Run)
+	return func() {
+		stopScheduler()
+		stopWorker()
+	}
+}
+
+// runInBackground starts run in a goroutine, the returned func cancels its context and waits for it to return
+func runInBackground(name string, run func(ctx context.Context)) func() {
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		log.Printf("%s started", name)
+		run(ctx)
+		close(stopped)
+	}()
+
+	return func() {
+		cancel()
+		<-stopped
+	}
+}
+
+// readinessCheck fails while the database is unreachable or its schema is not the one this binary expects
+func readinessCheck(pool *pgxpool.Pool, migrator *migration.Migrator) api.ReadinessCheck {
+	return func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, pingTimeout)
+		defer cancel()
+
+		if err := pool.Ping(ctx); err != nil {
+			return fmt.Errorf("database is unreachable: %w", err)
+		}
+		version, dirty, err := migrator.Version(ctx)
+		if err != nil {
+			return fmt.Errorf("cannot read schema version: %w", err)
+		}
+		if dirty {
+			return fmt.Errorf("schema version %d is dirty", version)
+		}
+		if version != migrator.LatestVersion() {
+			return fmt.Errorf("schema version is %d, want %d", version, migrator.LatestVersion())
+		}
+		return nil
+	}
+}
+
+// shutdown stops accepting new requests and waits for the in-flight ones, and the
+// transactions they run, to finish before the timeout
+func shutdown(
+	timeout time.Duration,
+	httpAPI *api.Server,
+	httpServer *http.Server,
+	adminServer *http.Server,
+	healthServer *health.Server,
+	grpcServer *grpc.Server,
+) error {
+	httpAPI.Drain()
+	healthServer.Shutdown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	var err error
+	if shutdownErr := httpServer.Shutdown(ctx); shutdownErr != nil {
+		err = fmt.Errorf("HTTP server shutdown: %w", shutdownErr)
+	}
+	if shutdownErr := adminServer.Shutdown(ctx); shutdownErr != nil {
+		err = errors.Join(err, fmt.Errorf("admin server shutdown: %w", shutdownErr))
+	}
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		grpcServer.Stop()
+		err = errors.Join(err, errors.New("gRPC server did not drain before the shutdown timeout"))
+	}
+	return err
+}
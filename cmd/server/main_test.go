@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/arpangoswami/backend-golang-dev/api"
+	mockdb "github.com/arpangoswami/backend-golang-dev/database/mock"
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+	"github.com/arpangoswami/backend-golang-dev/gapi"
+	"github.com/arpangoswami/backend-golang-dev/token"
+	"github.com/arpangoswami/backend-golang-dev/util"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace/noop"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+)
+
+func TestHTTPServer_Gateway(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	account := db.Account{ID: util.RandomInt(1, 1000), Owner: util.RandomOwner(), Currency: "USD"}
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(2).Return(account, nil)
+
+	tokenMaker, err := token.NewPasetoMaker(util.RandomString(32))
+	require.NoError(t, err)
+	httpAPI := api.NewServer(store, tokenMaker, time.Minute, time.Hour)
+	grpcAPI := gapi.NewServer(store, tokenMaker, time.Minute, time.Hour)
+	httpServer, err := newHTTPServer(context.Background(), "", httpAPI, grpcAPI, noop.NewTracerProvider())
+	require.NoError(t, err)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	served := make(chan error, 1)
+	go func() {
+		served <- httpServer.Serve(listener)
+	}()
+
+	accessToken, _, err := tokenMaker.CreateToken(account.Owner, token.TokenTypeAccess, time.Minute)
+	require.NoError(t, err)
+	get := func(path string) (*http.Response, map[string]any) {
+		request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("http://%s%s", listener.Addr(), path), nil)
+		require.NoError(t, err)
+		request.Header.Set("Authorization", "Bearer "+accessToken)
+		response, err := http.DefaultClient.Do(request)
+		require.NoError(t, err)
+		defer response.Body.Close()
+		var body map[string]any
+		assert.NoError(t, json.NewDecoder(response.Body).Decode(&body))
+		return response, body
+	}
+
+	// protojson encodes int64 as strings, gin as numbers
+	response, body := get(fmt.Sprintf("/v1/accounts/%d", account.ID))
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, account.Owner, body["owner"])
+	assert.Equal(t, fmt.Sprint(account.ID), body["id"])
+
+	response, body = get(fmt.Sprintf("/accounts/%d", account.ID))
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, account.Owner, body["owner"])
+	assert.Equal(t, float64(account.ID), body["id"])
+
+	// the metrics are only served by the admin server
+	metricsResponse, err := http.Get(fmt.Sprintf("http://%s/metrics", listener.Addr()))
+	require.NoError(t, err)
+	metricsResponse.Body.Close()
+	assert.Equal(t, http.StatusNotFound, metricsResponse.StatusCode)
+
+	grpcServer := grpc.NewServer()
+	grpcAPI.Register(grpcServer)
+	adminServer := newAdminServer("", prometheus.NewRegistry())
+	require.NoError(t, shutdown(time.Second, httpAPI, httpServer, adminServer, health.NewServer(), grpcServer))
+	assert.True(t, errors.Is(<-served, http.ErrServerClosed))
+
+	_, err = http.Get(fmt.Sprintf("http://%s/v1/accounts/%d", listener.Addr(), account.ID))
+	assert.Error(t, err)
+}
+
+func TestHTTPServer_ErrorStatusParity(t *testing.T) {
+	from := db.Account{ID: 1, Owner: util.RandomOwner(), Currency: util.USD}
+	to := db.Account{ID: 2, Owner: util.RandomOwner(), Currency: util.USD}
+	transferBody := fmt.Sprintf(`{"from_account_id":%d,"to_account_id":%d,"amount":10,"currency":"USD"}`, from.ID, to.ID)
+
+	testCases := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		buildStubs func(store *mockdb.MockStore)
+		status     int
+	}{
+		{
+			name:   "NotFound",
+			method: http.MethodGet,
+			path:   "/accounts/3",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(int64(3))).Return(db.Account{}, sql.ErrNoRows)
+			},
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "AccountFrozen",
+			method: http.MethodPost,
+			path:   "/transfers",
+			body:   transferBody,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(from.ID)).Return(from, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(to.ID)).Return(to, nil)
+				store.EXPECT().TransferTransaction(gomock.Any(), gomock.Any()).
+					Return(db.TransferTransactionResult{}, db.ErrAccountFrozen)
+			},
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "TransferArchived",
+			method: http.MethodGet,
+			path:   "/transfers/7",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(int64(7))).Return(db.Transfer{}, sql.ErrNoRows)
+				store.EXPECT().GetArchivedTransfer(gomock.Any(), gomock.Eq(int64(7))).
+					Return(db.TransfersArchive{ID: 7, FromAccountID: from.ID, ToAccountID: to.ID, Amount: 10}, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(from.ID)).Return(from, nil)
+			},
+			status: http.StatusGone,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+
+			tokenMaker, err := token.NewPasetoMaker(util.RandomString(32))
+			require.NoError(t, err)
+			httpAPI := api.NewServer(store, tokenMaker, time.Minute, time.Hour)
+			grpcAPI := gapi.NewServer(store, tokenMaker, time.Minute, time.Hour)
+			httpServer, err := newHTTPServer(context.Background(), "", httpAPI, grpcAPI, noop.NewTracerProvider())
+			require.NoError(t, err)
+			accessToken, _, err := tokenMaker.CreateToken(from.Owner, token.TokenTypeAccess, time.Minute)
+			require.NoError(t, err)
+
+			// the same request answered by gin and by the gateway
+			for _, prefix := range []string{"", "/v1"} {
+				tc.buildStubs(store)
+				request := httptest.NewRequest(tc.method, prefix+tc.path, bytes.NewBufferString(tc.body))
+				request.Header.Set("Authorization", "Bearer "+accessToken)
+				recorder := httptest.NewRecorder()
+				httpServer.Handler.ServeHTTP(recorder, request)
+				assert.Equal(t, tc.status, recorder.Code, "%s%s: %s", prefix, tc.path, recorder.Body)
+			}
+		})
+	}
+}
+
+func TestAdminServer_Metrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	counter := prometheus.NewCounter(prometheus.CounterOpts{Name: "test_total", Help: "Test counter."})
+	registry.MustRegister(counter)
+	counter.Inc()
+
+	adminServer := newAdminServer("", registry)
+	recorder := httptest.NewRecorder()
+	adminServer.Handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "test_total 1")
+}
@@ -0,0 +1,370 @@
+package config
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Config holds every setting of the application. Values are read from an
+// optional env file and can be overridden by environment variables of the same name.
+type Config struct {
+	Environment string
+
+	DBSource            string
+	DBMaxConns          int
+	DBMinConns          int
+	DBMaxConnLifetime   time.Duration
+	DBMaxConnIdleTime   time.Duration
+	DBHealthCheckPeriod time.Duration
+	MigrateOnStart      bool
+
+	DBReplicaSources       []string
+	DBReplicaMaxLag        time.Duration
+	DBReplicaCheckInterval time.Duration
+
+	HTTPServerAddress  string
+	GRPCServerAddress  string
+	AdminServerAddress string
+	ShutdownTimeout    time.Duration
+
+	TokenSymmetricKey    string
+	AccessTokenDuration  time.Duration
+	RefreshTokenDuration time.Duration
+
+	OutboxPublisher    string
+	OutboxFile         string
+	OutboxPollInterval time.Duration
+
+	WebhookMaxAttempts int
+	WebhookMinBackoff  time.Duration
+	WebhookMaxBackoff  time.Duration
+	WebhookTimeout     time.Duration
+
+	JobPollInterval      time.Duration
+	JobTimeout           time.Duration
+	PartitionMonthsAhead int
+	ArchiveAfter         time.Duration
+
+	TracingExporter string
+
+	SlowQueryThreshold        time.Duration
+	SlowQueryExplainThreshold time.Duration
+}
+
+const (
+	// DefaultFile is the env file looked up next to the binary or the repository root
+	DefaultFile = "app.env"
+
+	tokenSymmetricKeySize = 32
+
+	// Environment values, production turns off the debugging aids that cost extra queries
+	EnvironmentDevelopment = "development"
+	EnvironmentProduction  = "production"
+
+	// OutboxPublisher values: the relay is off, prints events, appends them to OutboxFile,
+	// or queues them for the webhook subscriptions, which are then delivered by the server
+	OutboxPublisherNone    = "none"
+	OutboxPublisherStdout  = "stdout"
+	OutboxPublisherFile    = "file"
+	OutboxPublisherWebhook = "webhook"
+
+	// TracingExporter values: spans are dropped, printed, or sent to the collector
+	// configured by the standard OTEL_EXPORTER_OTLP_* environment variables
+	TracingExporterNone   = "none"
+	TracingExporterStdout = "stdout"
+	TracingExporterOTLP   = "otlp"
+)
+
+// defaults are applied to every setting missing from both the file and the environment
+var defaults = map[string]string{
+	"ENVIRONMENT":               EnvironmentDevelopment,
+	"DB_MAX_CONNS":              "25",
+	"DB_MIN_CONNS":              "0",
+	"DB_MAX_CONN_LIFETIME":      "1h",
+	"DB_MAX_CONN_IDLE_TIME":     "30m",
+	"DB_HEALTH_CHECK_PERIOD":    "1m",
+	"MIGRATE_ON_START":          "false",
+	"DB_REPLICA_SOURCES":        "",
+	"DB_REPLICA_MAX_LAG":        "5s",
+	"DB_REPLICA_CHECK_INTERVAL": "5s",
+	"HTTP_SERVER_ADDRESS":       "0.0.0.0:8080",
+	"GRPC_SERVER_ADDRESS":       "0.0.0.0:9090",
+	"ADMIN_SERVER_ADDRESS":      "127.0.0.1:8081",
+	"SHUTDOWN_TIMEOUT":          "30s",
+	"ACCESS_TOKEN_DURATION":     "15m",
+	"REFRESH_TOKEN_DURATION":    "24h",
+	"OUTBOX_PUBLISHER":          OutboxPublisherNone,
+	"OUTBOX_FILE":               "outbox.jsonl",
+	"OUTBOX_POLL_INTERVAL":      "1s",
+	"WEBHOOK_MAX_ATTEMPTS":      "8",
+	"WEBHOOK_MIN_BACKOFF":       "30s",
+	"WEBHOOK_MAX_BACKOFF":       "1h",
+	"WEBHOOK_TIMEOUT":           "10s",
+	"JOB_POLL_INTERVAL":         "1s",
+	"JOB_TIMEOUT":               "5m",
+	"PARTITION_MONTHS_AHEAD":    "3",
+	"ARCHIVE_AFTER":             "0",
+	"TRACING_EXPORTER":          TracingExporterNone,
+	// a SLOW_QUERY_THRESHOLD of 0 logs every query, a SLOW_QUERY_EXPLAIN_THRESHOLD of 0 never explains
+	"SLOW_QUERY_THRESHOLD":         "200ms",
+	"SLOW_QUERY_EXPLAIN_THRESHOLD": "0",
+}
+
+// Load reads the configuration from the env file at path, if it exists, and
+// from the environment. An empty path only reads the environment.
+func Load(path string) (Config, error) {
+	values := make(map[string]string, len(defaults))
+	for key, value := range defaults {
+		values[key] = value
+	}
+
+	if path != "" {
+		fileValues, err := readFile(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return Config{}, err
+		}
+		for key, value := range fileValues {
+			values[key] = value
+		}
+	}
+
+	return parse(func(key string) string {
+		if value, ok := os.LookupEnv(key); ok {
+			return value
+		}
+		return values[key]
+	})
+}
+
+// readFile parses KEY=VALUE lines, ignoring blank lines and # comments
+func readFile(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: expected KEY=VALUE", path, lineNumber)
+		}
+		values[strings.TrimSpace(key)] = strings.Trim(strings.TrimSpace(value), `"'`)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return values, nil
+}
+
+func parse(lookup func(key string) string) (Config, error) {
+	p := parser{lookup: lookup}
+	config := Config{
+		Environment:               p.string("ENVIRONMENT"),
+		DBSource:                  p.string("DB_SOURCE"),
+		DBMaxConns:                p.int("DB_MAX_CONNS"),
+		DBMinConns:                p.int("DB_MIN_CONNS"),
+		DBMaxConnLifetime:         p.duration("DB_MAX_CONN_LIFETIME"),
+		DBMaxConnIdleTime:         p.duration("DB_MAX_CONN_IDLE_TIME"),
+		DBHealthCheckPeriod:       p.duration("DB_HEALTH_CHECK_PERIOD"),
+		MigrateOnStart:            p.bool("MIGRATE_ON_START"),
+		DBReplicaSources:          p.list("DB_REPLICA_SOURCES"),
+		DBReplicaMaxLag:           p.duration("DB_REPLICA_MAX_LAG"),
+		DBReplicaCheckInterval:    p.duration("DB_REPLICA_CHECK_INTERVAL"),
+		HTTPServerAddress:         p.string("HTTP_SERVER_ADDRESS"),
+		GRPCServerAddress:         p.string("GRPC_SERVER_ADDRESS"),
+		AdminServerAddress:        p.string("ADMIN_SERVER_ADDRESS"),
+		ShutdownTimeout:           p.duration("SHUTDOWN_TIMEOUT"),
+		TokenSymmetricKey:         p.string("TOKEN_SYMMETRIC_KEY"),
+		AccessTokenDuration:       p.duration("ACCESS_TOKEN_DURATION"),
+		RefreshTokenDuration:      p.duration("REFRESH_TOKEN_DURATION"),
+		OutboxPublisher:           p.string("OUTBOX_PUBLISHER"),
+		OutboxFile:                p.string("OUTBOX_FILE"),
+		OutboxPollInterval:        p.duration("OUTBOX_POLL_INTERVAL"),
+		WebhookMaxAttempts:        p.int("WEBHOOK_MAX_ATTEMPTS"),
+		WebhookMinBackoff:         p.duration("WEBHOOK_MIN_BACKOFF"),
+		WebhookMaxBackoff:         p.duration("WEBHOOK_MAX_BACKOFF"),
+		WebhookTimeout:            p.duration("WEBHOOK_TIMEOUT"),
+		JobPollInterval:           p.duration("JOB_POLL_INTERVAL"),
+		JobTimeout:                p.duration("JOB_TIMEOUT"),
+		PartitionMonthsAhead:      p.int("PARTITION_MONTHS_AHEAD"),
+		ArchiveAfter:              p.duration("ARCHIVE_AFTER"),
+		TracingExporter:           p.string("TRACING_EXPORTER"),
+		SlowQueryThreshold:        p.duration("SLOW_QUERY_THRESHOLD"),
+		SlowQueryExplainThreshold: p.duration("SLOW_QUERY_EXPLAIN_THRESHOLD"),
+	}
+	if len(p.errs) > 0 {
+		return Config{}, errors.Join(p.errs...)
+	}
+	if err := config.Validate(); err != nil {
+		return Config{}, err
+	}
+	return config, nil
+}
+
+// Validate reports every setting that is missing or out of range
+func (config Config) Validate() error {
+	var errs []error
+	if config.Environment != EnvironmentDevelopment && config.Environment != EnvironmentProduction {
+		errs = append(errs, fmt.Errorf("ENVIRONMENT must be %s or %s", EnvironmentDevelopment, EnvironmentProduction))
+	}
+	if config.DBSource == "" {
+		errs = append(errs, errors.New("DB_SOURCE must be set"))
+	}
+	if config.DBMaxConns < 1 {
+		errs = append(errs, errors.New("DB_MAX_CONNS must be at least 1"))
+	}
+	if config.DBMinConns < 0 || config.DBMinConns > config.DBMaxConns {
+		errs = append(errs, errors.New("DB_MIN_CONNS must be between 0 and DB_MAX_CONNS"))
+	}
+	if config.DBMaxConnLifetime <= 0 || config.DBMaxConnIdleTime <= 0 || config.DBHealthCheckPeriod <= 0 {
+		errs = append(errs, errors.New("DB_MAX_CONN_LIFETIME, DB_MAX_CONN_IDLE_TIME and DB_HEALTH_CHECK_PERIOD must be positive"))
+	}
+	if config.DBReplicaMaxLag < 0 {
+		errs = append(errs, errors.New("DB_REPLICA_MAX_LAG must not be negative"))
+	}
+	if config.DBReplicaCheckInterval <= 0 {
+		errs = append(errs, errors.New("DB_REPLICA_CHECK_INTERVAL must be positive"))
+	}
+	if config.HTTPServerAddress == "" {
+		errs = append(errs, errors.New("HTTP_SERVER_ADDRESS must be set"))
+	}
+	if config.GRPCServerAddress == "" {
+		errs = append(errs, errors.New("GRPC_SERVER_ADDRESS must be set"))
+	}
+	if config.AdminServerAddress == "" {
+		errs = append(errs, errors.New("ADMIN_SERVER_ADDRESS must be set"))
+	}
+	if config.ShutdownTimeout <= 0 {
+		errs = append(errs, errors.New("SHUTDOWN_TIMEOUT must be positive"))
+	}
+	if len(config.TokenSymmetricKey) != tokenSymmetricKeySize {
+		errs = append(errs, fmt.Errorf("TOKEN_SYMMETRIC_KEY must be exactly %d characters", tokenSymmetricKeySize))
+	}
+	if config.AccessTokenDuration <= 0 {
+		errs = append(errs, errors.New("ACCESS_TOKEN_DURATION must be positive"))
+	}
+	if config.RefreshTokenDuration < config.AccessTokenDuration {
+		errs = append(errs, errors.New("REFRESH_TOKEN_DURATION must not be shorter than ACCESS_TOKEN_DURATION"))
+	}
+	switch config.OutboxPublisher {
+	case OutboxPublisherNone, OutboxPublisherStdout, OutboxPublisherWebhook:
+	case OutboxPublisherFile:
+		if config.OutboxFile == "" {
+			errs = append(errs, errors.New("OUTBOX_FILE must be set when OUTBOX_PUBLISHER is file"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("OUTBOX_PUBLISHER must be one of %s, %s, %s or %s",
+			OutboxPublisherNone, OutboxPublisherStdout, OutboxPublisherFile, OutboxPublisherWebhook))
+	}
+	if config.OutboxPollInterval <= 0 {
+		errs = append(errs, errors.New("OUTBOX_POLL_INTERVAL must be positive"))
+	}
+	if config.WebhookMaxAttempts < 1 {
+		errs = append(errs, errors.New("WEBHOOK_MAX_ATTEMPTS must be at least 1"))
+	}
+	if config.WebhookMinBackoff <= 0 {
+		errs = append(errs, errors.New("WEBHOOK_MIN_BACKOFF must be positive"))
+	}
+	if config.WebhookMaxBackoff < config.WebhookMinBackoff {
+		errs = append(errs, errors.New("WEBHOOK_MAX_BACKOFF must not be shorter than WEBHOOK_MIN_BACKOFF"))
+	}
+	if config.WebhookTimeout <= 0 {
+		errs = append(errs, errors.New("WEBHOOK_TIMEOUT must be positive"))
+	}
+	if config.JobPollInterval <= 0 || config.JobTimeout <= 0 {
+		errs = append(errs, errors.New("JOB_POLL_INTERVAL and JOB_TIMEOUT must be positive"))
+	}
+	if config.PartitionMonthsAhead < 1 {
+		errs = append(errs, errors.New("PARTITION_MONTHS_AHEAD must be at least 1"))
+	}
+	if config.ArchiveAfter < 0 {
+		errs = append(errs, errors.New("ARCHIVE_AFTER must not be negative"))
+	}
+	switch config.TracingExporter {
+	case TracingExporterNone, TracingExporterStdout, TracingExporterOTLP:
+	default:
+		errs = append(errs, fmt.Errorf("TRACING_EXPORTER must be one of %s, %s or %s",
+			TracingExporterNone, TracingExporterStdout, TracingExporterOTLP))
+	}
+	if config.SlowQueryThreshold < 0 || config.SlowQueryExplainThreshold < 0 {
+		errs = append(errs, errors.New("SLOW_QUERY_THRESHOLD and SLOW_QUERY_EXPLAIN_THRESHOLD must not be negative"))
+	}
+	if config.SlowQueryExplainThreshold > 0 && config.Environment == EnvironmentProduction {
+		errs = append(errs, errors.New("SLOW_QUERY_EXPLAIN_THRESHOLD must be 0 in production"))
+	}
+	return errors.Join(errs...)
+}
+
+// PoolConfig returns the settings of a connection pool to the database at source, which
+// is DBSource or one of DBReplicaSources
+func (config Config) PoolConfig(source string) (*pgxpool.Config, error) {
+	poolConfig, err := pgxpool.ParseConfig(source)
+	if err != nil {
+		return nil, fmt.Errorf("invalid database source: %w", err)
+	}
+	poolConfig.MaxConns = int32(config.DBMaxConns)
+	poolConfig.MinConns = int32(config.DBMinConns)
+	poolConfig.MaxConnLifetime = config.DBMaxConnLifetime
+	poolConfig.MaxConnIdleTime = config.DBMaxConnIdleTime
+	poolConfig.HealthCheckPeriod = config.DBHealthCheckPeriod
+	return poolConfig, nil
+}
+
+// parser collects conversion errors so that all bad settings are reported at once
+type parser struct {
+	lookup func(key string) string
+	errs   []error
+}
+
+func (p *parser) string(key string) string {
+	return p.lookup(key)
+}
+
+// list splits a comma separated value, an empty value is an empty list
+func (p *parser) list(key string) []string {
+	var values []string
+	for _, value := range strings.Split(p.lookup(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func (p *parser) int(key string) int {
+	value, err := strconv.Atoi(p.lookup(key))
+	if err != nil {
+		p.errs = append(p.errs, fmt.Errorf("%s must be an integer: %w", key, err))
+	}
+	return value
+}
+
+func (p *parser) bool(key string) bool {
+	value, err := strconv.ParseBool(p.lookup(key))
+	if err != nil {
+		p.errs = append(p.errs, fmt.Errorf("%s must be a boolean: %w", key, err))
+	}
+	return value
+}
+
+func (p *parser) duration(key string) time.Duration {
+	value, err := time.ParseDuration(p.lookup(key))
+	if err != nil {
+		p.errs = append(p.errs, fmt.Errorf("%s must be a duration: %w", key, err))
+	}
+	return value
+}
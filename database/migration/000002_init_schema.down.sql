@@ -0,0 +1,3 @@
+ALTER TABLE entries ALTER COLUMN amount TYPE bigint USING round(amount)::bigint;
+
+ALTER TABLE transfers ALTER COLUMN amount TYPE bigint USING round(amount)::bigint;
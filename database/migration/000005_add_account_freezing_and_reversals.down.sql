@@ -0,0 +1,3 @@
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "reverses_transfer_id";
+
+ALTER TABLE "accounts" DROP COLUMN IF EXISTS "is_frozen";
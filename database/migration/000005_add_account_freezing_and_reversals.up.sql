@@ -0,0 +1,7 @@
+ALTER TABLE "accounts" ADD COLUMN "is_frozen" boolean NOT NULL DEFAULT false;
+
+ALTER TABLE "transfers" ADD COLUMN "reverses_transfer_id" bigint UNIQUE;
+
+ALTER TABLE "transfers" ADD FOREIGN KEY ("reverses_transfer_id") REFERENCES "transfers" ("id");
+
+COMMENT ON COLUMN "transfers"."reverses_transfer_id" IS 'Set on the transfer moving the money of a reversed transfer back';
@@ -0,0 +1,27 @@
+CREATE TABLE "jobs" (
+  "id" bigserial PRIMARY KEY,
+  "kind" varchar NOT NULL,
+  "payload" jsonb NOT NULL,
+  "priority" int NOT NULL DEFAULT 0,
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "unique_key" varchar,
+  "attempts" int NOT NULL DEFAULT 0,
+  "max_attempts" int NOT NULL,
+  "run_at" timestamptz NOT NULL DEFAULT (now()),
+  "locked_until" timestamptz,
+  "last_error" varchar NOT NULL DEFAULT '',
+  "finished_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "jobs" ("priority" DESC, "run_at", "id") WHERE "status" = 'pending';
+
+CREATE INDEX ON "jobs" ("locked_until") WHERE "status" = 'running';
+
+CREATE UNIQUE INDEX ON "jobs" ("kind", "unique_key") WHERE "status" IN ('pending', 'running');
+
+COMMENT ON COLUMN "jobs"."status" IS 'pending, running, succeeded or failed once the attempts are exhausted';
+
+COMMENT ON COLUMN "jobs"."unique_key" IS 'At most one pending or running job of a kind has the same key';
+
+COMMENT ON COLUMN "jobs"."locked_until" IS 'A running job is claimed again once its worker missed this deadline';
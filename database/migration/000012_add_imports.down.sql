@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS "imported_accounts";
+
+DROP TABLE IF EXISTS "import_errors";
+
+DROP TABLE IF EXISTS "imports";
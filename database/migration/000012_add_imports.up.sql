@@ -0,0 +1,39 @@
+CREATE TABLE "imports" (
+  "id" bigserial PRIMARY KEY,
+  "name" varchar UNIQUE NOT NULL,
+  "kind" varchar NOT NULL,
+  "rows_processed" bigint NOT NULL DEFAULT 0,
+  "rows_imported" bigint NOT NULL DEFAULT 0,
+  "rows_failed" bigint NOT NULL DEFAULT 0,
+  "finished_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "import_errors" (
+  "id" bigserial PRIMARY KEY,
+  "import_id" bigint NOT NULL,
+  "line" bigint NOT NULL,
+  "message" varchar NOT NULL
+);
+
+CREATE TABLE "imported_accounts" (
+  "external_id" varchar PRIMARY KEY,
+  "account_id" bigint UNIQUE NOT NULL,
+  "import_id" bigint NOT NULL
+);
+
+CREATE INDEX ON "import_errors" ("import_id", "line");
+
+CREATE INDEX ON "imported_accounts" ("import_id");
+
+COMMENT ON COLUMN "imports"."kind" IS 'accounts or transfers, the kind of CSV file imported';
+
+COMMENT ON COLUMN "imports"."rows_processed" IS 'CSV records committed, valid or not; an interrupted import resumes after them';
+
+COMMENT ON COLUMN "imported_accounts"."external_id" IS 'The account id in the system the account was imported from';
+
+ALTER TABLE "import_errors" ADD FOREIGN KEY ("import_id") REFERENCES "imports" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "imported_accounts" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "imported_accounts" ADD FOREIGN KEY ("import_id") REFERENCES "imports" ("id");
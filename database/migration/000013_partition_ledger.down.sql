@@ -0,0 +1,73 @@
+ALTER TABLE "entries" RENAME TO "entries_partitioned";
+ALTER TABLE "entries_partitioned" RENAME CONSTRAINT "entries_pkey" TO "entries_partitioned_pkey";
+ALTER SEQUENCE "entries_id_seq" OWNED BY NONE;
+
+CREATE TABLE "entries" (
+  "id" bigint PRIMARY KEY DEFAULT nextval('entries_id_seq'),
+  "account_id" bigint NOT NULL,
+  "amount" float NOT NULL,
+  "created_at" timestamp NOT NULL DEFAULT (now())
+);
+
+ALTER SEQUENCE "entries_id_seq" OWNED BY "entries"."id";
+
+ALTER TABLE "transfers" RENAME TO "transfers_partitioned";
+ALTER TABLE "transfers_partitioned" RENAME CONSTRAINT "transfers_pkey" TO "transfers_partitioned_pkey";
+ALTER SEQUENCE "transfers_id_seq" OWNED BY NONE;
+
+CREATE TABLE "transfers" (
+  "id" bigint PRIMARY KEY DEFAULT nextval('transfers_id_seq'),
+  "from_account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" float NOT NULL,
+  "created_at" timestamp NOT NULL DEFAULT (now()),
+  "reverses_transfer_id" bigint UNIQUE
+);
+
+ALTER SEQUENCE "transfers_id_seq" OWNED BY "transfers"."id";
+
+INSERT INTO "entries" SELECT "id", "account_id", "amount", "created_at" FROM "entries_partitioned";
+
+INSERT INTO "transfers"
+SELECT "id", "from_account_id", "to_account_id", "amount", "created_at", "reverses_transfer_id" FROM "transfers_partitioned";
+
+-- dropping the partitioned tables drops their partitions
+DROP TABLE "entries_partitioned";
+
+DROP TABLE "transfers_partitioned";
+
+DROP FUNCTION IF EXISTS create_monthly_partitions(text, timestamp, timestamp);
+
+CREATE INDEX ON "entries" ("account_id");
+
+CREATE INDEX ON "entries" ("account_id", "created_at", "id");
+
+CREATE INDEX ON "entries" ("account_id", abs("amount"));
+
+CREATE INDEX ON "transfers" ("from_account_id");
+
+CREATE INDEX ON "transfers" ("to_account_id");
+
+CREATE INDEX ON "transfers" ("from_account_id", "to_account_id");
+
+CREATE INDEX ON "transfers" ("from_account_id", "created_at", "id");
+
+CREATE INDEX ON "transfers" ("to_account_id", "created_at", "id");
+
+CREATE INDEX ON "transfers" ("from_account_id", "amount");
+
+CREATE INDEX ON "transfers" ("to_account_id", "amount");
+
+COMMENT ON COLUMN "entries"."amount" IS 'Can be both negative and positive';
+
+COMMENT ON COLUMN "transfers"."amount" IS 'Must be positive';
+
+COMMENT ON COLUMN "transfers"."reverses_transfer_id" IS 'Set on the transfer moving the money of a reversed transfer back';
+
+ALTER TABLE "entries" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "transfers" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "transfers" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "transfers" ADD FOREIGN KEY ("reverses_transfer_id") REFERENCES "transfers" ("id");
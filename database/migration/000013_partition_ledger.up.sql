@@ -0,0 +1,119 @@
+-- create_monthly_partitions creates the missing monthly partitions of a table partitioned by range
+-- of created_at, from the month of from_time through the month of to_time, and returns how many it
+-- created. Rows outside of the monthly partitions, e.g. imported from long ago, land in the default
+-- partition it creates first; the rows of a new month held by the default partition are moved into it.
+CREATE FUNCTION "create_monthly_partitions"(parent text, from_time timestamp, to_time timestamp) RETURNS integer AS $$
+DECLARE
+  month_start timestamp := date_trunc('month', from_time);
+  month_end timestamp;
+  partition_name text;
+  created integer := 0;
+BEGIN
+  -- concurrent calls would create the same partitions
+  PERFORM pg_advisory_xact_lock(hashtext('create_monthly_partitions'), hashtext(parent));
+  IF to_regclass(parent || '_default') IS NULL THEN
+    EXECUTE format('CREATE TABLE %I PARTITION OF %I DEFAULT', parent || '_default', parent);
+  END IF;
+  WHILE month_start <= to_time LOOP
+    month_end := month_start + interval '1 month';
+    partition_name := parent || '_p' || to_char(month_start, 'YYYYMM');
+    IF to_regclass(partition_name) IS NULL THEN
+      EXECUTE format('CREATE TABLE %I (LIKE %I INCLUDING DEFAULTS)', partition_name, parent);
+      EXECUTE format('LOCK TABLE %I IN EXCLUSIVE MODE', parent || '_default');
+      EXECUTE format('WITH moved AS (DELETE FROM %I WHERE created_at >= %L AND created_at < %L RETURNING *) INSERT INTO %I SELECT * FROM moved',
+        parent || '_default', month_start, month_end, partition_name);
+      EXECUTE format('ALTER TABLE %I ATTACH PARTITION %I FOR VALUES FROM (%L) TO (%L)',
+        parent, partition_name, month_start, month_end);
+      created := created + 1;
+    END IF;
+    month_start := month_end;
+  END LOOP;
+  RETURN created;
+END;
+$$ LANGUAGE plpgsql;
+
+-- a quoted 'now()' default is turned into the time the table was created, while created_at now
+-- picks the partition of every row
+ALTER TABLE "accounts" ALTER COLUMN "created_at" SET DEFAULT (now());
+
+-- the tables are rebuilt partitioned, keeping their ids and sequences. The primary key of a
+-- partitioned table must hold the partition key, and so must a unique constraint: the reversal of
+-- a transfer is no longer unique nor a foreign key, ReverseTransferTransaction checks it instead.
+ALTER TABLE "entries" RENAME TO "entries_unpartitioned";
+ALTER TABLE "entries_unpartitioned" RENAME CONSTRAINT "entries_pkey" TO "entries_unpartitioned_pkey";
+ALTER SEQUENCE "entries_id_seq" OWNED BY NONE;
+
+CREATE TABLE "entries" (
+  "id" bigint NOT NULL DEFAULT nextval('entries_id_seq'),
+  "account_id" bigint NOT NULL,
+  "amount" float NOT NULL,
+  "created_at" timestamp NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("id", "created_at")
+) PARTITION BY RANGE ("created_at");
+
+ALTER SEQUENCE "entries_id_seq" OWNED BY "entries"."id";
+
+ALTER TABLE "transfers" RENAME TO "transfers_unpartitioned";
+ALTER TABLE "transfers_unpartitioned" RENAME CONSTRAINT "transfers_pkey" TO "transfers_unpartitioned_pkey";
+ALTER SEQUENCE "transfers_id_seq" OWNED BY NONE;
+
+CREATE TABLE "transfers" (
+  "id" bigint NOT NULL DEFAULT nextval('transfers_id_seq'),
+  "from_account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" float NOT NULL,
+  "created_at" timestamp NOT NULL DEFAULT (now()),
+  "reverses_transfer_id" bigint,
+  PRIMARY KEY ("id", "created_at")
+) PARTITION BY RANGE ("created_at");
+
+ALTER SEQUENCE "transfers_id_seq" OWNED BY "transfers"."id";
+
+SELECT create_monthly_partitions('entries', coalesce(min("created_at"), localtimestamp), localtimestamp + interval '3 months')
+FROM "entries_unpartitioned";
+
+SELECT create_monthly_partitions('transfers', coalesce(min("created_at"), localtimestamp), localtimestamp + interval '3 months')
+FROM "transfers_unpartitioned";
+
+INSERT INTO "entries" SELECT "id", "account_id", "amount", "created_at" FROM "entries_unpartitioned";
+
+INSERT INTO "transfers"
+SELECT "id", "from_account_id", "to_account_id", "amount", "created_at", "reverses_transfer_id" FROM "transfers_unpartitioned";
+
+DROP TABLE "entries_unpartitioned";
+
+DROP TABLE "transfers_unpartitioned";
+
+CREATE INDEX ON "entries" ("account_id");
+
+CREATE INDEX ON "entries" ("account_id", "created_at", "id");
+
+CREATE INDEX ON "entries" ("account_id", abs("amount"));
+
+CREATE INDEX ON "transfers" ("from_account_id");
+
+CREATE INDEX ON "transfers" ("to_account_id");
+
+CREATE INDEX ON "transfers" ("from_account_id", "to_account_id");
+
+CREATE INDEX ON "transfers" ("from_account_id", "created_at", "id");
+
+CREATE INDEX ON "transfers" ("to_account_id", "created_at", "id");
+
+CREATE INDEX ON "transfers" ("from_account_id", "amount");
+
+CREATE INDEX ON "transfers" ("to_account_id", "amount");
+
+CREATE INDEX ON "transfers" ("reverses_transfer_id");
+
+COMMENT ON COLUMN "entries"."amount" IS 'Can be both negative and positive';
+
+COMMENT ON COLUMN "transfers"."amount" IS 'Must be positive';
+
+COMMENT ON COLUMN "transfers"."reverses_transfer_id" IS 'Set on the transfer moving the money of a reversed transfer back';
+
+ALTER TABLE "entries" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "transfers" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "transfers" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
@@ -0,0 +1,11 @@
+-- the archived rows move back, so that no ledger history is lost and the balances match the
+-- entries again without the checkpoints
+INSERT INTO "entries" SELECT * FROM "entries_archive";
+
+INSERT INTO "transfers" SELECT * FROM "transfers_archive";
+
+DROP TABLE IF EXISTS "balance_checkpoints";
+
+DROP TABLE IF EXISTS "transfers_archive";
+
+DROP TABLE IF EXISTS "entries_archive";
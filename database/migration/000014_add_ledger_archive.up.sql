@@ -0,0 +1,46 @@
+CREATE TABLE "entries_archive" (
+  "id" bigint PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "amount" float NOT NULL,
+  "created_at" timestamp NOT NULL
+);
+
+CREATE TABLE "transfers_archive" (
+  "id" bigint PRIMARY KEY,
+  "from_account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" float NOT NULL,
+  "created_at" timestamp NOT NULL,
+  "reverses_transfer_id" bigint
+);
+
+CREATE TABLE "balance_checkpoints" (
+  "account_id" bigint PRIMARY KEY,
+  "balance" float NOT NULL,
+  "archived_entries" bigint NOT NULL,
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "entries_archive" ("account_id", "created_at", "id");
+
+CREATE INDEX ON "entries_archive" ("created_at");
+
+CREATE INDEX ON "transfers_archive" ("from_account_id", "created_at", "id");
+
+CREATE INDEX ON "transfers_archive" ("to_account_id", "created_at", "id");
+
+CREATE INDEX ON "transfers_archive" ("created_at");
+
+COMMENT ON TABLE "entries_archive" IS 'Entries moved out of entries once older than the retention, see balance_checkpoints';
+
+COMMENT ON TABLE "transfers_archive" IS 'Transfers moved out of transfers once older than the retention';
+
+COMMENT ON COLUMN "balance_checkpoints"."balance" IS 'Sum of the archived entries: the balance is this plus the sum of the entries left';
+
+ALTER TABLE "entries_archive" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "transfers_archive" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "transfers_archive" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "balance_checkpoints" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
@@ -0,0 +1,89 @@
+// Package migration embeds the versioned schema migrations and applies them
+// using the same schema_migrations bookkeeping as the migrate CLI.
+package migration
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//go:embed *.sql
+var files embed.FS
+
+// Migration is a single versioned schema change and the statements undoing it
+type Migration struct {
+	Version uint
+	Name    string
+	Up      string
+	Down    string
+}
+
+// load parses files named <version>_<name>.up.sql and <version>_<name>.down.sql
+// into migrations sorted by version. Every version needs both directions.
+func load(fsys fs.FS) ([]Migration, error) {
+	names, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[uint]*Migration, len(names)/2)
+	for _, fileName := range names {
+		base, direction, ok := cutDirection(fileName)
+		if !ok {
+			return nil, fmt.Errorf("invalid migration file name %q: missing .up.sql or .down.sql", fileName)
+		}
+		prefix, name, ok := strings.Cut(base, "_")
+		if !ok {
+			return nil, fmt.Errorf("invalid migration file name %q", fileName)
+		}
+		version, err := strconv.ParseUint(prefix, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %q: %w", fileName, err)
+		}
+
+		migration, ok := byVersion[uint(version)]
+		if !ok {
+			migration = &Migration{Version: uint(version), Name: name}
+			byVersion[uint(version)] = migration
+		}
+		if migration.Name != name {
+			return nil, fmt.Errorf("duplicate migration version %d in %q and %q", version, migration.Name, name)
+		}
+
+		statements, err := fs.ReadFile(fsys, fileName)
+		if err != nil {
+			return nil, err
+		}
+		switch direction {
+		case "up":
+			migration.Up = string(statements)
+		case "down":
+			migration.Down = string(statements)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both an up and a down file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+func cutDirection(fileName string) (base, direction string, ok bool) {
+	for _, direction := range []string{"up", "down"} {
+		if base, ok := strings.CutSuffix(fileName, "."+direction+".sql"); ok {
+			return base, direction, true
+		}
+	}
+	return "", "", false
+}
@@ -0,0 +1,73 @@
+package migration
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad(t *testing.T) {
+	migrations, err := load(fstest.MapFS{
+		"000002_add_users.up.sql":      {Data: []byte("CREATE TABLE users ();")},
+		"000002_add_users.down.sql":    {Data: []byte("DROP TABLE users;")},
+		"000001_init_schema.up.sql":    {Data: []byte("CREATE TABLE accounts ();")},
+		"000001_init_schema.down.sql":  {Data: []byte("DROP TABLE accounts;")},
+		"000010_add_sessions.up.sql":   {Data: []byte("CREATE TABLE sessions ();")},
+		"000010_add_sessions.down.sql": {Data: []byte("DROP TABLE sessions;")},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, migrations, 3)
+	assert.Equal(t, uint(1), migrations[0].Version)
+	assert.Equal(t, "init_schema", migrations[0].Name)
+	assert.Equal(t, "CREATE TABLE accounts ();", migrations[0].Up)
+	assert.Equal(t, "DROP TABLE accounts;", migrations[0].Down)
+	assert.Equal(t, uint(10), migrations[2].Version)
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	_, err := load(fstest.MapFS{"init.up.sql": {}})
+	assert.Error(t, err)
+
+	_, err = load(fstest.MapFS{"first_init.up.sql": {}})
+	assert.Error(t, err)
+
+	_, err = load(fstest.MapFS{"1_init.sql": {}})
+	assert.ErrorContains(t, err, "missing .up.sql or .down.sql")
+
+	_, err = load(fstest.MapFS{"1_init.up.sql": {Data: []byte("x")}, "0001_again.up.sql": {Data: []byte("y")}})
+	assert.ErrorContains(t, err, "duplicate migration version 1")
+
+	_, err = load(fstest.MapFS{"1_init.up.sql": {Data: []byte("CREATE TABLE accounts ();")}})
+	assert.ErrorContains(t, err, "needs both an up and a down file")
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	migrator, err := New(nil)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, migrator.migrations)
+	assert.Equal(t, uint(1), migrator.migrations[0].Version)
+	assert.Equal(t, migrator.migrations[len(migrator.migrations)-1].Version, migrator.LatestVersion())
+
+	for i, migration := range migrator.migrations {
+		assert.NotEmpty(t, migration.Down, "migration %d has no down", migration.Version)
+		index, err := migrator.index(migration.Version)
+		assert.NoError(t, err)
+		assert.Equal(t, i, index)
+	}
+}
+
+func TestMigrator_Index(t *testing.T) {
+	migrator := &Migrator{migrations: []Migration{{Version: 1}, {Version: 3}}}
+
+	index, err := migrator.index(0)
+	assert.NoError(t, err)
+	assert.Equal(t, -1, index)
+
+	index, err = migrator.index(3)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, index)
+
+	_, err = migrator.index(2)
+	assert.ErrorIs(t, err, ErrUnknownVersion)
+}
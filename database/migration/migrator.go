@@ -0,0 +1,285 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// lockID is the key of the Postgres advisory lock held while migrating,
+// so that instances starting together don't apply the same migration twice
+const lockID int64 = 7_340_296_115_223_680_001
+
+var (
+	// ErrDirty is returned when a previous migration failed half way and needs to be repaired with Force
+	ErrDirty = errors.New("database is in a dirty migration state")
+	// ErrUnknownVersion is returned when migrating to a version that has no migration
+	ErrUnknownVersion = errors.New("unknown migration version")
+)
+
+// Migrator applies the embedded migrations to a database
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+// New creates a Migrator for the embedded migrations
+func New(db *sql.DB) (*Migrator, error) {
+	migrations, err := load(files)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// conn is the subset of *sql.DB and *sql.Conn the migrator runs on
+type conn interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+// LatestVersion returns the version the schema has once every migration is applied
+func (migrator *Migrator) LatestVersion() uint {
+	if len(migrator.migrations) == 0 {
+		return 0
+	}
+	return migrator.migrations[len(migrator.migrations)-1].Version
+}
+
+// Version returns the current schema version, 0 when nothing was applied yet
+func (migrator *Migrator) Version(ctx context.Context) (version uint, dirty bool, err error) {
+	return readVersion(ctx, migrator.db)
+}
+
+// MigrationStatus tells whether a migration is part of the current schema
+type MigrationStatus struct {
+	Version uint   `json:"version"`
+	Name    string `json:"name"`
+	Applied bool   `json:"applied"`
+}
+
+// Status is the current schema version and the state of every known migration
+type Status struct {
+	Version    uint              `json:"version"`
+	Dirty      bool              `json:"dirty"`
+	Migrations []MigrationStatus `json:"migrations"`
+}
+
+// Status reports the current version and which migrations are applied
+func (migrator *Migrator) Status(ctx context.Context) (Status, error) {
+	version, dirty, err := migrator.Version(ctx)
+	if err != nil {
+		return Status{}, err
+	}
+
+	status := Status{Version: version, Dirty: dirty, Migrations: make([]MigrationStatus, len(migrator.migrations))}
+	for i, migration := range migrator.migrations {
+		status.Migrations[i] = MigrationStatus{
+			Version: migration.Version,
+			Name:    migration.Name,
+			Applied: migration.Version <= version,
+		}
+	}
+	return status, nil
+}
+
+// Up applies every migration newer than the current version
+func (migrator *Migrator) Up(ctx context.Context) error {
+	return migrator.Goto(ctx, migrator.LatestVersion())
+}
+
+// Down reverts the last n applied migrations
+func (migrator *Migrator) Down(ctx context.Context, n int) error {
+	if n <= 0 {
+		return fmt.Errorf("number of migrations to revert must be positive, got %d", n)
+	}
+	return migrator.withLock(ctx, func(c conn) error {
+		current, err := cleanVersion(ctx, c)
+		if err != nil {
+			return err
+		}
+		index, err := migrator.index(current)
+		if err != nil {
+			return err
+		}
+
+		target := uint(0)
+		if index-n >= 0 {
+			target = migrator.migrations[index-n].Version
+		}
+		return migrator.migrate(ctx, c, current, target)
+	})
+}
+
+// Goto migrates up or down to the given version, 0 reverts every migration
+func (migrator *Migrator) Goto(ctx context.Context, version uint) error {
+	if _, err := migrator.index(version); err != nil {
+		return err
+	}
+	return migrator.withLock(ctx, func(c conn) error {
+		current, err := cleanVersion(ctx, c)
+		if err != nil {
+			return err
+		}
+		return migrator.migrate(ctx, c, current, version)
+	})
+}
+
+// Force records version as the current clean version without running any migration.
+// It repairs a dirty state once the failed migration was fixed by hand.
+func (migrator *Migrator) Force(ctx context.Context, version uint) error {
+	if _, err := migrator.index(version); err != nil {
+		return err
+	}
+	return migrator.withLock(ctx, func(c conn) error {
+		return executeTransaction(ctx, c, func(tx *sql.Tx) error {
+			return setVersion(ctx, tx, version, false)
+		})
+	})
+}
+
+// index returns the position of version in the migrations, -1 for version 0
+func (migrator *Migrator) index(version uint) (int, error) {
+	if version == 0 {
+		return -1, nil
+	}
+	for i, migration := range migrator.migrations {
+		if migration.Version == version {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("%w %d", ErrUnknownVersion, version)
+}
+
+// migrate runs the migrations between current and target one at a time. The version is
+// marked dirty before each step and cleaned in the step's transaction, so a step that
+// fails outside of it, e.g. because of a lost connection, leaves the dirty flag behind.
+func (migrator *Migrator) migrate(ctx context.Context, c conn, current, target uint) error {
+	if target >= current {
+		for _, migration := range migrator.migrations {
+			if migration.Version <= current || migration.Version > target {
+				continue
+			}
+			if err := step(ctx, c, migration.Version, migration.Up); err != nil {
+				return fmt.Errorf("migration %d_%s up: %w", migration.Version, migration.Name, err)
+			}
+		}
+		return nil
+	}
+
+	for i := len(migrator.migrations) - 1; i >= 0; i-- {
+		migration := migrator.migrations[i]
+		if migration.Version > current || migration.Version <= target {
+			continue
+		}
+		previous := uint(0)
+		if i > 0 {
+			previous = migrator.migrations[i-1].Version
+		}
+		if err := step(ctx, c, previous, migration.Down); err != nil {
+			return fmt.Errorf("migration %d_%s down: %w", migration.Version, migration.Name, err)
+		}
+	}
+	return nil
+}
+
+// step runs statements and moves the schema to version
+func step(ctx context.Context, c conn, version uint, statements string) error {
+	err := executeTransaction(ctx, c, func(tx *sql.Tx) error {
+		return setVersion(ctx, tx, version, true)
+	})
+	if err != nil {
+		return err
+	}
+	return executeTransaction(ctx, c, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, statements); err != nil {
+			return err
+		}
+		return setVersion(ctx, tx, version, false)
+	})
+}
+
+// withLock runs fn on a single connection holding the migration advisory lock
+func (migrator *Migrator) withLock(ctx context.Context, fn func(c conn) error) error {
+	c, err := migrator.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if _, err := c.ExecContext(ctx, "SELECT pg_advisory_lock($1)", lockID); err != nil {
+		return fmt.Errorf("cannot acquire migration lock: %w", err)
+	}
+	// unlock with a fresh context so that a cancelled migration still releases the lock
+	defer c.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", lockID)
+
+	if err := ensureVersionTable(ctx, c); err != nil {
+		return err
+	}
+	return fn(c)
+}
+
+func executeTransaction(ctx context.Context, c conn, fn func(*sql.Tx) error) error {
+	tx, err := c.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	err = fn(tx)
+	if err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("transaction error %w; rollback error failed: %w", err, rbErr)
+		}
+		return err
+	}
+	return tx.Commit()
+}
+
+func ensureVersionTable(ctx context.Context, c conn) error {
+	_, err := c.ExecContext(ctx,
+		"CREATE TABLE IF NOT EXISTS schema_migrations (version bigint NOT NULL PRIMARY KEY, dirty boolean NOT NULL)")
+	return err
+}
+
+// readVersion reads the version without creating schema_migrations, which only withLock does, so
+// that checking it, e.g. from the readiness probe, never runs DDL. No table means version 0.
+func readVersion(ctx context.Context, c conn) (version uint, dirty bool, err error) {
+	var exists bool
+	if err := c.QueryRowContext(ctx, "SELECT to_regclass('schema_migrations') IS NOT NULL").Scan(&exists); err != nil {
+		return 0, false, err
+	}
+	if !exists {
+		return 0, false, nil
+	}
+
+	err = c.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	return version, dirty, err
+}
+
+// cleanVersion returns the current version, refusing to go on from a dirty one
+func cleanVersion(ctx context.Context, c conn) (uint, error) {
+	version, dirty, err := readVersion(ctx, c)
+	if err != nil {
+		return 0, err
+	}
+	if dirty {
+		return 0, fmt.Errorf("%w at version %d", ErrDirty, version)
+	}
+	return version, nil
+}
+
+// setVersion replaces the single row of schema_migrations, no row means version 0
+func setVersion(ctx context.Context, tx *sql.Tx, version uint, dirty bool) error {
+	if _, err := tx.ExecContext(ctx, "TRUNCATE schema_migrations"); err != nil {
+		return err
+	}
+	if version == 0 && !dirty {
+		return nil
+	}
+	_, err := tx.ExecContext(ctx, "INSERT INTO schema_migrations (version, dirty) VALUES ($1, $2)", version, dirty)
+	return err
+}
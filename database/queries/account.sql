@@ -25,4 +25,100 @@ WHERE id = $1
 RETURNING *;
 
 -- name: DeleteAccount :exec
-DELETE FROM accounts WHERE id = $1;
\ No newline at end of file
+DELETE FROM accounts WHERE id = $1;
+
+-- name: ListAccountsByOwner :many
+SELECT * FROM accounts
+WHERE owner = $1
+ORDER BY id
+LIMIT $2
+OFFSET $3;
+
+-- name: GetAccountForUpdate :one
+SELECT * FROM accounts
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: AddAccountBalance :one
+UPDATE accounts
+SET balance = balance + sqlc.arg(amount)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: SetAccountFrozen :one
+UPDATE accounts
+SET is_frozen = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: ListBalanceMismatches :many
+SELECT
+    accounts.id,
+    accounts.owner,
+    accounts.currency,
+    accounts.balance,
+    (COALESCE(balance_checkpoints.balance, 0) + COALESCE(SUM(entries.amount), 0))::float AS entries_total
+FROM accounts
+LEFT JOIN balance_checkpoints ON balance_checkpoints.account_id = accounts.id
+LEFT JOIN entries ON entries.account_id = accounts.id
+GROUP BY accounts.id, balance_checkpoints.balance
+HAVING ABS(accounts.balance - COALESCE(balance_checkpoints.balance, 0) - COALESCE(SUM(entries.amount), 0)) > sqlc.arg(tolerance)::float
+ORDER BY accounts.id;
+
+-- name: ListAccountsByOwnerAfter :many
+SELECT * FROM accounts
+WHERE owner = sqlc.arg(owner)
+    AND (created_at, id) > (sqlc.arg(after_created_at)::timestamp, sqlc.arg(after_id)::bigint)
+ORDER BY created_at, id
+LIMIT sqlc.arg(limit_count);
+
+-- name: ListAccountsByOwnerBefore :many
+SELECT * FROM accounts
+WHERE owner = sqlc.arg(owner)
+    AND (created_at, id) < (sqlc.arg(before_created_at)::timestamp, sqlc.arg(before_id)::bigint)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(limit_count);
+
+-- name: CopyAccounts :copyfrom
+INSERT INTO accounts (
+    id,
+    owner,
+    balance,
+    currency,
+    country_code,
+    created_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+);
+
+-- name: NextAccountIDs :many
+SELECT nextval(pg_get_serial_sequence('accounts', 'id'))::bigint AS id
+FROM generate_series(1, sqlc.arg(count)::int);
+
+-- name: AddAccountBalances :many
+UPDATE accounts
+SET balance = accounts.balance + moves.amount
+FROM (
+    SELECT unnest(sqlc.arg(ids)::bigint[]) AS id, unnest(sqlc.arg(amounts)::float[]) AS amount
+) AS moves
+WHERE accounts.id = moves.id
+RETURNING accounts.*;
+
+-- name: ExportAccounts :many
+SELECT * FROM accounts
+WHERE id > sqlc.arg(after_id)
+ORDER BY id
+LIMIT sqlc.arg(limit_count);
+
+-- name: RestoreAccounts :copyfrom
+INSERT INTO accounts (
+    id,
+    owner,
+    balance,
+    currency,
+    created_at,
+    country_code,
+    is_frozen
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+);
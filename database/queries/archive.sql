@@ -0,0 +1,84 @@
+-- name: ArchiveEntries :one
+WITH moved AS (
+    DELETE FROM entries
+    WHERE (id, created_at) IN (
+        SELECT id, created_at FROM entries
+        WHERE created_at < sqlc.arg(before)::timestamp
+        ORDER BY created_at, id
+        LIMIT sqlc.arg(limit_count)
+    )
+    RETURNING *
+), archived AS (
+    INSERT INTO entries_archive SELECT * FROM moved
+), checkpoints AS (
+    INSERT INTO balance_checkpoints (account_id, balance, archived_entries)
+    SELECT account_id, sum(amount), count(*) FROM moved GROUP BY account_id
+    ON CONFLICT (account_id) DO UPDATE SET
+        balance = balance_checkpoints.balance + excluded.balance,
+        archived_entries = balance_checkpoints.archived_entries + excluded.archived_entries,
+        updated_at = now()
+)
+SELECT count(*) FROM moved;
+
+-- name: ArchiveTransfers :one
+WITH moved AS (
+    DELETE FROM transfers
+    WHERE (id, created_at) IN (
+        SELECT id, created_at FROM transfers
+        WHERE created_at < sqlc.arg(before)::timestamp
+        ORDER BY created_at, id
+        LIMIT sqlc.arg(limit_count)
+    )
+    RETURNING *
+), archived AS (
+    INSERT INTO transfers_archive SELECT * FROM moved
+)
+SELECT count(*) FROM moved;
+
+-- name: RestoreArchivedEntries :one
+WITH moved AS (
+    DELETE FROM entries_archive
+    WHERE id IN (
+        SELECT id FROM entries_archive
+        WHERE created_at >= sqlc.arg(since)::timestamp
+        ORDER BY created_at, id
+        LIMIT sqlc.arg(limit_count)
+    )
+    RETURNING *
+), restored AS (
+    INSERT INTO entries SELECT * FROM moved
+), checkpoints AS (
+    UPDATE balance_checkpoints SET
+        balance = balance_checkpoints.balance - totals.balance,
+        archived_entries = balance_checkpoints.archived_entries - totals.archived_entries,
+        updated_at = now()
+    FROM (
+        SELECT account_id, sum(amount) AS balance, count(*) AS archived_entries
+        FROM moved GROUP BY account_id
+    ) AS totals
+    WHERE balance_checkpoints.account_id = totals.account_id
+)
+SELECT count(*) FROM moved;
+
+-- name: RestoreArchivedTransfers :one
+WITH moved AS (
+    DELETE FROM transfers_archive
+    WHERE id IN (
+        SELECT id FROM transfers_archive
+        WHERE created_at >= sqlc.arg(since)::timestamp
+        ORDER BY created_at, id
+        LIMIT sqlc.arg(limit_count)
+    )
+    RETURNING *
+), restored AS (
+    INSERT INTO transfers SELECT * FROM moved
+)
+SELECT count(*) FROM moved;
+
+-- name: GetBalanceCheckpoint :one
+SELECT * FROM balance_checkpoints
+WHERE account_id = $1 LIMIT 1;
+
+-- name: GetArchivedTransfer :one
+SELECT * FROM transfers_archive
+WHERE id = $1 LIMIT 1;
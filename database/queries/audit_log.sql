@@ -0,0 +1,36 @@
+-- name: CreateAuditLog :one
+INSERT INTO audit_log (
+    actor,
+    action,
+    entity_type,
+    entity_id,
+    before,
+    after,
+    request_id
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+) RETURNING *;
+
+-- name: ListAuditLogs :many
+SELECT * FROM audit_log
+WHERE
+    (sqlc.narg(entity_type)::varchar IS NULL OR entity_type = sqlc.narg(entity_type))
+    AND (sqlc.narg(entity_id)::bigint IS NULL OR entity_id = sqlc.narg(entity_id))
+    AND created_at >= coalesce(sqlc.narg(start_time)::timestamptz, '-infinity')
+    AND created_at < coalesce(sqlc.narg(end_time)::timestamptz, 'infinity')
+    AND id > sqlc.arg(after_id)
+ORDER BY id
+LIMIT sqlc.arg(limit_count);
+
+-- name: CopyAuditLogs :copyfrom
+INSERT INTO audit_log (
+    actor,
+    action,
+    entity_type,
+    entity_id,
+    before,
+    after,
+    request_id
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+);
@@ -12,10 +12,103 @@ WHERE id = $1 LIMIT 1;
 
 -- name: ListEntries :many
 SELECT * FROM entries
-WHERE account_id = ANY($1::bigint[])
+WHERE account_id = ANY(sqlc.arg(account_ids)::bigint[])
+    AND created_at >= coalesce(sqlc.narg(start_time)::timestamp, '-infinity')
+    AND created_at < coalesce(sqlc.narg(end_time)::timestamp, 'infinity')
 ORDER BY id
-LIMIT $2
-OFFSET $3;
+LIMIT sqlc.arg(limit_count)
+OFFSET sqlc.arg(offset_count);
 
 -- name: DeleteEntry :exec
-DELETE FROM entries WHERE id = $1;
\ No newline at end of file
+DELETE FROM entries WHERE id = $1;
+
+-- name: ListLatestEntries :many
+SELECT * FROM entries
+WHERE account_id = $1
+ORDER BY id DESC
+LIMIT $2;
+
+-- name: ListEntriesAfter :many
+SELECT * FROM entries
+WHERE account_id = ANY(sqlc.arg(account_ids)::bigint[])
+    AND CASE sqlc.arg(direction)::text WHEN 'in' THEN amount > 0 WHEN 'out' THEN amount < 0 ELSE true END
+    AND created_at >= coalesce(sqlc.narg(start_time)::timestamp, '-infinity')
+    AND created_at < coalesce(sqlc.narg(end_time)::timestamp, 'infinity')
+    AND abs(amount) >= coalesce(sqlc.narg(min_amount)::float, 0)
+    AND abs(amount) <= coalesce(sqlc.narg(max_amount)::float, 'infinity')
+    AND (created_at, id) > (sqlc.arg(after_created_at)::timestamp, sqlc.arg(after_id)::bigint)
+ORDER BY created_at, id
+LIMIT sqlc.arg(limit_count);
+
+-- name: ListEntriesBefore :many
+SELECT * FROM entries
+WHERE account_id = ANY(sqlc.arg(account_ids)::bigint[])
+    AND CASE sqlc.arg(direction)::text WHEN 'in' THEN amount > 0 WHEN 'out' THEN amount < 0 ELSE true END
+    AND created_at >= coalesce(sqlc.narg(start_time)::timestamp, '-infinity')
+    AND created_at < coalesce(sqlc.narg(end_time)::timestamp, 'infinity')
+    AND abs(amount) >= coalesce(sqlc.narg(min_amount)::float, 0)
+    AND abs(amount) <= coalesce(sqlc.narg(max_amount)::float, 'infinity')
+    AND (created_at, id) < (sqlc.arg(before_created_at)::timestamp, sqlc.arg(before_id)::bigint)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(limit_count);
+
+-- name: CopyEntries :copyfrom
+INSERT INTO entries (
+    account_id,
+    amount,
+    created_at
+) VALUES (
+    $1, $2, $3
+);
+
+-- name: ExportEntries :many
+SELECT * FROM (
+    SELECT * FROM entries
+    UNION ALL
+    SELECT * FROM entries_archive
+) AS entries
+WHERE id > sqlc.arg(after_id)::bigint
+ORDER BY id
+LIMIT sqlc.arg(limit_count);
+
+-- name: RestoreEntries :copyfrom
+INSERT INTO entries (
+    id,
+    account_id,
+    amount,
+    created_at
+) VALUES (
+    $1, $2, $3, $4
+);
+
+-- name: ListEntriesWithArchiveAfter :many
+SELECT * FROM (
+    SELECT * FROM entries
+    UNION ALL
+    SELECT * FROM entries_archive
+) AS entries
+WHERE account_id = ANY(sqlc.arg(account_ids)::bigint[])
+    AND CASE sqlc.arg(direction)::text WHEN 'in' THEN amount > 0 WHEN 'out' THEN amount < 0 ELSE true END
+    AND created_at >= coalesce(sqlc.narg(start_time)::timestamp, '-infinity')
+    AND created_at < coalesce(sqlc.narg(end_time)::timestamp, 'infinity')
+    AND abs(amount) >= coalesce(sqlc.narg(min_amount)::float, 0)
+    AND abs(amount) <= coalesce(sqlc.narg(max_amount)::float, 'infinity')
+    AND (created_at, id) > (sqlc.arg(after_created_at)::timestamp, sqlc.arg(after_id)::bigint)
+ORDER BY created_at, id
+LIMIT sqlc.arg(limit_count);
+
+-- name: ListEntriesWithArchiveBefore :many
+SELECT * FROM (
+    SELECT * FROM entries
+    UNION ALL
+    SELECT * FROM entries_archive
+) AS entries
+WHERE account_id = ANY(sqlc.arg(account_ids)::bigint[])
+    AND CASE sqlc.arg(direction)::text WHEN 'in' THEN amount > 0 WHEN 'out' THEN amount < 0 ELSE true END
+    AND created_at >= coalesce(sqlc.narg(start_time)::timestamp, '-infinity')
+    AND created_at < coalesce(sqlc.narg(end_time)::timestamp, 'infinity')
+    AND abs(amount) >= coalesce(sqlc.narg(min_amount)::float, 0)
+    AND abs(amount) <= coalesce(sqlc.narg(max_amount)::float, 'infinity')
+    AND (created_at, id) < (sqlc.arg(before_created_at)::timestamp, sqlc.arg(before_id)::bigint)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(limit_count);
@@ -0,0 +1,64 @@
+-- name: GetOrCreateImport :one
+INSERT INTO imports (
+    name,
+    kind
+) VALUES (
+    $1, $2
+)
+ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
+RETURNING *;
+
+-- name: GetImport :one
+SELECT * FROM imports
+WHERE name = $1 LIMIT 1;
+
+-- name: AdvanceImport :one
+UPDATE imports
+SET
+    rows_processed = rows_processed + sqlc.arg(rows_processed),
+    rows_imported = rows_imported + sqlc.arg(rows_imported),
+    rows_failed = rows_failed + sqlc.arg(rows_failed)
+WHERE id = sqlc.arg(id) AND rows_processed = sqlc.arg(after_rows_processed) AND finished_at IS NULL
+RETURNING *;
+
+-- name: FinishImport :one
+UPDATE imports
+SET finished_at = now()
+WHERE id = $1 AND finished_at IS NULL
+RETURNING *;
+
+-- name: CopyImportErrors :copyfrom
+INSERT INTO import_errors (
+    import_id,
+    line,
+    message
+) VALUES (
+    $1, $2, $3
+);
+
+-- name: ListImportErrors :many
+SELECT * FROM import_errors
+WHERE import_id = sqlc.arg(import_id) AND id > sqlc.arg(after_id)
+ORDER BY id
+LIMIT sqlc.arg(limit_count);
+
+-- name: CopyImportedAccounts :copyfrom
+INSERT INTO imported_accounts (
+    external_id,
+    account_id,
+    import_id
+) VALUES (
+    $1, $2, $3
+);
+
+-- name: ListImportedExternalIDs :many
+SELECT external_id FROM imported_accounts
+WHERE external_id = ANY(sqlc.arg(external_ids)::varchar[]);
+
+-- name: ListImportedAccountsForUpdate :many
+SELECT imported_accounts.external_id, accounts.*
+FROM imported_accounts
+JOIN accounts ON accounts.id = imported_accounts.account_id
+WHERE imported_accounts.external_id = ANY(sqlc.arg(external_ids)::varchar[])
+ORDER BY accounts.id
+FOR NO KEY UPDATE OF accounts;
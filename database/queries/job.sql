@@ -0,0 +1,68 @@
+-- name: EnqueueJob :one
+INSERT INTO jobs (
+    kind,
+    payload,
+    priority,
+    unique_key,
+    max_attempts,
+    run_at
+) VALUES (
+    sqlc.arg(kind),
+    sqlc.arg(payload),
+    sqlc.arg(priority),
+    sqlc.narg(unique_key),
+    sqlc.arg(max_attempts),
+    coalesce(sqlc.narg(run_at)::timestamptz, now())
+)
+ON CONFLICT (kind, unique_key) WHERE status IN ('pending', 'running') DO NOTHING
+RETURNING *;
+
+-- name: GetJob :one
+SELECT * FROM jobs
+WHERE id = $1 LIMIT 1;
+
+-- name: ClaimJobs :many
+-- A running job whose worker missed locked_until is claimed again while it has attempts left,
+-- and failed otherwise.
+WITH exhausted AS (
+    UPDATE jobs
+    SET
+        status = 'failed',
+        locked_until = NULL,
+        last_error = 'timed out on the last attempt',
+        finished_at = now()
+    WHERE status = 'running' AND locked_until < now() AND attempts >= max_attempts
+)
+UPDATE jobs
+SET
+    status = 'running',
+    attempts = attempts + 1,
+    locked_until = sqlc.arg(locked_until)::timestamptz
+WHERE id IN (
+    SELECT id FROM jobs
+    WHERE (status = 'pending' AND run_at <= now())
+        OR (status = 'running' AND locked_until < now() AND attempts < max_attempts)
+    ORDER BY priority DESC, run_at, id
+    LIMIT sqlc.arg(limit_count)
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: CompleteJob :execrows
+UPDATE jobs
+SET
+    status = 'succeeded',
+    locked_until = NULL,
+    last_error = '',
+    finished_at = now()
+WHERE id = sqlc.arg(id) AND status = 'running' AND attempts = sqlc.arg(attempts);
+
+-- name: FailJob :execrows
+UPDATE jobs
+SET
+    status = sqlc.arg(status),
+    run_at = sqlc.arg(run_at),
+    locked_until = NULL,
+    last_error = sqlc.arg(last_error),
+    finished_at = CASE WHEN sqlc.arg(status) = 'failed' THEN now() END
+WHERE id = sqlc.arg(id) AND status = 'running' AND attempts = sqlc.arg(attempts);
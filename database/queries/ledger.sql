@@ -0,0 +1,21 @@
+-- name: HasLedger :one
+SELECT EXISTS (
+    SELECT 1 FROM accounts
+    UNION ALL SELECT 1 FROM entries
+    UNION ALL SELECT 1 FROM transfers
+    UNION ALL SELECT 1 FROM entries_archive
+    UNION ALL SELECT 1 FROM transfers_archive
+) AS has_ledger;
+
+-- name: ResetLedgerSequences :exec
+SELECT
+    setval(pg_get_serial_sequence('accounts', 'id'), coalesce((SELECT max(id) FROM accounts), 0) + 1, false),
+    setval(pg_get_serial_sequence('entries', 'id'), coalesce((SELECT max(id) FROM entries), 0) + 1, false),
+    setval(pg_get_serial_sequence('transfers', 'id'), coalesce((SELECT max(id) FROM transfers), 0) + 1, false);
+
+-- name: CreateMonthlyPartitions :one
+SELECT create_monthly_partitions(
+    sqlc.arg(table_name)::text,
+    localtimestamp,
+    localtimestamp + make_interval(months => sqlc.arg(months_ahead)::integer)
+)::integer AS created;
@@ -0,0 +1,28 @@
+-- name: CreateSession :one
+INSERT INTO sessions (
+    id,
+    username,
+    refresh_token,
+    user_agent,
+    client_ip,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: GetSession :one
+SELECT * FROM sessions
+WHERE id = $1 LIMIT 1;
+
+-- name: ListSessions :many
+SELECT * FROM sessions
+WHERE username = $1
+ORDER BY created_at DESC
+LIMIT $2
+OFFSET $3;
+
+-- name: RevokeSession :one
+UPDATE sessions
+SET is_revoked = true
+WHERE id = $1 AND username = $2 AND is_revoked = false
+RETURNING *;
@@ -2,23 +2,130 @@
 INSERT INTO transfers (
 	from_account_id,
     to_account_id,
-    amount
+    amount,
+    reverses_transfer_id
 ) VALUES (
-	$1, $2, $3
+	$1, $2, $3, $4
 ) RETURNING *;
 
 -- name: GetTransfer :one
 SELECT * FROM transfers
 WHERE id = $1 LIMIT 1;
 
+-- name: GetTransferForUpdate :one
+SELECT * FROM transfers
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: GetTransferReversal :one
+SELECT * FROM transfers
+WHERE reverses_transfer_id = $1 LIMIT 1;
+
 -- name: ListTransfers :many
 SELECT * FROM transfers
-WHERE
-    from_account_id = $1 OR
-    to_account_id = $2
+WHERE (from_account_id = sqlc.arg(from_account_id) OR to_account_id = sqlc.arg(to_account_id))
+    AND created_at >= coalesce(sqlc.narg(start_time)::timestamp, '-infinity')
+    AND created_at < coalesce(sqlc.narg(end_time)::timestamp, 'infinity')
 ORDER BY id
-LIMIT $3
-OFFSET $4;
+LIMIT sqlc.arg(limit_count)
+OFFSET sqlc.arg(offset_count);
 
 -- name: DeleteTransfer :exec
-DELETE FROM transfers WHERE id = $1;
\ No newline at end of file
+DELETE FROM transfers WHERE id = $1;
+
+-- name: ListTransfersAfter :many
+SELECT * FROM transfers
+WHERE
+    ((sqlc.arg(direction)::text <> 'in' AND from_account_id = sqlc.arg(account_id))
+        OR (sqlc.arg(direction)::text <> 'out' AND to_account_id = sqlc.arg(account_id)))
+    AND created_at >= coalesce(sqlc.narg(start_time)::timestamp, '-infinity')
+    AND created_at < coalesce(sqlc.narg(end_time)::timestamp, 'infinity')
+    AND amount >= coalesce(sqlc.narg(min_amount)::float, 0)
+    AND amount <= coalesce(sqlc.narg(max_amount)::float, 'infinity')
+    AND (created_at, id) > (sqlc.arg(after_created_at)::timestamp, sqlc.arg(after_id)::bigint)
+ORDER BY created_at, id
+LIMIT sqlc.arg(limit_count);
+
+-- name: ListTransfersBefore :many
+SELECT * FROM transfers
+WHERE
+    ((sqlc.arg(direction)::text <> 'in' AND from_account_id = sqlc.arg(account_id))
+        OR (sqlc.arg(direction)::text <> 'out' AND to_account_id = sqlc.arg(account_id)))
+    AND created_at >= coalesce(sqlc.narg(start_time)::timestamp, '-infinity')
+    AND created_at < coalesce(sqlc.narg(end_time)::timestamp, 'infinity')
+    AND amount >= coalesce(sqlc.narg(min_amount)::float, 0)
+    AND amount <= coalesce(sqlc.narg(max_amount)::float, 'infinity')
+    AND (created_at, id) < (sqlc.arg(before_created_at)::timestamp, sqlc.arg(before_id)::bigint)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(limit_count);
+
+-- name: CopyTransfers :copyfrom
+INSERT INTO transfers (
+    id,
+    from_account_id,
+    to_account_id,
+    amount,
+    created_at
+) VALUES (
+    $1, $2, $3, $4, $5
+);
+
+-- name: NextTransferIDs :many
+SELECT nextval(pg_get_serial_sequence('transfers', 'id'))::bigint AS id
+FROM generate_series(1, sqlc.arg(count)::int);
+
+-- name: ExportTransfers :many
+SELECT * FROM (
+    SELECT * FROM transfers
+    UNION ALL
+    SELECT * FROM transfers_archive
+) AS transfers
+WHERE id > sqlc.arg(after_id)::bigint
+ORDER BY id
+LIMIT sqlc.arg(limit_count);
+
+-- name: RestoreTransfers :copyfrom
+INSERT INTO transfers (
+    id,
+    from_account_id,
+    to_account_id,
+    amount,
+    created_at,
+    reverses_transfer_id
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+);
+
+-- name: ListTransfersWithArchiveAfter :many
+SELECT * FROM (
+    SELECT * FROM transfers
+    UNION ALL
+    SELECT * FROM transfers_archive
+) AS transfers
+WHERE
+    ((sqlc.arg(direction)::text <> 'in' AND from_account_id = sqlc.arg(account_id)::bigint)
+        OR (sqlc.arg(direction)::text <> 'out' AND to_account_id = sqlc.arg(account_id)::bigint))
+    AND created_at >= coalesce(sqlc.narg(start_time)::timestamp, '-infinity')
+    AND created_at < coalesce(sqlc.narg(end_time)::timestamp, 'infinity')
+    AND amount >= coalesce(sqlc.narg(min_amount)::float, 0)
+    AND amount <= coalesce(sqlc.narg(max_amount)::float, 'infinity')
+    AND (created_at, id) > (sqlc.arg(after_created_at)::timestamp, sqlc.arg(after_id)::bigint)
+ORDER BY created_at, id
+LIMIT sqlc.arg(limit_count);
+
+-- name: ListTransfersWithArchiveBefore :many
+SELECT * FROM (
+    SELECT * FROM transfers
+    UNION ALL
+    SELECT * FROM transfers_archive
+) AS transfers
+WHERE
+    ((sqlc.arg(direction)::text <> 'in' AND from_account_id = sqlc.arg(account_id)::bigint)
+        OR (sqlc.arg(direction)::text <> 'out' AND to_account_id = sqlc.arg(account_id)::bigint))
+    AND created_at >= coalesce(sqlc.narg(start_time)::timestamp, '-infinity')
+    AND created_at < coalesce(sqlc.narg(end_time)::timestamp, 'infinity')
+    AND amount >= coalesce(sqlc.narg(min_amount)::float, 0)
+    AND amount <= coalesce(sqlc.narg(max_amount)::float, 'infinity')
+    AND (created_at, id) < (sqlc.arg(before_created_at)::timestamp, sqlc.arg(before_id)::bigint)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(limit_count);
@@ -0,0 +1,224 @@
+// Package schema detects drift between the migrations, the declared schema in
+// simple_bank.sql and the models generated by sqlc.
+package schema
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// Column is a column as Postgres reports it once the schema is applied
+type Column struct {
+	Name     string
+	Type     string
+	Nullable bool
+	// Default is the default expression, empty without one. A constant default where an
+	// expression was meant, like a quoted 'now()' frozen when the table was created, differs.
+	Default string
+}
+
+func (column Column) String() string {
+	nullable := "NOT NULL"
+	if column.Nullable {
+		nullable = "NULL"
+	}
+	if column.Default == "" {
+		return fmt.Sprintf("%s %s %s", column.Name, column.Type, nullable)
+	}
+	return fmt.Sprintf("%s %s %s DEFAULT %s", column.Name, column.Type, nullable, column.Default)
+}
+
+// Table holds the columns, foreign keys and indexes of a table
+type Table struct {
+	Name        string
+	Columns     []Column
+	ForeignKeys []string
+	Indexes     []string
+}
+
+// Catalog maps table names to their definition
+type Catalog map[string]*Table
+
+// ignoredTables are bookkeeping tables that only exist in migrated databases
+var ignoredTables = map[string]bool{
+	"schema_migrations": true,
+}
+
+// partitions selects the names of the partitions of the public tables, created at runtime by
+// create_monthly_partitions: only their partitioned tables are compared
+const partitions = `
+SELECT c.relname FROM pg_class c
+JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE n.nspname = 'public' AND c.relispartition`
+
+// indexName matches the generated name of an index, which differs between databases
+var indexName = regexp.MustCompile(`^CREATE (UNIQUE )?INDEX \S+ ON `)
+
+// Introspect reads the tables of the public schema of db
+func Introspect(ctx context.Context, db *sql.DB) (Catalog, error) {
+	catalog := make(Catalog)
+	table := func(name string) *Table {
+		if catalog[name] == nil {
+			catalog[name] = &Table{Name: name}
+		}
+		return catalog[name]
+	}
+
+	err := queryRows(ctx, db, `
+SELECT table_name, column_name,
+    -- arrays are reported as ARRAY, name them after their element type instead, e.g. varchar[]
+    CASE WHEN data_type = 'ARRAY' THEN substr(udt_name, 2) || '[]' ELSE data_type END,
+    is_nullable = 'YES',
+    coalesce(column_default, '')
+FROM information_schema.columns
+WHERE table_schema = 'public' AND table_name NOT IN (`+partitions+`)
+ORDER BY table_name, ordinal_position`, func(rows *sql.Rows) error {
+		var tableName string
+		var column Column
+		if err := rows.Scan(&tableName, &column.Name, &column.Type, &column.Nullable, &column.Default); err != nil {
+			return err
+		}
+		if !ignoredTables[tableName] {
+			table(tableName).Columns = append(table(tableName).Columns, column)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot read columns: %w", err)
+	}
+
+	err = queryRows(ctx, db, `
+SELECT cl.relname, a.attname, rcl.relname, ra.attname
+FROM pg_constraint c
+JOIN pg_class cl ON cl.oid = c.conrelid
+JOIN pg_namespace n ON n.oid = cl.relnamespace
+JOIN pg_class rcl ON rcl.oid = c.confrelid
+JOIN pg_attribute a ON a.attrelid = c.conrelid AND a.attnum = c.conkey[1]
+JOIN pg_attribute ra ON ra.attrelid = c.confrelid AND ra.attnum = c.confkey[1]
+WHERE c.contype = 'f' AND n.nspname = 'public' AND NOT cl.relispartition`, func(rows *sql.Rows) error {
+		var tableName, column, refTable, refColumn string
+		if err := rows.Scan(&tableName, &column, &refTable, &refColumn); err != nil {
+			return err
+		}
+		if !ignoredTables[tableName] {
+			table(tableName).ForeignKeys = append(table(tableName).ForeignKeys,
+				fmt.Sprintf("%s REFERENCES %s (%s)", column, refTable, refColumn))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot read foreign keys: %w", err)
+	}
+
+	err = queryRows(ctx, db, `
+SELECT tablename, indexdef
+FROM pg_indexes
+WHERE schemaname = 'public' AND tablename NOT IN (`+partitions+`)`, func(rows *sql.Rows) error {
+		var tableName, definition string
+		if err := rows.Scan(&tableName, &definition); err != nil {
+			return err
+		}
+		if !ignoredTables[tableName] {
+			table(tableName).Indexes = append(table(tableName).Indexes,
+				indexName.ReplaceAllString(definition, "${1}INDEX ON "))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot read indexes: %w", err)
+	}
+
+	for _, table := range catalog {
+		sort.Strings(table.ForeignKeys)
+		sort.Strings(table.Indexes)
+	}
+	return catalog, nil
+}
+
+func queryRows(ctx context.Context, db *sql.DB, query string, scan func(rows *sql.Rows) error) error {
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// Diff lists what differs between two catalogs, naming each side for the report
+func Diff(wantName string, want Catalog, gotName string, got Catalog) []string {
+	var drift []string
+	for _, name := range tableNames(want, got) {
+		wantTable, gotTable := want[name], got[name]
+		switch {
+		case gotTable == nil:
+			drift = append(drift, fmt.Sprintf("table %s is missing from %s", name, gotName))
+			continue
+		case wantTable == nil:
+			drift = append(drift, fmt.Sprintf("table %s is missing from %s", name, wantName))
+			continue
+		}
+
+		drift = append(drift, diffSets(name, "column", wantName, columnStrings(wantTable), gotName, columnStrings(gotTable))...)
+		drift = append(drift, diffSets(name, "foreign key", wantName, wantTable.ForeignKeys, gotName, gotTable.ForeignKeys)...)
+		drift = append(drift, diffSets(name, "index", wantName, wantTable.Indexes, gotName, gotTable.Indexes)...)
+	}
+	return drift
+}
+
+func diffSets(table, kind, wantName string, want []string, gotName string, got []string) []string {
+	var drift []string
+	for _, item := range subtract(want, got) {
+		drift = append(drift, fmt.Sprintf("%s: %s %q is in %s but not in %s", table, kind, item, wantName, gotName))
+	}
+	for _, item := range subtract(got, want) {
+		drift = append(drift, fmt.Sprintf("%s: %s %q is in %s but not in %s", table, kind, item, gotName, wantName))
+	}
+	return drift
+}
+
+// subtract returns the items of a missing from b
+func subtract(a, b []string) []string {
+	inB := make(map[string]bool, len(b))
+	for _, item := range b {
+		inB[item] = true
+	}
+	var missing []string
+	for _, item := range a {
+		if !inB[item] {
+			missing = append(missing, item)
+		}
+	}
+	return missing
+}
+
+func columnStrings(table *Table) []string {
+	columns := make([]string, len(table.Columns))
+	for i, column := range table.Columns {
+		columns[i] = column.String()
+	}
+	return columns
+}
+
+func tableNames(catalogs ...Catalog) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, catalog := range catalogs {
+		for name := range catalog {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
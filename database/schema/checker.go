@@ -0,0 +1,101 @@
+package schema
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/arpangoswami/backend-golang-dev/database/migration"
+	"github.com/arpangoswami/backend-golang-dev/util"
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+// driverName is the database/sql driver of pgx, which the migrator and the introspection run on
+const driverName = "pgx"
+
+// Checker compares the schema built by the migrations with the declared schema and the models.
+// It needs a role allowed to create databases, the scratch databases are dropped afterwards.
+type Checker struct {
+	source string
+}
+
+// NewChecker creates a Checker connecting to the postgres:// URL
+func NewChecker(source string) *Checker {
+	return &Checker{source: source}
+}
+
+// Check applies the migrations and the declared schema to two scratch databases and
+// returns every difference between them and with the sqlc models, none when in sync
+func (checker *Checker) Check(ctx context.Context, declaredSchema string) ([]string, error) {
+	migrated, err := checker.scratchCatalog(ctx, func(conn *sql.DB) error {
+		migrator, err := migration.New(conn)
+		if err != nil {
+			return err
+		}
+		return migrator.Up(ctx)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot apply migrations: %w", err)
+	}
+
+	declared, err := checker.scratchCatalog(ctx, func(conn *sql.DB) error {
+		_, err := conn.ExecContext(ctx, declaredSchema)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot apply declared schema: %w", err)
+	}
+
+	drift := Diff("migrations", migrated, "declared schema", declared)
+	return append(drift, CheckModels(migrated, Models)...), nil
+}
+
+// scratchCatalog creates an empty database, runs apply on it and introspects the result
+func (checker *Checker) scratchCatalog(ctx context.Context, apply func(conn *sql.DB) error) (catalog Catalog, err error) {
+	admin, err := sql.Open(driverName, checker.source)
+	if err != nil {
+		return nil, err
+	}
+	defer admin.Close()
+
+	name := "drift_" + strings.ToLower(util.RandomString(12))
+	if _, err := admin.ExecContext(ctx, "CREATE DATABASE "+name); err != nil {
+		return nil, fmt.Errorf("cannot create scratch database: %w", err)
+	}
+	defer func() {
+		// WITH (FORCE) drops the database even if a connection of the pool is still open
+		if _, dropErr := admin.ExecContext(context.Background(), "DROP DATABASE IF EXISTS "+name+" WITH (FORCE)"); dropErr != nil && err == nil {
+			err = fmt.Errorf("cannot drop scratch database %s: %w", name, dropErr)
+		}
+	}()
+
+	source, err := withDatabase(checker.source, name)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := sql.Open(driverName, source)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := apply(conn); err != nil {
+		return nil, err
+	}
+	return Introspect(ctx, conn)
+}
+
+// withDatabase points a postgres:// URL to another database
+func withDatabase(source, database string) (string, error) {
+	u, err := url.Parse(source)
+	if err != nil {
+		return "", fmt.Errorf("invalid database source: %w", err)
+	}
+	if u.Scheme != "postgres" && u.Scheme != "postgresql" {
+		return "", fmt.Errorf("database source must be a postgres:// URL, got scheme %q", u.Scheme)
+	}
+	u.Path = "/" + database
+	return u.String(), nil
+}
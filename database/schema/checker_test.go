@@ -0,0 +1,23 @@
+package schema
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/arpangoswami/backend-golang-dev/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestChecker_Check fails whenever a migration, simple_bank.sql or the sqlc models disagree
+func TestChecker_Check(t *testing.T) {
+	cfg, err := config.Load("../../" + config.DefaultFile)
+	assert.NoError(t, err)
+
+	declaredSchema, err := os.ReadFile("../simple_bank.sql")
+	assert.NoError(t, err)
+
+	drift, err := NewChecker(cfg.DBSource).Check(context.Background(), string(declaredSchema))
+	assert.NoError(t, err)
+	assert.Empty(t, drift, "schema drift between migrations, simple_bank.sql and the sqlc models")
+}
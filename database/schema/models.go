@@ -0,0 +1,106 @@
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+)
+
+// Models maps every table to the model sqlc generates for it
+var Models = map[string]any{
+	"accounts":              db.Account{},
+	"entries":               db.Entry{},
+	"transfers":             db.Transfer{},
+	"users":                 db.User{},
+	"sessions":              db.Session{},
+	"audit_log":             db.AuditLog{},
+	"outbox_events":         db.OutboxEvent{},
+	"webhook_subscriptions": db.WebhookSubscription{},
+	"webhook_deliveries":    db.WebhookDelivery{},
+	"jobs":                  db.Job{},
+	"imports":               db.Import{},
+	"import_errors":         db.ImportError{},
+	"imported_accounts":     db.ImportedAccount{},
+	"entries_archive":       db.EntriesArchive{},
+	"transfers_archive":     db.TransfersArchive{},
+	"balance_checkpoints":   db.BalanceCheckpoint{},
+}
+
+// goTypes is the Go type sqlc uses for a Postgres type, with the overrides of sqlc.yaml, as NOT NULL and as nullable
+var goTypes = map[string][2]string{
+	"bigint":                      {"int64", "sql.NullInt64"},
+	"integer":                     {"int32", "sql.NullInt32"},
+	"smallint":                    {"int16", "sql.NullInt16"},
+	"double precision":            {"float64", "sql.NullFloat64"},
+	"real":                        {"float32", "pgtype.Float4"},
+	"numeric":                     {"pgtype.Numeric", "pgtype.Numeric"},
+	"character varying":           {"string", "sql.NullString"},
+	"text":                        {"string", "sql.NullString"},
+	"boolean":                     {"bool", "sql.NullBool"},
+	"timestamp without time zone": {"time.Time", "sql.NullTime"},
+	"timestamp with time zone":    {"time.Time", "sql.NullTime"},
+	"date":                        {"time.Time", "sql.NullTime"},
+	"uuid":                        {"uuid.UUID", "uuid.NullUUID"},
+	"jsonb":                       {"json.RawMessage", "json.RawMessage"},
+	"varchar[]":                   {"[]string", "[]string"},
+	"text[]":                      {"[]string", "[]string"},
+}
+
+// CheckModels lists the tables and columns of catalog that the models don't match,
+// which means the sqlc code was not regenerated after a migration
+func CheckModels(catalog Catalog, models map[string]any) []string {
+	var drift []string
+	for _, name := range tableNames(catalog) {
+		model, ok := models[name]
+		if !ok {
+			drift = append(drift, fmt.Sprintf("table %s has no model", name))
+			continue
+		}
+		drift = append(drift, checkModel(catalog[name], reflect.TypeOf(model))...)
+	}
+	for name := range models {
+		if catalog[name] == nil {
+			drift = append(drift, fmt.Sprintf("model of table %s has no table", name))
+		}
+	}
+	return drift
+}
+
+func checkModel(table *Table, model reflect.Type) []string {
+	fields := make(map[string]reflect.StructField, model.NumField())
+	for i := 0; i < model.NumField(); i++ {
+		field := model.Field(i)
+		column, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		fields[column] = field
+	}
+
+	var drift []string
+	for _, column := range table.Columns {
+		field, ok := fields[column.Name]
+		if !ok {
+			drift = append(drift, fmt.Sprintf("%s: column %s has no field in %s", table.Name, column.Name, model.Name()))
+			continue
+		}
+		delete(fields, column.Name)
+
+		types, ok := goTypes[column.Type]
+		if !ok {
+			drift = append(drift, fmt.Sprintf("%s: column %s has unsupported type %s", table.Name, column.Name, column.Type))
+			continue
+		}
+		want := types[0]
+		if column.Nullable {
+			want = types[1]
+		}
+		if got := field.Type.String(); got != want {
+			drift = append(drift, fmt.Sprintf("%s: column %q is %s.%s of type %s, want %s",
+				table.Name, column, model.Name(), field.Name, got, want))
+		}
+	}
+	for column, field := range fields {
+		drift = append(drift, fmt.Sprintf("%s: field %s.%s has no column %s", table.Name, model.Name(), field.Name, column))
+	}
+	return drift
+}
@@ -0,0 +1,57 @@
+package schema
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testAccount struct {
+	ID          int64         `json:"id"`
+	Balance     float64       `json:"balance"`
+	CountryCode sql.NullInt32 `json:"country_code"`
+	CreatedAt   time.Time     `json:"created_at"`
+	Tags        []string      `json:"tags"`
+}
+
+func TestCheckModels(t *testing.T) {
+	catalog := Catalog{"accounts": &Table{
+		Name: "accounts",
+		Columns: []Column{
+			{Name: "id", Type: "bigint"},
+			{Name: "balance", Type: "double precision"},
+			{Name: "country_code", Type: "integer", Nullable: true},
+			{Name: "created_at", Type: "timestamp without time zone"},
+			{Name: "tags", Type: "varchar[]"},
+		},
+	}}
+	assert.Empty(t, CheckModels(catalog, map[string]any{"accounts": testAccount{}}))
+}
+
+func TestCheckModels_Drift(t *testing.T) {
+	catalog := Catalog{
+		"accounts": &Table{
+			Name: "accounts",
+			Columns: []Column{
+				{Name: "id", Type: "bigint"},
+				{Name: "balance", Type: "bigint"},
+				{Name: "country_code", Type: "integer"},
+				{Name: "is_frozen", Type: "boolean"},
+			},
+		},
+		"users": &Table{Name: "users"},
+	}
+
+	drift := CheckModels(catalog, map[string]any{"accounts": testAccount{}, "sessions": struct{}{}})
+	assert.ElementsMatch(t, []string{
+		`accounts: column "balance bigint NOT NULL" is testAccount.Balance of type float64, want int64`,
+		`accounts: column "country_code integer NOT NULL" is testAccount.CountryCode of type sql.NullInt32, want int32`,
+		`accounts: column is_frozen has no field in testAccount`,
+		`accounts: field testAccount.CreatedAt has no column created_at`,
+		`accounts: field testAccount.Tags has no column tags`,
+		`table users has no model`,
+		`model of table sessions has no table`,
+	}, drift)
+}
@@ -1,27 +1,162 @@
+CREATE TABLE "users" (
+  "username" varchar PRIMARY KEY,
+  "hashed_password" varchar NOT NULL,
+  "full_name" varchar NOT NULL,
+  "email" varchar UNIQUE NOT NULL,
+  "password_changed_at" timestamptz NOT NULL DEFAULT '0001-01-01 00:00:00Z',
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "sessions" (
+  "id" uuid PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "refresh_token" varchar NOT NULL,
+  "user_agent" varchar NOT NULL,
+  "client_ip" varchar NOT NULL,
+  "is_revoked" boolean NOT NULL DEFAULT false,
+  "expires_at" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
 CREATE TABLE "accounts" (
   "id" bigserial PRIMARY KEY,
   "owner" varchar NOT NULL,
   "balance" float NOT NULL,
   "currency" varchar NOT NULL,
-  "created_at" timestamp NOT NULL DEFAULT 'now()',
-  "country_code" int
+  "created_at" timestamp NOT NULL DEFAULT (now()),
+  "country_code" int,
+  "is_frozen" boolean NOT NULL DEFAULT false
 );
 
 CREATE TABLE "entries" (
-  "id" bigserial PRIMARY KEY,
+  "id" bigserial,
   "account_id" bigint NOT NULL,
-  "amount" bigint NOT NULL,
-  "created_at" timestamp NOT NULL DEFAULT 'now()'
-);
+  "amount" float NOT NULL,
+  "created_at" timestamp NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("id", "created_at")
+) PARTITION BY RANGE ("created_at");
 
 CREATE TABLE "transfers" (
+  "id" bigserial,
+  "from_account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" float NOT NULL,
+  "created_at" timestamp NOT NULL DEFAULT (now()),
+  "reverses_transfer_id" bigint,
+  PRIMARY KEY ("id", "created_at")
+) PARTITION BY RANGE ("created_at");
+
+CREATE TABLE "audit_log" (
+  "id" bigserial PRIMARY KEY,
+  "actor" varchar NOT NULL,
+  "action" varchar NOT NULL,
+  "entity_type" varchar NOT NULL,
+  "entity_id" bigint NOT NULL,
+  "before" jsonb NOT NULL DEFAULT 'null',
+  "after" jsonb NOT NULL DEFAULT 'null',
+  "request_id" varchar NOT NULL DEFAULT '',
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "outbox_events" (
   "id" bigserial PRIMARY KEY,
+  "aggregate_type" varchar NOT NULL,
+  "aggregate_id" bigint NOT NULL,
+  "event_type" varchar NOT NULL,
+  "payload" jsonb NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "published_at" timestamptz
+);
+
+CREATE TABLE "webhook_subscriptions" (
+  "id" bigserial PRIMARY KEY,
+  "owner" varchar NOT NULL,
+  "url" varchar NOT NULL,
+  "event_types" varchar[] NOT NULL,
+  "secret" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "webhook_deliveries" (
+  "id" bigserial PRIMARY KEY,
+  "subscription_id" bigint NOT NULL,
+  "event_id" bigint NOT NULL,
+  "event_type" varchar NOT NULL,
+  "payload" jsonb NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "attempts" int NOT NULL DEFAULT 0,
+  "next_attempt_at" timestamptz NOT NULL DEFAULT (now()),
+  "last_response_code" int NOT NULL DEFAULT 0,
+  "last_error" varchar NOT NULL DEFAULT '',
+  "delivered_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "jobs" (
+  "id" bigserial PRIMARY KEY,
+  "kind" varchar NOT NULL,
+  "payload" jsonb NOT NULL,
+  "priority" int NOT NULL DEFAULT 0,
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "unique_key" varchar,
+  "attempts" int NOT NULL DEFAULT 0,
+  "max_attempts" int NOT NULL,
+  "run_at" timestamptz NOT NULL DEFAULT (now()),
+  "locked_until" timestamptz,
+  "last_error" varchar NOT NULL DEFAULT '',
+  "finished_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "imports" (
+  "id" bigserial PRIMARY KEY,
+  "name" varchar UNIQUE NOT NULL,
+  "kind" varchar NOT NULL,
+  "rows_processed" bigint NOT NULL DEFAULT 0,
+  "rows_imported" bigint NOT NULL DEFAULT 0,
+  "rows_failed" bigint NOT NULL DEFAULT 0,
+  "finished_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "import_errors" (
+  "id" bigserial PRIMARY KEY,
+  "import_id" bigint NOT NULL,
+  "line" bigint NOT NULL,
+  "message" varchar NOT NULL
+);
+
+CREATE TABLE "imported_accounts" (
+  "external_id" varchar PRIMARY KEY,
+  "account_id" bigint UNIQUE NOT NULL,
+  "import_id" bigint NOT NULL
+);
+
+CREATE TABLE "entries_archive" (
+  "id" bigint PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "amount" float NOT NULL,
+  "created_at" timestamp NOT NULL
+);
+
+CREATE TABLE "transfers_archive" (
+  "id" bigint PRIMARY KEY,
   "from_account_id" bigint NOT NULL,
   "to_account_id" bigint NOT NULL,
-  "amount" bigint NOT NULL,
-  "created_at" timestamp NOT NULL DEFAULT 'now()'
+  "amount" float NOT NULL,
+  "created_at" timestamp NOT NULL,
+  "reverses_transfer_id" bigint
 );
 
+CREATE TABLE "balance_checkpoints" (
+  "account_id" bigint PRIMARY KEY,
+  "balance" float NOT NULL,
+  "archived_entries" bigint NOT NULL,
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "sessions" ("username");
+
 CREATE INDEX ON "accounts" ("owner");
 
 CREATE INDEX ON "entries" ("account_id");
@@ -32,12 +167,157 @@ CREATE INDEX ON "transfers" ("to_account_id");
 
 CREATE INDEX ON "transfers" ("from_account_id", "to_account_id");
 
+CREATE INDEX ON "accounts" ("owner", "created_at", "id");
+
+CREATE INDEX ON "entries" ("account_id", "created_at", "id");
+
+CREATE INDEX ON "transfers" ("from_account_id", "created_at", "id");
+
+CREATE INDEX ON "transfers" ("to_account_id", "created_at", "id");
+
+CREATE INDEX ON "entries" ("account_id", abs("amount"));
+
+CREATE INDEX ON "transfers" ("from_account_id", "amount");
+
+CREATE INDEX ON "transfers" ("to_account_id", "amount");
+
+CREATE INDEX ON "transfers" ("reverses_transfer_id");
+
+CREATE INDEX ON "audit_log" ("entity_type", "entity_id", "created_at");
+
+CREATE INDEX ON "audit_log" ("created_at");
+
+CREATE INDEX ON "outbox_events" ("id") WHERE "published_at" IS NULL;
+
+CREATE INDEX ON "outbox_events" ("aggregate_type", "aggregate_id", "id");
+
+CREATE INDEX ON "webhook_subscriptions" ("owner");
+
+CREATE UNIQUE INDEX ON "webhook_deliveries" ("subscription_id", "event_id");
+
+CREATE INDEX ON "webhook_deliveries" ("next_attempt_at") WHERE "status" = 'pending';
+
+CREATE INDEX ON "jobs" ("priority" DESC, "run_at", "id") WHERE "status" = 'pending';
+
+CREATE INDEX ON "jobs" ("locked_until") WHERE "status" = 'running';
+
+CREATE UNIQUE INDEX ON "jobs" ("kind", "unique_key") WHERE "status" IN ('pending', 'running');
+
+CREATE INDEX ON "import_errors" ("import_id", "line");
+
+CREATE INDEX ON "imported_accounts" ("import_id");
+
+CREATE INDEX ON "entries_archive" ("account_id", "created_at", "id");
+
+CREATE INDEX ON "entries_archive" ("created_at");
+
+CREATE INDEX ON "transfers_archive" ("from_account_id", "created_at", "id");
+
+CREATE INDEX ON "transfers_archive" ("to_account_id", "created_at", "id");
+
+CREATE INDEX ON "transfers_archive" ("created_at");
+
+COMMENT ON TABLE "audit_log" IS 'Append-only, rows cannot be updated or deleted';
+
 COMMENT ON COLUMN "entries"."amount" IS 'Can be both negative and positive';
 
 COMMENT ON COLUMN "transfers"."amount" IS 'Must be positive';
 
+COMMENT ON COLUMN "transfers"."reverses_transfer_id" IS 'Set on the transfer moving the money of a reversed transfer back';
+
+COMMENT ON COLUMN "outbox_events"."published_at" IS 'Set by the relay once the event was handed to the publisher';
+
+COMMENT ON COLUMN "webhook_deliveries"."event_id" IS 'The outbox event fanned out to the subscription';
+
+COMMENT ON COLUMN "webhook_deliveries"."status" IS 'pending, succeeded or dead once the attempts are exhausted';
+
+COMMENT ON COLUMN "jobs"."status" IS 'pending, running, succeeded or failed once the attempts are exhausted';
+
+COMMENT ON COLUMN "jobs"."unique_key" IS 'At most one pending or running job of a kind has the same key';
+
+COMMENT ON COLUMN "jobs"."locked_until" IS 'A running job is claimed again once its worker missed this deadline';
+
+COMMENT ON COLUMN "imports"."kind" IS 'accounts or transfers, the kind of CSV file imported';
+
+COMMENT ON COLUMN "imports"."rows_processed" IS 'CSV records committed, valid or not; an interrupted import resumes after them';
+
+COMMENT ON COLUMN "imported_accounts"."external_id" IS 'The account id in the system the account was imported from';
+
+COMMENT ON TABLE "entries_archive" IS 'Entries moved out of entries once older than the retention, see balance_checkpoints';
+
+COMMENT ON TABLE "transfers_archive" IS 'Transfers moved out of transfers once older than the retention';
+
+COMMENT ON COLUMN "balance_checkpoints"."balance" IS 'Sum of the archived entries: the balance is this plus the sum of the entries left';
+
 ALTER TABLE "entries" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
 
 ALTER TABLE "transfers" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
 
 ALTER TABLE "transfers" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "sessions" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "webhook_subscriptions" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+
+ALTER TABLE "webhook_deliveries" ADD FOREIGN KEY ("subscription_id") REFERENCES "webhook_subscriptions" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "import_errors" ADD FOREIGN KEY ("import_id") REFERENCES "imports" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "imported_accounts" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "imported_accounts" ADD FOREIGN KEY ("import_id") REFERENCES "imports" ("id");
+
+ALTER TABLE "entries_archive" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "transfers_archive" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "transfers_archive" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "balance_checkpoints" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+CREATE FUNCTION "audit_log_append_only"() RETURNS trigger AS $$
+BEGIN
+  RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "audit_log_append_only" BEFORE UPDATE OR DELETE OR TRUNCATE ON "audit_log"
+  FOR EACH STATEMENT EXECUTE FUNCTION "audit_log_append_only"();
+
+-- create_monthly_partitions creates the missing monthly partitions of a table partitioned by range
+-- of created_at, from the month of from_time through the month of to_time, and returns how many it
+-- created. Rows outside of the monthly partitions, e.g. imported from long ago, land in the default
+-- partition it creates first; the rows of a new month held by the default partition are moved into it.
+CREATE FUNCTION "create_monthly_partitions"(parent text, from_time timestamp, to_time timestamp) RETURNS integer AS $$
+DECLARE
+  month_start timestamp := date_trunc('month', from_time);
+  month_end timestamp;
+  partition_name text;
+  created integer := 0;
+BEGIN
+  -- concurrent calls would create the same partitions
+  PERFORM pg_advisory_xact_lock(hashtext('create_monthly_partitions'), hashtext(parent));
+  IF to_regclass(parent || '_default') IS NULL THEN
+    EXECUTE format('CREATE TABLE %I PARTITION OF %I DEFAULT', parent || '_default', parent);
+  END IF;
+  WHILE month_start <= to_time LOOP
+    month_end := month_start + interval '1 month';
+    partition_name := parent || '_p' || to_char(month_start, 'YYYYMM');
+    IF to_regclass(partition_name) IS NULL THEN
+      EXECUTE format('CREATE TABLE %I (LIKE %I INCLUDING DEFAULTS)', partition_name, parent);
+      EXECUTE format('LOCK TABLE %I IN EXCLUSIVE MODE', parent || '_default');
+      EXECUTE format('WITH moved AS (DELETE FROM %I WHERE created_at >= %L AND created_at < %L RETURNING *) INSERT INTO %I SELECT * FROM moved',
+        parent || '_default', month_start, month_end, partition_name);
+      EXECUTE format('ALTER TABLE %I ATTACH PARTITION %I FOR VALUES FROM (%L) TO (%L)',
+        parent, partition_name, month_start, month_end);
+      created := created + 1;
+    END IF;
+    month_start := month_end;
+  END LOOP;
+  RETURN created;
+END;
+$$ LANGUAGE plpgsql;
+
+SELECT create_monthly_partitions('entries', localtimestamp, localtimestamp + interval '3 months');
+
+SELECT create_monthly_partitions('transfers', localtimestamp, localtimestamp + interval '3 months');
@@ -8,8 +8,88 @@ package db
 import (
 	"context"
 	"database/sql"
+	"time"
 )
 
+const addAccountBalance = `-- name: AddAccountBalance :one
+UPDATE accounts
+SET balance = balance + $1
+WHERE id = $2
+RETURNING id, owner, balance, currency, created_at, country_code, is_frozen
+`
+
+type AddAccountBalanceParams struct {
+	Amount float64 `json:"amount"`
+	ID     int64   `json:"id"`
+}
+
+func (q *Queries) AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error) {
+	row := q.db.QueryRow(ctx, addAccountBalance, arg.Amount, arg.ID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.CountryCode,
+		&i.IsFrozen,
+	)
+	return i, err
+}
+
+const addAccountBalances = `-- name: AddAccountBalances :many
+UPDATE accounts
+SET balance = accounts.balance + moves.amount
+FROM (
+    SELECT unnest($1::bigint[]) AS id, unnest($2::float[]) AS amount
+) AS moves
+WHERE accounts.id = moves.id
+RETURNING accounts.id, accounts.owner, accounts.balance, accounts.currency, accounts.created_at, accounts.country_code, accounts.is_frozen
+`
+
+type AddAccountBalancesParams struct {
+	Ids     []int64   `json:"ids"`
+	Amounts []float64 `json:"amounts"`
+}
+
+func (q *Queries) AddAccountBalances(ctx context.Context, arg AddAccountBalancesParams) ([]Account, error) {
+	rows, err := q.db.Query(ctx, addAccountBalances, arg.Ids, arg.Amounts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Account{}
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Balance,
+			&i.Currency,
+			&i.CreatedAt,
+			&i.CountryCode,
+			&i.IsFrozen,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+type CopyAccountsParams struct {
+	ID          int64         `json:"id"`
+	Owner       string        `json:"owner"`
+	Balance     float64       `json:"balance"`
+	Currency    string        `json:"currency"`
+	CountryCode sql.NullInt32 `json:"country_code"`
+	CreatedAt   time.Time     `json:"created_at"`
+}
+
 const createAccount = `-- name: CreateAccount :one
 INSERT INTO accounts(
     owner,
@@ -18,7 +98,7 @@ INSERT INTO accounts(
     country_code
 ) VALUES (
     $1, $2, $3, $4
-) RETURNING id, owner, balance, currency, created_at, country_code
+) RETURNING id, owner, balance, currency, created_at, country_code, is_frozen
 `
 
 type CreateAccountParams struct {
@@ -29,7 +109,7 @@ type CreateAccountParams struct {
 }
 
 func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error) {
-	row := q.db.QueryRowContext(ctx, createAccount,
+	row := q.db.QueryRow(ctx, createAccount,
 		arg.Owner,
 		arg.Balance,
 		arg.Currency,
@@ -43,6 +123,7 @@ func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (A
 		&i.Currency,
 		&i.CreatedAt,
 		&i.CountryCode,
+		&i.IsFrozen,
 	)
 	return i, err
 }
@@ -52,17 +133,57 @@ DELETE FROM accounts WHERE id = $1
 `
 
 func (q *Queries) DeleteAccount(ctx context.Context, id int64) error {
-	_, err := q.db.ExecContext(ctx, deleteAccount, id)
+	_, err := q.db.Exec(ctx, deleteAccount, id)
 	return err
 }
 
+const exportAccounts = `-- name: ExportAccounts :many
+SELECT id, owner, balance, currency, created_at, country_code, is_frozen FROM accounts
+WHERE id > $1
+ORDER BY id
+LIMIT $2
+`
+
+type ExportAccountsParams struct {
+	AfterID    int64 `json:"after_id"`
+	LimitCount int32 `json:"limit_count"`
+}
+
+func (q *Queries) ExportAccounts(ctx context.Context, arg ExportAccountsParams) ([]Account, error) {
+	rows, err := q.db.Query(ctx, exportAccounts, arg.AfterID, arg.LimitCount)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Account{}
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Balance,
+			&i.Currency,
+			&i.CreatedAt,
+			&i.CountryCode,
+			&i.IsFrozen,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getAccount = `-- name: GetAccount :one
-SELECT id, owner, balance, currency, created_at, country_code FROM accounts
+SELECT id, owner, balance, currency, created_at, country_code, is_frozen FROM accounts
 WHERE id = $1 LIMIT 1
 `
 
 func (q *Queries) GetAccount(ctx context.Context, id int64) (Account, error) {
-	row := q.db.QueryRowContext(ctx, getAccount, id)
+	row := q.db.QueryRow(ctx, getAccount, id)
 	var i Account
 	err := row.Scan(
 		&i.ID,
@@ -71,12 +192,34 @@ func (q *Queries) GetAccount(ctx context.Context, id int64) (Account, error) {
 		&i.Currency,
 		&i.CreatedAt,
 		&i.CountryCode,
+		&i.IsFrozen,
+	)
+	return i, err
+}
+
+const getAccountForUpdate = `-- name: GetAccountForUpdate :one
+SELECT id, owner, balance, currency, created_at, country_code, is_frozen FROM accounts
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetAccountForUpdate(ctx context.Context, id int64) (Account, error) {
+	row := q.db.QueryRow(ctx, getAccountForUpdate, id)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.CountryCode,
+		&i.IsFrozen,
 	)
 	return i, err
 }
 
 const listAccounts = `-- name: ListAccounts :many
-SELECT id, owner, balance, currency, created_at, country_code FROM accounts
+SELECT id, owner, balance, currency, created_at, country_code, is_frozen FROM accounts
 ORDER BY id
 LIMIT $1
 OFFSET $2
@@ -88,7 +231,7 @@ type ListAccountsParams struct {
 }
 
 func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error) {
-	rows, err := q.db.QueryContext(ctx, listAccounts, arg.Limit, arg.Offset)
+	rows, err := q.db.Query(ctx, listAccounts, arg.Limit, arg.Offset)
 	if err != nil {
 		return nil, err
 	}
@@ -103,25 +246,272 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 			&i.Currency,
 			&i.CreatedAt,
 			&i.CountryCode,
+			&i.IsFrozen,
 		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
 	}
-	if err := rows.Close(); err != nil {
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAccountsByOwner = `-- name: ListAccountsByOwner :many
+SELECT id, owner, balance, currency, created_at, country_code, is_frozen FROM accounts
+WHERE owner = $1
+ORDER BY id
+LIMIT $2
+OFFSET $3
+`
+
+type ListAccountsByOwnerParams struct {
+	Owner  string `json:"owner"`
+	Limit  int32  `json:"limit"`
+	Offset int32  `json:"offset"`
+}
+
+func (q *Queries) ListAccountsByOwner(ctx context.Context, arg ListAccountsByOwnerParams) ([]Account, error) {
+	rows, err := q.db.Query(ctx, listAccountsByOwner, arg.Owner, arg.Limit, arg.Offset)
+	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
+	items := []Account{}
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Balance,
+			&i.Currency,
+			&i.CreatedAt,
+			&i.CountryCode,
+			&i.IsFrozen,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
 	return items, nil
 }
 
+const listAccountsByOwnerAfter = `-- name: ListAccountsByOwnerAfter :many
+SELECT id, owner, balance, currency, created_at, country_code, is_frozen FROM accounts
+WHERE owner = $1
+    AND (created_at, id) > ($2::timestamp, $3::bigint)
+ORDER BY created_at, id
+LIMIT $4
+`
+
+type ListAccountsByOwnerAfterParams struct {
+	Owner          string    `json:"owner"`
+	AfterCreatedAt time.Time `json:"after_created_at"`
+	AfterID        int64     `json:"after_id"`
+	LimitCount     int32     `json:"limit_count"`
+}
+
+func (q *Queries) ListAccountsByOwnerAfter(ctx context.Context, arg ListAccountsByOwnerAfterParams) ([]Account, error) {
+	rows, err := q.db.Query(ctx, listAccountsByOwnerAfter,
+		arg.Owner,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.LimitCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Account{}
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Balance,
+			&i.Currency,
+			&i.CreatedAt,
+			&i.CountryCode,
+			&i.IsFrozen,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAccountsByOwnerBefore = `-- name: ListAccountsByOwnerBefore :many
+SELECT id, owner, balance, currency, created_at, country_code, is_frozen FROM accounts
+WHERE owner = $1
+    AND (created_at, id) < ($2::timestamp, $3::bigint)
+ORDER BY created_at DESC, id DESC
+LIMIT $4
+`
+
+type ListAccountsByOwnerBeforeParams struct {
+	Owner           string    `json:"owner"`
+	BeforeCreatedAt time.Time `json:"before_created_at"`
+	BeforeID        int64     `json:"before_id"`
+	LimitCount      int32     `json:"limit_count"`
+}
+
+func (q *Queries) ListAccountsByOwnerBefore(ctx context.Context, arg ListAccountsByOwnerBeforeParams) ([]Account, error) {
+	rows, err := q.db.Query(ctx, listAccountsByOwnerBefore,
+		arg.Owner,
+		arg.BeforeCreatedAt,
+		arg.BeforeID,
+		arg.LimitCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Account{}
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Balance,
+			&i.Currency,
+			&i.CreatedAt,
+			&i.CountryCode,
+			&i.IsFrozen,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBalanceMismatches = `-- name: ListBalanceMismatches :many
+SELECT
+    accounts.id,
+    accounts.owner,
+    accounts.currency,
+    accounts.balance,
+    (COALESCE(balance_checkpoints.balance, 0) + COALESCE(SUM(entries.amount), 0))::float AS entries_total
+FROM accounts
+LEFT JOIN balance_checkpoints ON balance_checkpoints.account_id = accounts.id
+LEFT JOIN entries ON entries.account_id = accounts.id
+GROUP BY accounts.id, balance_checkpoints.balance
+HAVING ABS(accounts.balance - COALESCE(balance_checkpoints.balance, 0) - COALESCE(SUM(entries.amount), 0)) > $1::float
+ORDER BY accounts.id
+`
+
+type ListBalanceMismatchesRow struct {
+	ID           int64   `json:"id"`
+	Owner        string  `json:"owner"`
+	Currency     string  `json:"currency"`
+	Balance      float64 `json:"balance"`
+	EntriesTotal float64 `json:"entries_total"`
+}
+
+func (q *Queries) ListBalanceMismatches(ctx context.Context, tolerance float64) ([]ListBalanceMismatchesRow, error) {
+	rows, err := q.db.Query(ctx, listBalanceMismatches, tolerance)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListBalanceMismatchesRow{}
+	for rows.Next() {
+		var i ListBalanceMismatchesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Currency,
+			&i.Balance,
+			&i.EntriesTotal,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const nextAccountIDs = `-- name: NextAccountIDs :many
+SELECT nextval(pg_get_serial_sequence('accounts', 'id'))::bigint AS id
+FROM generate_series(1, $1::int)
+`
+
+func (q *Queries) NextAccountIDs(ctx context.Context, count int32) ([]int64, error) {
+	rows, err := q.db.Query(ctx, nextAccountIDs, count)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+type RestoreAccountsParams struct {
+	ID          int64         `json:"id"`
+	Owner       string        `json:"owner"`
+	Balance     float64       `json:"balance"`
+	Currency    string        `json:"currency"`
+	CreatedAt   time.Time     `json:"created_at"`
+	CountryCode sql.NullInt32 `json:"country_code"`
+	IsFrozen    bool          `json:"is_frozen"`
+}
+
+const setAccountFrozen = `-- name: SetAccountFrozen :one
+UPDATE accounts
+SET is_frozen = $2
+WHERE id = $1
+RETURNING id, owner, balance, currency, created_at, country_code, is_frozen
+`
+
+type SetAccountFrozenParams struct {
+	ID       int64 `json:"id"`
+	IsFrozen bool  `json:"is_frozen"`
+}
+
+func (q *Queries) SetAccountFrozen(ctx context.Context, arg SetAccountFrozenParams) (Account, error) {
+	row := q.db.QueryRow(ctx, setAccountFrozen, arg.ID, arg.IsFrozen)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.CountryCode,
+		&i.IsFrozen,
+	)
+	return i, err
+}
+
 const updateAccount = `-- name: UpdateAccount :one
 UPDATE accounts
 SET balance = $2
 WHERE id = $1
-RETURNING id, owner, balance, currency, created_at, country_code
+RETURNING id, owner, balance, currency, created_at, country_code, is_frozen
 `
 
 type UpdateAccountParams struct {
@@ -130,7 +520,7 @@ type UpdateAccountParams struct {
 }
 
 func (q *Queries) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error) {
-	row := q.db.QueryRowContext(ctx, updateAccount, arg.ID, arg.Balance)
+	row := q.db.QueryRow(ctx, updateAccount, arg.ID, arg.Balance)
 	var i Account
 	err := row.Scan(
 		&i.ID,
@@ -139,6 +529,7 @@ func (q *Queries) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (A
 		&i.Currency,
 		&i.CreatedAt,
 		&i.CountryCode,
+		&i.IsFrozen,
 	)
 	return i, err
 }
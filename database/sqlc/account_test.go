@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"github.com/arpangoswami/backend-golang-dev/util"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"testing"
 	"time"
 )
@@ -97,6 +98,79 @@ func TestQueries_ListAccounts(t *testing.T) {
 	cleanUpAccounts(t, cleanupList)
 }
 
+func TestQueries_ListAccountsByOwner(t *testing.T) {
+	var cleanupList []int64
+	var lastAccount Account
+	for i := 0; i < 10; i++ {
+		lastAccount = createRandomAccount(t)
+		cleanupList = append(cleanupList, lastAccount.ID)
+	}
+	arg := ListAccountsByOwnerParams{
+		Owner:  lastAccount.Owner,
+		Limit:  5,
+		Offset: 0,
+	}
+	accounts, err := testQueries.ListAccountsByOwner(context.Background(), arg)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, accounts)
+
+	for _, account := range accounts {
+		assert.Equal(t, lastAccount.Owner, account.Owner)
+	}
+
+	cleanUpAccounts(t, cleanupList)
+}
+
+func TestQueries_ListAccountsByOwnerAfterBefore(t *testing.T) {
+	owner := createRandomUser(t).Username
+	var cleanupList []int64
+	for i := 0; i < 4; i++ {
+		currencyCountryCode := util.RandomCurrencyCodeCountryCode()
+		account, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+			Owner:       owner,
+			Balance:     util.RandomMoney(1000),
+			Currency:    currencyCountryCode.CurrencyCode,
+			CountryCode: currencyCountryCode.CountryCode,
+		})
+		require.NoError(t, err)
+		cleanupList = append(cleanupList, account.ID)
+	}
+	// the accounts are cleaned up even when a require below stops the test
+	t.Cleanup(func() { cleanUpAccounts(t, cleanupList) })
+
+	first, err := testQueries.ListAccountsByOwnerAfter(context.Background(), ListAccountsByOwnerAfterParams{
+		Owner:      owner,
+		LimitCount: 2,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, first)
+	assert.Len(t, first, 2)
+
+	last := first[len(first)-1]
+	second, err := testQueries.ListAccountsByOwnerAfter(context.Background(), ListAccountsByOwnerAfterParams{
+		Owner:          owner,
+		AfterCreatedAt: last.CreatedAt,
+		AfterID:        last.ID,
+		LimitCount:     5,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, second)
+	assert.Len(t, second, 2)
+
+	// walking back from the second page returns the first one, newest first
+	previous, err := testQueries.ListAccountsByOwnerBefore(context.Background(), ListAccountsByOwnerBeforeParams{
+		Owner:           owner,
+		BeforeCreatedAt: second[0].CreatedAt,
+		BeforeID:        second[0].ID,
+		LimitCount:      5,
+	})
+	require.NoError(t, err)
+	require.Len(t, previous, 2)
+	require.Len(t, first, 2)
+	assert.Equal(t, first[1].ID, previous[0].ID)
+	assert.Equal(t, first[0].ID, previous[1].ID)
+}
+
 func cleanUpAccount(t *testing.T, id int64) {
 	t.Helper()
 	err := testQueries.DeleteAccount(context.Background(), id)
@@ -0,0 +1,176 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: archive.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const archiveEntries = `-- name: ArchiveEntries :one
+WITH moved AS (
+    DELETE FROM entries
+    WHERE (id, created_at) IN (
+        SELECT id, created_at FROM entries
+        WHERE created_at < $1::timestamp
+        ORDER BY created_at, id
+        LIMIT $2
+    )
+    RETURNING id, account_id, amount, created_at
+), archived AS (
+    INSERT INTO entries_archive SELECT id, account_id, amount, created_at FROM moved
+), checkpoints AS (
+    INSERT INTO balance_checkpoints (account_id, balance, archived_entries)
+    SELECT account_id, sum(amount), count(*) FROM moved GROUP BY account_id
+    ON CONFLICT (account_id) DO UPDATE SET
+        balance = balance_checkpoints.balance + excluded.balance,
+        archived_entries = balance_checkpoints.archived_entries + excluded.archived_entries,
+        updated_at = now()
+)
+SELECT count(*) FROM moved
+`
+
+type ArchiveEntriesParams struct {
+	Before     time.Time `json:"before"`
+	LimitCount int32     `json:"limit_count"`
+}
+
+func (q *Queries) ArchiveEntries(ctx context.Context, arg ArchiveEntriesParams) (int64, error) {
+	row := q.db.QueryRow(ctx, archiveEntries, arg.Before, arg.LimitCount)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const archiveTransfers = `-- name: ArchiveTransfers :one
+WITH moved AS (
+    DELETE FROM transfers
+    WHERE (id, created_at) IN (
+        SELECT id, created_at FROM transfers
+        WHERE created_at < $1::timestamp
+        ORDER BY created_at, id
+        LIMIT $2
+    )
+    RETURNING id, from_account_id, to_account_id, amount, created_at, reverses_transfer_id
+), archived AS (
+    INSERT INTO transfers_archive SELECT id, from_account_id, to_account_id, amount, created_at, reverses_transfer_id FROM moved
+)
+SELECT count(*) FROM moved
+`
+
+type ArchiveTransfersParams struct {
+	Before     time.Time `json:"before"`
+	LimitCount int32     `json:"limit_count"`
+}
+
+func (q *Queries) ArchiveTransfers(ctx context.Context, arg ArchiveTransfersParams) (int64, error) {
+	row := q.db.QueryRow(ctx, archiveTransfers, arg.Before, arg.LimitCount)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const getArchivedTransfer = `-- name: GetArchivedTransfer :one
+SELECT id, from_account_id, to_account_id, amount, created_at, reverses_transfer_id FROM transfers_archive
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetArchivedTransfer(ctx context.Context, id int64) (TransfersArchive, error) {
+	row := q.db.QueryRow(ctx, getArchivedTransfer, id)
+	var i TransfersArchive
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.ReversesTransferID,
+	)
+	return i, err
+}
+
+const getBalanceCheckpoint = `-- name: GetBalanceCheckpoint :one
+SELECT account_id, balance, archived_entries, updated_at FROM balance_checkpoints
+WHERE account_id = $1 LIMIT 1
+`
+
+func (q *Queries) GetBalanceCheckpoint(ctx context.Context, accountID int64) (BalanceCheckpoint, error) {
+	row := q.db.QueryRow(ctx, getBalanceCheckpoint, accountID)
+	var i BalanceCheckpoint
+	err := row.Scan(
+		&i.AccountID,
+		&i.Balance,
+		&i.ArchivedEntries,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const restoreArchivedEntries = `-- name: RestoreArchivedEntries :one
+WITH moved AS (
+    DELETE FROM entries_archive
+    WHERE id IN (
+        SELECT id FROM entries_archive
+        WHERE created_at >= $1::timestamp
+        ORDER BY created_at, id
+        LIMIT $2
+    )
+    RETURNING id, account_id, amount, created_at
+), restored AS (
+    INSERT INTO entries SELECT id, account_id, amount, created_at FROM moved
+), checkpoints AS (
+    UPDATE balance_checkpoints SET
+        balance = balance_checkpoints.balance - totals.balance,
+        archived_entries = balance_checkpoints.archived_entries - totals.archived_entries,
+        updated_at = now()
+    FROM (
+        SELECT account_id, sum(amount) AS balance, count(*) AS archived_entries
+        FROM moved GROUP BY account_id
+    ) AS totals
+    WHERE balance_checkpoints.account_id = totals.account_id
+)
+SELECT count(*) FROM moved
+`
+
+type RestoreArchivedEntriesParams struct {
+	Since      time.Time `json:"since"`
+	LimitCount int32     `json:"limit_count"`
+}
+
+func (q *Queries) RestoreArchivedEntries(ctx context.Context, arg RestoreArchivedEntriesParams) (int64, error) {
+	row := q.db.QueryRow(ctx, restoreArchivedEntries, arg.Since, arg.LimitCount)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const restoreArchivedTransfers = `-- name: RestoreArchivedTransfers :one
+WITH moved AS (
+    DELETE FROM transfers_archive
+    WHERE id IN (
+        SELECT id FROM transfers_archive
+        WHERE created_at >= $1::timestamp
+        ORDER BY created_at, id
+        LIMIT $2
+    )
+    RETURNING id, from_account_id, to_account_id, amount, created_at, reverses_transfer_id
+), restored AS (
+    INSERT INTO transfers SELECT id, from_account_id, to_account_id, amount, created_at, reverses_transfer_id FROM moved
+)
+SELECT count(*) FROM moved
+`
+
+type RestoreArchivedTransfersParams struct {
+	Since      time.Time `json:"since"`
+	LimitCount int32     `json:"limit_count"`
+}
+
+func (q *Queries) RestoreArchivedTransfers(ctx context.Context, arg RestoreArchivedTransfersParams) (int64, error) {
+	row := q.db.QueryRow(ctx, restoreArchivedTransfers, arg.Since, arg.LimitCount)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
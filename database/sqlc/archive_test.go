@@ -0,0 +1,84 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueries_ArchiveEntries(t *testing.T) {
+	ctx := context.Background()
+	account := createRandomAccount(t)
+	createdAt := time.Date(1999, time.January, 1, 0, 0, 0, 0, time.UTC)
+	_, err := testQueries.CopyEntries(ctx, []CopyEntriesParams{
+		{AccountID: account.ID, Amount: 10, CreatedAt: createdAt},
+		{AccountID: account.ID, Amount: -4, CreatedAt: createdAt},
+	})
+	assert.NoError(t, err)
+
+	listHot := func() []Entry {
+		entries, err := testQueries.ListEntriesAfter(ctx, ListEntriesAfterParams{
+			AccountIds: []int64{account.ID}, LimitCount: 10,
+		})
+		assert.NoError(t, err)
+		return entries
+	}
+	listAll := func() []Entry {
+		entries, err := testQueries.ListEntriesWithArchiveAfter(ctx, ListEntriesWithArchiveAfterParams{
+			AccountIds: []int64{account.ID}, LimitCount: 10,
+		})
+		assert.NoError(t, err)
+		return entries
+	}
+
+	archived, err := testQueries.ArchiveEntries(ctx, ArchiveEntriesParams{Before: createdAt.Add(time.Hour), LimitCount: 1000})
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, archived, int64(2))
+	assert.Empty(t, listHot())
+	assert.Len(t, listAll(), 2)
+
+	// the archived entries are kept summed up per account
+	checkpoint, err := testQueries.GetBalanceCheckpoint(ctx, account.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(6), checkpoint.Balance)
+	assert.Equal(t, int64(2), checkpoint.ArchivedEntries)
+
+	restored, err := testQueries.RestoreArchivedEntries(ctx, RestoreArchivedEntriesParams{Since: createdAt, LimitCount: 1000})
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, restored, int64(2))
+	assert.Len(t, listHot(), 2)
+
+	checkpoint, err = testQueries.GetBalanceCheckpoint(ctx, account.ID)
+	assert.NoError(t, err)
+	assert.Zero(t, checkpoint.Balance)
+	assert.Zero(t, checkpoint.ArchivedEntries)
+}
+
+func TestStore_ReverseArchivedTransfer(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore(testDB)
+	from, to := createRandomAccount(t), createRandomAccount(t)
+	ids, err := testQueries.NextTransferIDs(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, ids, 1)
+	createdAt := time.Date(1999, time.February, 1, 0, 0, 0, 0, time.UTC)
+	_, err = testQueries.CopyTransfers(ctx, []CopyTransfersParams{
+		{ID: ids[0], FromAccountID: from.ID, ToAccountID: to.ID, Amount: 1, CreatedAt: createdAt},
+	})
+	assert.NoError(t, err)
+
+	_, err = testQueries.ArchiveTransfers(ctx, ArchiveTransfersParams{Before: createdAt.Add(time.Hour), LimitCount: 1000})
+	assert.NoError(t, err)
+	_, err = store.ReverseTransferTransaction(ctx, ids[0])
+	assert.ErrorIs(t, err, ErrTransferArchived)
+
+	archived, err := testQueries.GetArchivedTransfer(ctx, ids[0])
+	assert.NoError(t, err)
+	assert.Equal(t, from.ID, archived.FromAccountID)
+
+	_, err = testQueries.RestoreArchivedTransfers(ctx, RestoreArchivedTransfersParams{Since: createdAt, LimitCount: 1000})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,117 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Audited actions, written to audit_log.action
+const (
+	ActionAccountCreate     = "account.create"
+	ActionAccountUpdate     = "account.update"
+	ActionAccountAddBalance = "account.add_balance"
+	ActionAccountFreeze     = "account.freeze"
+	ActionAccountUnfreeze   = "account.unfreeze"
+	ActionAccountDelete     = "account.delete"
+	ActionTransferCreate    = "transfer.create"
+	ActionTransferReverse   = "transfer.reverse"
+	ActionTransferDelete    = "transfer.delete"
+	ActionEntryCreate       = "entry.create"
+	ActionEntryDelete       = "entry.delete"
+	ActionAccountImport     = "account.import"
+	ActionTransferImport    = "transfer.import"
+	ActionAccountRestore    = "account.restore"
+	ActionTransferRestore   = "transfer.restore"
+)
+
+// Audited entity types, written to audit_log.entity_type
+const (
+	EntityAccount  = "account"
+	EntityTransfer = "transfer"
+	EntityEntry    = "entry"
+)
+
+// SystemActor is recorded for mutations made without an actor in the context
+const SystemActor = "system"
+
+// AuditInfo describes who made a change and in which request
+type AuditInfo struct {
+	Actor     string
+	RequestID string
+}
+
+type auditKey struct{}
+
+// WithActor returns a copy of ctx recording actor as the author of the mutations made with it
+func WithActor(ctx context.Context, actor string) context.Context {
+	info := AuditFromContext(ctx)
+	info.Actor = actor
+	return context.WithValue(ctx, auditKey{}, info)
+}
+
+// WithRequestID returns a copy of ctx linking the mutations made with it to a request
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	info := AuditFromContext(ctx)
+	info.RequestID = requestID
+	return context.WithValue(ctx, auditKey{}, info)
+}
+
+// maxRequestIDLength bounds the length of the request IDs accepted from clients
+const maxRequestIDLength = 64
+
+// ValidRequestID tells whether a request ID sent by a client may be recorded: at most 64
+// letters, digits and dashes, which includes UUIDs. Callers replace any other with a new ID.
+func ValidRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range requestID {
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+// AuditFromContext returns the audit information stored in ctx, SystemActor when no actor was set
+func AuditFromContext(ctx context.Context) AuditInfo {
+	info, _ := ctx.Value(auditKey{}).(AuditInfo)
+	if info.Actor == "" {
+		info.Actor = SystemActor
+	}
+	return info
+}
+
+// audit appends a row to audit_log inside the caller's txn. A nil state is stored as JSON null.
+func audit(ctx context.Context, q *Queries, action, entityType string, entityID int64, before, after any) error {
+	row, err := auditLogRow(ctx, action, entityType, entityID, before, after)
+	if err != nil {
+		return err
+	}
+	_, err = q.CreateAuditLog(ctx, CreateAuditLogParams(row))
+	return err
+}
+
+// auditLogRow returns the audit_log row recording a change made with ctx, for audit or a COPY
+func auditLogRow(ctx context.Context, action, entityType string, entityID int64, before, after any) (CopyAuditLogsParams, error) {
+	beforeJSON, err := json.Marshal(before)
+	if err != nil {
+		return CopyAuditLogsParams{}, fmt.Errorf("marshal audit state: %w", err)
+	}
+	afterJSON, err := json.Marshal(after)
+	if err != nil {
+		return CopyAuditLogsParams{}, fmt.Errorf("marshal audit state: %w", err)
+	}
+
+	info := AuditFromContext(ctx)
+	return CopyAuditLogsParams{
+		Actor:      info.Actor,
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Before:     beforeJSON,
+		After:      afterJSON,
+		RequestID:  info.RequestID,
+	}, nil
+}
@@ -0,0 +1,129 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: audit_log.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+)
+
+type CopyAuditLogsParams struct {
+	Actor      string          `json:"actor"`
+	Action     string          `json:"action"`
+	EntityType string          `json:"entity_type"`
+	EntityID   int64           `json:"entity_id"`
+	Before     json.RawMessage `json:"before"`
+	After      json.RawMessage `json:"after"`
+	RequestID  string          `json:"request_id"`
+}
+
+const createAuditLog = `-- name: CreateAuditLog :one
+INSERT INTO audit_log (
+    actor,
+    action,
+    entity_type,
+    entity_id,
+    before,
+    after,
+    request_id
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+) RETURNING id, actor, action, entity_type, entity_id, before, after, request_id, created_at
+`
+
+type CreateAuditLogParams struct {
+	Actor      string          `json:"actor"`
+	Action     string          `json:"action"`
+	EntityType string          `json:"entity_type"`
+	EntityID   int64           `json:"entity_id"`
+	Before     json.RawMessage `json:"before"`
+	After      json.RawMessage `json:"after"`
+	RequestID  string          `json:"request_id"`
+}
+
+func (q *Queries) CreateAuditLog(ctx context.Context, arg CreateAuditLogParams) (AuditLog, error) {
+	row := q.db.QueryRow(ctx, createAuditLog,
+		arg.Actor,
+		arg.Action,
+		arg.EntityType,
+		arg.EntityID,
+		arg.Before,
+		arg.After,
+		arg.RequestID,
+	)
+	var i AuditLog
+	err := row.Scan(
+		&i.ID,
+		&i.Actor,
+		&i.Action,
+		&i.EntityType,
+		&i.EntityID,
+		&i.Before,
+		&i.After,
+		&i.RequestID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listAuditLogs = `-- name: ListAuditLogs :many
+SELECT id, actor, action, entity_type, entity_id, before, after, request_id, created_at FROM audit_log
+WHERE
+    ($1::varchar IS NULL OR entity_type = $1)
+    AND ($2::bigint IS NULL OR entity_id = $2)
+    AND created_at >= coalesce($3::timestamptz, '-infinity')
+    AND created_at < coalesce($4::timestamptz, 'infinity')
+    AND id > $5
+ORDER BY id
+LIMIT $6
+`
+
+type ListAuditLogsParams struct {
+	EntityType sql.NullString `json:"entity_type"`
+	EntityID   sql.NullInt64  `json:"entity_id"`
+	StartTime  sql.NullTime   `json:"start_time"`
+	EndTime    sql.NullTime   `json:"end_time"`
+	AfterID    int64          `json:"after_id"`
+	LimitCount int32          `json:"limit_count"`
+}
+
+func (q *Queries) ListAuditLogs(ctx context.Context, arg ListAuditLogsParams) ([]AuditLog, error) {
+	rows, err := q.db.Query(ctx, listAuditLogs,
+		arg.EntityType,
+		arg.EntityID,
+		arg.StartTime,
+		arg.EndTime,
+		arg.AfterID,
+		arg.LimitCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []AuditLog{}
+	for rows.Next() {
+		var i AuditLog
+		if err := rows.Scan(
+			&i.ID,
+			&i.Actor,
+			&i.Action,
+			&i.EntityType,
+			&i.EntityID,
+			&i.Before,
+			&i.After,
+			&i.RequestID,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
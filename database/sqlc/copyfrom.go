@@ -0,0 +1,333 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: copyfrom.go
+
+package db
+
+import (
+	"context"
+)
+
+// iteratorForCopyAccounts implements pgx.CopyFromSource.
+type iteratorForCopyAccounts struct {
+	rows                 []CopyAccountsParams
+	skippedFirstNextCall bool
+}
+
+func (r *iteratorForCopyAccounts) Next() bool {
+	if len(r.rows) == 0 {
+		return false
+	}
+	if !r.skippedFirstNextCall {
+		r.skippedFirstNextCall = true
+		return true
+	}
+	r.rows = r.rows[1:]
+	return len(r.rows) > 0
+}
+
+func (r iteratorForCopyAccounts) Values() ([]interface{}, error) {
+	return []interface{}{
+		r.rows[0].ID,
+		r.rows[0].Owner,
+		r.rows[0].Balance,
+		r.rows[0].Currency,
+		r.rows[0].CountryCode,
+		r.rows[0].CreatedAt,
+	}, nil
+}
+
+func (r iteratorForCopyAccounts) Err() error {
+	return nil
+}
+
+func (q *Queries) CopyAccounts(ctx context.Context, arg []CopyAccountsParams) (int64, error) {
+	return q.db.CopyFrom(ctx, []string{"accounts"}, []string{"id", "owner", "balance", "currency", "country_code", "created_at"}, &iteratorForCopyAccounts{rows: arg})
+}
+
+// iteratorForCopyAuditLogs implements pgx.CopyFromSource.
+type iteratorForCopyAuditLogs struct {
+	rows                 []CopyAuditLogsParams
+	skippedFirstNextCall bool
+}
+
+func (r *iteratorForCopyAuditLogs) Next() bool {
+	if len(r.rows) == 0 {
+		return false
+	}
+	if !r.skippedFirstNextCall {
+		r.skippedFirstNextCall = true
+		return true
+	}
+	r.rows = r.rows[1:]
+	return len(r.rows) > 0
+}
+
+func (r iteratorForCopyAuditLogs) Values() ([]interface{}, error) {
+	return []interface{}{
+		r.rows[0].Actor,
+		r.rows[0].Action,
+		r.rows[0].EntityType,
+		r.rows[0].EntityID,
+		r.rows[0].Before,
+		r.rows[0].After,
+		r.rows[0].RequestID,
+	}, nil
+}
+
+func (r iteratorForCopyAuditLogs) Err() error {
+	return nil
+}
+
+func (q *Queries) CopyAuditLogs(ctx context.Context, arg []CopyAuditLogsParams) (int64, error) {
+	return q.db.CopyFrom(ctx, []string{"audit_log"}, []string{"actor", "action", "entity_type", "entity_id", "before", "after", "request_id"}, &iteratorForCopyAuditLogs{rows: arg})
+}
+
+// iteratorForCopyEntries implements pgx.CopyFromSource.
+type iteratorForCopyEntries struct {
+	rows                 []CopyEntriesParams
+	skippedFirstNextCall bool
+}
+
+func (r *iteratorForCopyEntries) Next() bool {
+	if len(r.rows) == 0 {
+		return false
+	}
+	if !r.skippedFirstNextCall {
+		r.skippedFirstNextCall = true
+		return true
+	}
+	r.rows = r.rows[1:]
+	return len(r.rows) > 0
+}
+
+func (r iteratorForCopyEntries) Values() ([]interface{}, error) {
+	return []interface{}{
+		r.rows[0].AccountID,
+		r.rows[0].Amount,
+		r.rows[0].CreatedAt,
+	}, nil
+}
+
+func (r iteratorForCopyEntries) Err() error {
+	return nil
+}
+
+func (q *Queries) CopyEntries(ctx context.Context, arg []CopyEntriesParams) (int64, error) {
+	return q.db.CopyFrom(ctx, []string{"entries"}, []string{"account_id", "amount", "created_at"}, &iteratorForCopyEntries{rows: arg})
+}
+
+// iteratorForCopyImportErrors implements pgx.CopyFromSource.
+type iteratorForCopyImportErrors struct {
+	rows                 []CopyImportErrorsParams
+	skippedFirstNextCall bool
+}
+
+func (r *iteratorForCopyImportErrors) Next() bool {
+	if len(r.rows) == 0 {
+		return false
+	}
+	if !r.skippedFirstNextCall {
+		r.skippedFirstNextCall = true
+		return true
+	}
+	r.rows = r.rows[1:]
+	return len(r.rows) > 0
+}
+
+func (r iteratorForCopyImportErrors) Values() ([]interface{}, error) {
+	return []interface{}{
+		r.rows[0].ImportID,
+		r.rows[0].Line,
+		r.rows[0].Message,
+	}, nil
+}
+
+func (r iteratorForCopyImportErrors) Err() error {
+	return nil
+}
+
+func (q *Queries) CopyImportErrors(ctx context.Context, arg []CopyImportErrorsParams) (int64, error) {
+	return q.db.CopyFrom(ctx, []string{"import_errors"}, []string{"import_id", "line", "message"}, &iteratorForCopyImportErrors{rows: arg})
+}
+
+// iteratorForCopyImportedAccounts implements pgx.CopyFromSource.
+type iteratorForCopyImportedAccounts struct {
+	rows                 []CopyImportedAccountsParams
+	skippedFirstNextCall bool
+}
+
+func (r *iteratorForCopyImportedAccounts) Next() bool {
+	if len(r.rows) == 0 {
+		return false
+	}
+	if !r.skippedFirstNextCall {
+		r.skippedFirstNextCall = true
+		return true
+	}
+	r.rows = r.rows[1:]
+	return len(r.rows) > 0
+}
+
+func (r iteratorForCopyImportedAccounts) Values() ([]interface{}, error) {
+	return []interface{}{
+		r.rows[0].ExternalID,
+		r.rows[0].AccountID,
+		r.rows[0].ImportID,
+	}, nil
+}
+
+func (r iteratorForCopyImportedAccounts) Err() error {
+	return nil
+}
+
+func (q *Queries) CopyImportedAccounts(ctx context.Context, arg []CopyImportedAccountsParams) (int64, error) {
+	return q.db.CopyFrom(ctx, []string{"imported_accounts"}, []string{"external_id", "account_id", "import_id"}, &iteratorForCopyImportedAccounts{rows: arg})
+}
+
+// iteratorForCopyTransfers implements pgx.CopyFromSource.
+type iteratorForCopyTransfers struct {
+	rows                 []CopyTransfersParams
+	skippedFirstNextCall bool
+}
+
+func (r *iteratorForCopyTransfers) Next() bool {
+	if len(r.rows) == 0 {
+		return false
+	}
+	if !r.skippedFirstNextCall {
+		r.skippedFirstNextCall = true
+		return true
+	}
+	r.rows = r.rows[1:]
+	return len(r.rows) > 0
+}
+
+func (r iteratorForCopyTransfers) Values() ([]interface{}, error) {
+	return []interface{}{
+		r.rows[0].ID,
+		r.rows[0].FromAccountID,
+		r.rows[0].ToAccountID,
+		r.rows[0].Amount,
+		r.rows[0].CreatedAt,
+	}, nil
+}
+
+func (r iteratorForCopyTransfers) Err() error {
+	return nil
+}
+
+func (q *Queries) CopyTransfers(ctx context.Context, arg []CopyTransfersParams) (int64, error) {
+	return q.db.CopyFrom(ctx, []string{"transfers"}, []string{"id", "from_account_id", "to_account_id", "amount", "created_at"}, &iteratorForCopyTransfers{rows: arg})
+}
+
+// iteratorForRestoreAccounts implements pgx.CopyFromSource.
+type iteratorForRestoreAccounts struct {
+	rows                 []RestoreAccountsParams
+	skippedFirstNextCall bool
+}
+
+func (r *iteratorForRestoreAccounts) Next() bool {
+	if len(r.rows) == 0 {
+		return false
+	}
+	if !r.skippedFirstNextCall {
+		r.skippedFirstNextCall = true
+		return true
+	}
+	r.rows = r.rows[1:]
+	return len(r.rows) > 0
+}
+
+func (r iteratorForRestoreAccounts) Values() ([]interface{}, error) {
+	return []interface{}{
+		r.rows[0].ID,
+		r.rows[0].Owner,
+		r.rows[0].Balance,
+		r.rows[0].Currency,
+		r.rows[0].CreatedAt,
+		r.rows[0].CountryCode,
+		r.rows[0].IsFrozen,
+	}, nil
+}
+
+func (r iteratorForRestoreAccounts) Err() error {
+	return nil
+}
+
+func (q *Queries) RestoreAccounts(ctx context.Context, arg []RestoreAccountsParams) (int64, error) {
+	return q.db.CopyFrom(ctx, []string{"accounts"}, []string{"id", "owner", "balance", "currency", "created_at", "country_code", "is_frozen"}, &iteratorForRestoreAccounts{rows: arg})
+}
+
+// iteratorForRestoreEntries implements pgx.CopyFromSource.
+type iteratorForRestoreEntries struct {
+	rows                 []RestoreEntriesParams
+	skippedFirstNextCall bool
+}
+
+func (r *iteratorForRestoreEntries) Next() bool {
+	if len(r.rows) == 0 {
+		return false
+	}
+	if !r.skippedFirstNextCall {
+		r.skippedFirstNextCall = true
+		return true
+	}
+	r.rows = r.rows[1:]
+	return len(r.rows) > 0
+}
+
+func (r iteratorForRestoreEntries) Values() ([]interface{}, error) {
+	return []interface{}{
+		r.rows[0].ID,
+		r.rows[0].AccountID,
+		r.rows[0].Amount,
+		r.rows[0].CreatedAt,
+	}, nil
+}
+
+func (r iteratorForRestoreEntries) Err() error {
+	return nil
+}
+
+func (q *Queries) RestoreEntries(ctx context.Context, arg []RestoreEntriesParams) (int64, error) {
+	return q.db.CopyFrom(ctx, []string{"entries"}, []string{"id", "account_id", "amount", "created_at"}, &iteratorForRestoreEntries{rows: arg})
+}
+
+// iteratorForRestoreTransfers implements pgx.CopyFromSource.
+type iteratorForRestoreTransfers struct {
+	rows                 []RestoreTransfersParams
+	skippedFirstNextCall bool
+}
+
+func (r *iteratorForRestoreTransfers) Next() bool {
+	if len(r.rows) == 0 {
+		return false
+	}
+	if !r.skippedFirstNextCall {
+		r.skippedFirstNextCall = true
+		return true
+	}
+	r.rows = r.rows[1:]
+	return len(r.rows) > 0
+}
+
+func (r iteratorForRestoreTransfers) Values() ([]interface{}, error) {
+	return []interface{}{
+		r.rows[0].ID,
+		r.rows[0].FromAccountID,
+		r.rows[0].ToAccountID,
+		r.rows[0].Amount,
+		r.rows[0].CreatedAt,
+		r.rows[0].ReversesTransferID,
+	}, nil
+}
+
+func (r iteratorForRestoreTransfers) Err() error {
+	return nil
+}
+
+func (q *Queries) RestoreTransfers(ctx context.Context, arg []RestoreTransfersParams) (int64, error) {
+	return q.db.CopyFrom(ctx, []string{"transfers"}, []string{"id", "from_account_id", "to_account_id", "amount", "created_at", "reverses_transfer_id"}, &iteratorForRestoreTransfers{rows: arg})
+}
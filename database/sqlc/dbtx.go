@@ -0,0 +1,97 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// finishFunc is called by the DBTX decorators once a query is done, with the rows it returned
+// or affected and its error. Rows are read by the caller, so a query is done once its rows
+// are closed or its row is scanned.
+type finishFunc func(rows int64, err error)
+
+// execute runs an Exec through finish
+func execute(ctx context.Context, dbtx DBTX, query string, args []interface{}, finish finishFunc) (pgconn.CommandTag, error) {
+	tag, err := dbtx.Exec(ctx, query, args...)
+	finish(tag.RowsAffected(), err)
+	return tag, err
+}
+
+// query runs a Query whose rows call finish once closed
+func query(ctx context.Context, dbtx DBTX, query string, args []interface{}, finish finishFunc) (pgx.Rows, error) {
+	rows, err := dbtx.Query(ctx, query, args...)
+	if err != nil {
+		finish(0, err)
+		return nil, err
+	}
+	return &finishingRows{Rows: rows, finish: finish}, nil
+}
+
+// queryRow runs a QueryRow whose row calls finish once scanned
+func queryRow(ctx context.Context, dbtx DBTX, query string, args []interface{}, finish finishFunc) pgx.Row {
+	return finishingRow{row: dbtx.QueryRow(ctx, query, args...), finish: finish}
+}
+
+// copyFrom runs a CopyFrom through finish, with the rows it copied
+func copyFrom(ctx context.Context, dbtx DBTX, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource, finish finishFunc) (int64, error) {
+	rows, err := dbtx.CopyFrom(ctx, tableName, columnNames, rowSrc)
+	finish(rows, err)
+	return rows, err
+}
+
+// copyStatement is the COPY statement pgx runs for a CopyFrom, which the decorators report like a
+// query: QueryName names it COPY, and the table tells the copies apart in traces and logs
+func copyStatement(tableName pgx.Identifier, columnNames []string) string {
+	columns := make([]string, len(columnNames))
+	for i, name := range columnNames {
+		columns[i] = pgx.Identifier{name}.Sanitize()
+	}
+	return "COPY " + tableName.Sanitize() + " (" + strings.Join(columns, ", ") + ") FROM STDIN"
+}
+
+type finishingRows struct {
+	pgx.Rows
+	finish   finishFunc
+	finished bool
+}
+
+// Next closes the rows once read, as pgx does, which finishes the query
+func (rows *finishingRows) Next() bool {
+	if rows.Rows.Next() {
+		return true
+	}
+	rows.Close()
+	return false
+}
+
+func (rows *finishingRows) Close() {
+	rows.Rows.Close()
+	if !rows.finished {
+		rows.finished = true
+		rows.finish(rows.CommandTag().RowsAffected(), rows.Err())
+	}
+}
+
+type finishingRow struct {
+	row    pgx.Row
+	finish finishFunc
+}
+
+func (row finishingRow) Scan(dest ...any) error {
+	err := row.row.Scan(dest...)
+	if err != nil {
+		row.finish(0, err)
+	} else {
+		row.finish(1, nil)
+	}
+	return err
+}
+
+// failed tells whether a query failed, finding no rows isn't a failure
+func failed(err error) bool {
+	return err != nil && !errors.Is(err, pgx.ErrNoRows)
+}
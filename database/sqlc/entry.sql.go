@@ -7,10 +7,16 @@ package db
 
 import (
 	"context"
-
-	"github.com/lib/pq"
+	"database/sql"
+	"time"
 )
 
+type CopyEntriesParams struct {
+	AccountID int64     `json:"account_id"`
+	Amount    float64   `json:"amount"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
 const createEntry = `-- name: CreateEntry :one
 INSERT INTO entries (
     account_id,
@@ -26,7 +32,7 @@ type CreateEntryParams struct {
 }
 
 func (q *Queries) CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error) {
-	row := q.db.QueryRowContext(ctx, createEntry, arg.AccountID, arg.Amount)
+	row := q.db.QueryRow(ctx, createEntry, arg.AccountID, arg.Amount)
 	var i Entry
 	err := row.Scan(
 		&i.ID,
@@ -42,17 +48,58 @@ DELETE FROM entries WHERE id = $1
 `
 
 func (q *Queries) DeleteEntry(ctx context.Context, id int64) error {
-	_, err := q.db.ExecContext(ctx, deleteEntry, id)
+	_, err := q.db.Exec(ctx, deleteEntry, id)
 	return err
 }
 
+const exportEntries = `-- name: ExportEntries :many
+SELECT id, account_id, amount, created_at FROM (
+    SELECT id, account_id, amount, created_at FROM entries
+    UNION ALL
+    SELECT id, account_id, amount, created_at FROM entries_archive
+) AS entries
+WHERE id > $1::bigint
+ORDER BY id
+LIMIT $2
+`
+
+type ExportEntriesParams struct {
+	AfterID    int64 `json:"after_id"`
+	LimitCount int32 `json:"limit_count"`
+}
+
+func (q *Queries) ExportEntries(ctx context.Context, arg ExportEntriesParams) ([]Entry, error) {
+	rows, err := q.db.Query(ctx, exportEntries, arg.AfterID, arg.LimitCount)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Entry{}
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getEntry = `-- name: GetEntry :one
 SELECT id, account_id, amount, created_at FROM entries
 WHERE id = $1 LIMIT 1
 `
 
 func (q *Queries) GetEntry(ctx context.Context, id int64) (Entry, error) {
-	row := q.db.QueryRowContext(ctx, getEntry, id)
+	row := q.db.QueryRow(ctx, getEntry, id)
 	var i Entry
 	err := row.Scan(
 		&i.ID,
@@ -66,19 +113,29 @@ func (q *Queries) GetEntry(ctx context.Context, id int64) (Entry, error) {
 const listEntries = `-- name: ListEntries :many
 SELECT id, account_id, amount, created_at FROM entries
 WHERE account_id = ANY($1::bigint[])
+    AND created_at >= coalesce($2::timestamp, '-infinity')
+    AND created_at < coalesce($3::timestamp, 'infinity')
 ORDER BY id
-LIMIT $2
-OFFSET $3
+LIMIT $5
+OFFSET $4
 `
 
 type ListEntriesParams struct {
-	Column1 []int64 `json:"column_1"`
-	Limit   int32   `json:"limit"`
-	Offset  int32   `json:"offset"`
+	AccountIds  []int64      `json:"account_ids"`
+	StartTime   sql.NullTime `json:"start_time"`
+	EndTime     sql.NullTime `json:"end_time"`
+	OffsetCount int32        `json:"offset_count"`
+	LimitCount  int32        `json:"limit_count"`
 }
 
 func (q *Queries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error) {
-	rows, err := q.db.QueryContext(ctx, listEntries, pq.Array(arg.Column1), arg.Limit, arg.Offset)
+	rows, err := q.db.Query(ctx, listEntries,
+		arg.AccountIds,
+		arg.StartTime,
+		arg.EndTime,
+		arg.OffsetCount,
+		arg.LimitCount,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -96,11 +153,300 @@ func (q *Queries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Ent
 		}
 		items = append(items, i)
 	}
-	if err := rows.Close(); err != nil {
+	if err := rows.Err(); err != nil {
 		return nil, err
 	}
+	return items, nil
+}
+
+const listEntriesAfter = `-- name: ListEntriesAfter :many
+SELECT id, account_id, amount, created_at FROM entries
+WHERE account_id = ANY($1::bigint[])
+    AND CASE $2::text WHEN 'in' THEN amount > 0 WHEN 'out' THEN amount < 0 ELSE true END
+    AND created_at >= coalesce($3::timestamp, '-infinity')
+    AND created_at < coalesce($4::timestamp, 'infinity')
+    AND abs(amount) >= coalesce($5::float, 0)
+    AND abs(amount) <= coalesce($6::float, 'infinity')
+    AND (created_at, id) > ($7::timestamp, $8::bigint)
+ORDER BY created_at, id
+LIMIT $9
+`
+
+type ListEntriesAfterParams struct {
+	AccountIds     []int64         `json:"account_ids"`
+	Direction      string          `json:"direction"`
+	StartTime      sql.NullTime    `json:"start_time"`
+	EndTime        sql.NullTime    `json:"end_time"`
+	MinAmount      sql.NullFloat64 `json:"min_amount"`
+	MaxAmount      sql.NullFloat64 `json:"max_amount"`
+	AfterCreatedAt time.Time       `json:"after_created_at"`
+	AfterID        int64           `json:"after_id"`
+	LimitCount     int32           `json:"limit_count"`
+}
+
+func (q *Queries) ListEntriesAfter(ctx context.Context, arg ListEntriesAfterParams) ([]Entry, error) {
+	rows, err := q.db.Query(ctx, listEntriesAfter,
+		arg.AccountIds,
+		arg.Direction,
+		arg.StartTime,
+		arg.EndTime,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.LimitCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Entry{}
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
 	return items, nil
 }
+
+const listEntriesBefore = `-- name: ListEntriesBefore :many
+SELECT id, account_id, amount, created_at FROM entries
+WHERE account_id = ANY($1::bigint[])
+    AND CASE $2::text WHEN 'in' THEN amount > 0 WHEN 'out' THEN amount < 0 ELSE true END
+    AND created_at >= coalesce($3::timestamp, '-infinity')
+    AND created_at < coalesce($4::timestamp, 'infinity')
+    AND abs(amount) >= coalesce($5::float, 0)
+    AND abs(amount) <= coalesce($6::float, 'infinity')
+    AND (created_at, id) < ($7::timestamp, $8::bigint)
+ORDER BY created_at DESC, id DESC
+LIMIT $9
+`
+
+type ListEntriesBeforeParams struct {
+	AccountIds      []int64         `json:"account_ids"`
+	Direction       string          `json:"direction"`
+	StartTime       sql.NullTime    `json:"start_time"`
+	EndTime         sql.NullTime    `json:"end_time"`
+	MinAmount       sql.NullFloat64 `json:"min_amount"`
+	MaxAmount       sql.NullFloat64 `json:"max_amount"`
+	BeforeCreatedAt time.Time       `json:"before_created_at"`
+	BeforeID        int64           `json:"before_id"`
+	LimitCount      int32           `json:"limit_count"`
+}
+
+func (q *Queries) ListEntriesBefore(ctx context.Context, arg ListEntriesBeforeParams) ([]Entry, error) {
+	rows, err := q.db.Query(ctx, listEntriesBefore,
+		arg.AccountIds,
+		arg.Direction,
+		arg.StartTime,
+		arg.EndTime,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.BeforeCreatedAt,
+		arg.BeforeID,
+		arg.LimitCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Entry{}
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEntriesWithArchiveAfter = `-- name: ListEntriesWithArchiveAfter :many
+SELECT id, account_id, amount, created_at FROM (
+    SELECT id, account_id, amount, created_at FROM entries
+    UNION ALL
+    SELECT id, account_id, amount, created_at FROM entries_archive
+) AS entries
+WHERE account_id = ANY($1::bigint[])
+    AND CASE $2::text WHEN 'in' THEN amount > 0 WHEN 'out' THEN amount < 0 ELSE true END
+    AND created_at >= coalesce($3::timestamp, '-infinity')
+    AND created_at < coalesce($4::timestamp, 'infinity')
+    AND abs(amount) >= coalesce($5::float, 0)
+    AND abs(amount) <= coalesce($6::float, 'infinity')
+    AND (created_at, id) > ($7::timestamp, $8::bigint)
+ORDER BY created_at, id
+LIMIT $9
+`
+
+type ListEntriesWithArchiveAfterParams struct {
+	AccountIds     []int64         `json:"account_ids"`
+	Direction      string          `json:"direction"`
+	StartTime      sql.NullTime    `json:"start_time"`
+	EndTime        sql.NullTime    `json:"end_time"`
+	MinAmount      sql.NullFloat64 `json:"min_amount"`
+	MaxAmount      sql.NullFloat64 `json:"max_amount"`
+	AfterCreatedAt time.Time       `json:"after_created_at"`
+	AfterID        int64           `json:"after_id"`
+	LimitCount     int32           `json:"limit_count"`
+}
+
+func (q *Queries) ListEntriesWithArchiveAfter(ctx context.Context, arg ListEntriesWithArchiveAfterParams) ([]Entry, error) {
+	rows, err := q.db.Query(ctx, listEntriesWithArchiveAfter,
+		arg.AccountIds,
+		arg.Direction,
+		arg.StartTime,
+		arg.EndTime,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.LimitCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Entry{}
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEntriesWithArchiveBefore = `-- name: ListEntriesWithArchiveBefore :many
+SELECT id, account_id, amount, created_at FROM (
+    SELECT id, account_id, amount, created_at FROM entries
+    UNION ALL
+    SELECT id, account_id, amount, created_at FROM entries_archive
+) AS entries
+WHERE account_id = ANY($1::bigint[])
+    AND CASE $2::text WHEN 'in' THEN amount > 0 WHEN 'out' THEN amount < 0 ELSE true END
+    AND created_at >= coalesce($3::timestamp, '-infinity')
+    AND created_at < coalesce($4::timestamp, 'infinity')
+    AND abs(amount) >= coalesce($5::float, 0)
+    AND abs(amount) <= coalesce($6::float, 'infinity')
+    AND (created_at, id) < ($7::timestamp, $8::bigint)
+ORDER BY created_at DESC, id DESC
+LIMIT $9
+`
+
+type ListEntriesWithArchiveBeforeParams struct {
+	AccountIds      []int64         `json:"account_ids"`
+	Direction       string          `json:"direction"`
+	StartTime       sql.NullTime    `json:"start_time"`
+	EndTime         sql.NullTime    `json:"end_time"`
+	MinAmount       sql.NullFloat64 `json:"min_amount"`
+	MaxAmount       sql.NullFloat64 `json:"max_amount"`
+	BeforeCreatedAt time.Time       `json:"before_created_at"`
+	BeforeID        int64           `json:"before_id"`
+	LimitCount      int32           `json:"limit_count"`
+}
+
+func (q *Queries) ListEntriesWithArchiveBefore(ctx context.Context, arg ListEntriesWithArchiveBeforeParams) ([]Entry, error) {
+	rows, err := q.db.Query(ctx, listEntriesWithArchiveBefore,
+		arg.AccountIds,
+		arg.Direction,
+		arg.StartTime,
+		arg.EndTime,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.BeforeCreatedAt,
+		arg.BeforeID,
+		arg.LimitCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Entry{}
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listLatestEntries = `-- name: ListLatestEntries :many
+SELECT id, account_id, amount, created_at FROM entries
+WHERE account_id = $1
+ORDER BY id DESC
+LIMIT $2
+`
+
+type ListLatestEntriesParams struct {
+	AccountID int64 `json:"account_id"`
+	Limit     int32 `json:"limit"`
+}
+
+func (q *Queries) ListLatestEntries(ctx context.Context, arg ListLatestEntriesParams) ([]Entry, error) {
+	rows, err := q.db.Query(ctx, listLatestEntries, arg.AccountID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Entry{}
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+type RestoreEntriesParams struct {
+	ID        int64     `json:"id"`
+	AccountID int64     `json:"account_id"`
+	Amount    float64   `json:"amount"`
+	CreatedAt time.Time `json:"created_at"`
+}
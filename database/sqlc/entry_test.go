@@ -89,9 +89,9 @@ func TestQueries_ListEntries(t *testing.T) {
 		cleanupList = append(cleanupList, entry.ID)
 	}
 	arg := ListEntriesParams{
-		Column1: getUniqueAccountIDs(t, accountIDs),
-		Limit:   5,
-		Offset:  5,
+		AccountIds:  getUniqueAccountIDs(t, accountIDs),
+		LimitCount:  5,
+		OffsetCount: 5,
 	}
 	entries, err := testQueries.ListEntries(context.Background(), arg)
 	assert.NoError(t, err)
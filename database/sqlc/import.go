@@ -0,0 +1,339 @@
+package db
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Kinds of CSV files imported, written to imports.kind
+const (
+	ImportAccounts  = "accounts"
+	ImportTransfers = "transfers"
+)
+
+// ErrImportConflict is returned when another run advanced or finished the import since it was read,
+// the batch is rolled back so that no record is imported twice
+var ErrImportConflict = errors.New("import was advanced by another run")
+
+// ImportRowError rejects a CSV record of an import, Line being its line in the file
+type ImportRowError struct {
+	Line    int64  `json:"line"`
+	Message string `json:"message"`
+}
+
+// ImportAccountRow is an account read from a CSV record, ExternalID being its id in the old system
+type ImportAccountRow struct {
+	Line        int64
+	ExternalID  string
+	Owner       string
+	Currency    string
+	Balance     float64
+	CountryCode sql.NullInt32
+	CreatedAt   time.Time
+}
+
+// ImportTransferRow is a transfer read from a CSV record, between accounts imported before
+type ImportTransferRow struct {
+	Line           int64
+	FromExternalID string
+	ToExternalID   string
+	Amount         float64
+	CreatedAt      time.Time
+}
+
+// ImportAccountsParams is a batch of CSV records read after the ones Import processed. Every record
+// is either one of the Rows or one of the Errors, which were rejected before reaching the store.
+type ImportAccountsParams struct {
+	Import Import
+	Rows   []ImportAccountRow
+	Errors []ImportRowError
+}
+
+// ImportTransfersParams is a batch of CSV records read after the ones Import processed, see ImportAccountsParams
+type ImportTransfersParams struct {
+	Import Import
+	Rows   []ImportTransferRow
+	Errors []ImportRowError
+}
+
+type ImportBatchResult struct {
+	// Import holds the totals once the batch is committed
+	Import Import `json:"import"`
+	// Errors lists every record of the batch that was rejected, by line
+	Errors []ImportRowError `json:"errors"`
+}
+
+// ImportAccountsTransaction creates the accounts of a batch with COPY, along with the entries of
+// their opening balances and their audit, and advances the import within a single db txn. Accounts
+// whose external id was already imported are rejected. No domain events are emitted: the accounts
+// are history from the old system rather than activity to notify.
+func (store *SQLStore) ImportAccountsTransaction(ctx context.Context, arg ImportAccountsParams) (ImportBatchResult, error) {
+	var result ImportBatchResult
+	err := store.executeTransaction(ctx, func(q *Queries) error {
+		rejected := slices.Clone(arg.Errors)
+		externalIDs := make([]string, len(arg.Rows))
+		for i, row := range arg.Rows {
+			externalIDs[i] = row.ExternalID
+		}
+		existing, err := q.ListImportedExternalIDs(ctx, externalIDs)
+		if err != nil {
+			return err
+		}
+		imported := make(map[string]bool, len(arg.Rows))
+		for _, externalID := range existing {
+			imported[externalID] = true
+		}
+
+		var rows []ImportAccountRow
+		for _, row := range arg.Rows {
+			if imported[row.ExternalID] {
+				rejected = append(rejected, ImportRowError{Line: row.Line, Message: fmt.Sprintf("account %q was already imported", row.ExternalID)})
+				continue
+			}
+			imported[row.ExternalID] = true
+			rows = append(rows, row)
+		}
+
+		if len(rows) > 0 {
+			if err := copyAccounts(ctx, q, arg.Import.ID, rows); err != nil {
+				return err
+			}
+		}
+		result, err = commitBatch(ctx, q, arg.Import, int64(len(rows)), rejected)
+		return err
+	})
+	return result, err
+}
+
+// copyAccounts inserts the accounts with the ids they are given up front, so that
+// their entries, external ids and audit are copied along without reading them back
+func copyAccounts(ctx context.Context, q *Queries, importID int64, rows []ImportAccountRow) error {
+	ids, err := q.NextAccountIDs(ctx, int32(len(rows)))
+	if err != nil {
+		return err
+	}
+
+	accounts := make([]CopyAccountsParams, len(rows))
+	mappings := make([]CopyImportedAccountsParams, len(rows))
+	audits := make([]CopyAuditLogsParams, len(rows))
+	var entries []CopyEntriesParams
+	for i, row := range rows {
+		account := Account{
+			ID:          ids[i],
+			Owner:       row.Owner,
+			Balance:     row.Balance,
+			Currency:    row.Currency,
+			CreatedAt:   row.CreatedAt,
+			CountryCode: row.CountryCode,
+		}
+		accounts[i] = CopyAccountsParams{
+			ID:          account.ID,
+			Owner:       account.Owner,
+			Balance:     account.Balance,
+			Currency:    account.Currency,
+			CountryCode: account.CountryCode,
+			CreatedAt:   account.CreatedAt,
+		}
+		mappings[i] = CopyImportedAccountsParams{ExternalID: row.ExternalID, AccountID: account.ID, ImportID: importID}
+		// like OpenAccountTransaction, the balance always matches the sum of the account's entries
+		if account.Balance != 0 {
+			entries = append(entries, CopyEntriesParams{AccountID: account.ID, Amount: account.Balance, CreatedAt: account.CreatedAt})
+		}
+		audits[i], err = auditLogRow(ctx, ActionAccountImport, EntityAccount, account.ID, nil, account)
+		if err != nil {
+			return err
+		}
+	}
+
+	if _, err := q.CopyAccounts(ctx, accounts); err != nil {
+		return err
+	}
+	if _, err := q.CopyEntries(ctx, entries); err != nil {
+		return err
+	}
+	if _, err := q.CopyImportedAccounts(ctx, mappings); err != nil {
+		return err
+	}
+	_, err = q.CopyAuditLogs(ctx, audits)
+	return err
+}
+
+// ImportTransfersTransaction applies the transfers of a batch within a single db txn: the transfers
+// and their two entries each are created with COPY, the balances of the accounts are moved by the
+// sum of their entries, the transfers and accounts are audited and the import is advanced. Transfers
+// from or to an account that wasn't imported or is frozen, or between currencies, are rejected.
+// Like ImportAccountsTransaction, no domain events are emitted.
+func (store *SQLStore) ImportTransfersTransaction(ctx context.Context, arg ImportTransfersParams) (ImportBatchResult, error) {
+	var result ImportBatchResult
+	err := store.executeTransaction(ctx, func(q *Queries) error {
+		rejected := slices.Clone(arg.Errors)
+		var externalIDs []string
+		for _, row := range arg.Rows {
+			externalIDs = append(externalIDs, row.FromExternalID, row.ToExternalID)
+		}
+		slices.Sort(externalIDs)
+		// the rows are locked in id order so that concurrent transfers cannot deadlock with the batch
+		locked, err := q.ListImportedAccountsForUpdate(ctx, slices.Compact(externalIDs))
+		if err != nil {
+			return err
+		}
+		accounts := make(map[string]Account, len(locked))
+		for _, row := range locked {
+			accounts[row.ExternalID] = Account{
+				ID:          row.ID,
+				Owner:       row.Owner,
+				Balance:     row.Balance,
+				Currency:    row.Currency,
+				CreatedAt:   row.CreatedAt,
+				CountryCode: row.CountryCode,
+				IsFrozen:    row.IsFrozen,
+			}
+		}
+
+		var rows []ImportTransferRow
+		for _, row := range arg.Rows {
+			if message := checkImportTransfer(row, accounts); message != "" {
+				rejected = append(rejected, ImportRowError{Line: row.Line, Message: message})
+				continue
+			}
+			rows = append(rows, row)
+		}
+
+		if len(rows) > 0 {
+			if err := copyTransfers(ctx, q, rows, accounts); err != nil {
+				return err
+			}
+		}
+		result, err = commitBatch(ctx, q, arg.Import, int64(len(rows)), rejected)
+		return err
+	})
+	return result, err
+}
+
+// checkImportTransfer returns why the transfer cannot be applied between the accounts, by external id, if it can't
+func checkImportTransfer(row ImportTransferRow, accounts map[string]Account) string {
+	from, ok := accounts[row.FromExternalID]
+	if !ok {
+		return fmt.Sprintf("account %q was not imported", row.FromExternalID)
+	}
+	to, ok := accounts[row.ToExternalID]
+	if !ok {
+		return fmt.Sprintf("account %q was not imported", row.ToExternalID)
+	}
+	if from.Currency != to.Currency {
+		return fmt.Sprintf("currency mismatch: account %q holds %s, account %q holds %s",
+			row.FromExternalID, from.Currency, row.ToExternalID, to.Currency)
+	}
+	if from.IsFrozen {
+		return fmt.Sprintf("%v: account %q", ErrAccountFrozen, row.FromExternalID)
+	}
+	if to.IsFrozen {
+		return fmt.Sprintf("%v: account %q", ErrAccountFrozen, row.ToExternalID)
+	}
+	return ""
+}
+
+// copyTransfers inserts the transfers with the ids they are given up front and their entries,
+// then moves the balance of every account once by the sum of its entries
+func copyTransfers(ctx context.Context, q *Queries, rows []ImportTransferRow, accounts map[string]Account) error {
+	ids, err := q.NextTransferIDs(ctx, int32(len(rows)))
+	if err != nil {
+		return err
+	}
+
+	transfers := make([]CopyTransfersParams, len(rows))
+	entries := make([]CopyEntriesParams, 0, 2*len(rows))
+	audits := make([]CopyAuditLogsParams, 0, len(rows))
+	before := make(map[int64]Account)
+	moves := make(map[int64]float64)
+	for i, row := range rows {
+		from, to := accounts[row.FromExternalID], accounts[row.ToExternalID]
+		transfer := Transfer{
+			ID:            ids[i],
+			FromAccountID: from.ID,
+			ToAccountID:   to.ID,
+			Amount:        row.Amount,
+			CreatedAt:     row.CreatedAt,
+		}
+		transfers[i] = CopyTransfersParams{
+			ID:            transfer.ID,
+			FromAccountID: transfer.FromAccountID,
+			ToAccountID:   transfer.ToAccountID,
+			Amount:        transfer.Amount,
+			CreatedAt:     transfer.CreatedAt,
+		}
+		entries = append(entries,
+			CopyEntriesParams{AccountID: from.ID, Amount: -row.Amount, CreatedAt: row.CreatedAt},
+			CopyEntriesParams{AccountID: to.ID, Amount: row.Amount, CreatedAt: row.CreatedAt},
+		)
+		before[from.ID], before[to.ID] = from, to
+		moves[from.ID] -= row.Amount
+		moves[to.ID] += row.Amount
+
+		audit, err := auditLogRow(ctx, ActionTransferImport, EntityTransfer, transfer.ID, nil, transfer)
+		if err != nil {
+			return err
+		}
+		audits = append(audits, audit)
+	}
+
+	if _, err := q.CopyTransfers(ctx, transfers); err != nil {
+		return err
+	}
+	if _, err := q.CopyEntries(ctx, entries); err != nil {
+		return err
+	}
+
+	var balances AddAccountBalancesParams
+	for id, amount := range moves {
+		balances.Ids = append(balances.Ids, id)
+		balances.Amounts = append(balances.Amounts, amount)
+	}
+	after, err := q.AddAccountBalances(ctx, balances)
+	if err != nil {
+		return err
+	}
+	slices.SortFunc(after, func(a, b Account) int { return cmp.Compare(a.ID, b.ID) })
+	for _, account := range after {
+		audit, err := auditLogRow(ctx, ActionTransferImport, EntityAccount, account.ID, before[account.ID], account)
+		if err != nil {
+			return err
+		}
+		audits = append(audits, audit)
+	}
+	_, err = q.CopyAuditLogs(ctx, audits)
+	return err
+}
+
+// commitBatch records the records of a batch as processed, along with the rejected ones
+func commitBatch(ctx context.Context, q *Queries, imp Import, imported int64, rejected []ImportRowError) (ImportBatchResult, error) {
+	slices.SortFunc(rejected, func(a, b ImportRowError) int { return cmp.Compare(a.Line, b.Line) })
+	errs := make([]CopyImportErrorsParams, len(rejected))
+	for i, rowErr := range rejected {
+		errs[i] = CopyImportErrorsParams{ImportID: imp.ID, Line: rowErr.Line, Message: rowErr.Message}
+	}
+	if _, err := q.CopyImportErrors(ctx, errs); err != nil {
+		return ImportBatchResult{}, err
+	}
+
+	// the row lock makes a concurrent run of the same import wait for the batch, then miss its update
+	advanced, err := q.AdvanceImport(ctx, AdvanceImportParams{
+		ID:                 imp.ID,
+		AfterRowsProcessed: imp.RowsProcessed,
+		RowsProcessed:      imported + int64(len(rejected)),
+		RowsImported:       imported,
+		RowsFailed:         int64(len(rejected)),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return ImportBatchResult{}, fmt.Errorf("import %q: %w", imp.Name, ErrImportConflict)
+	}
+	if err != nil {
+		return ImportBatchResult{}, err
+	}
+	return ImportBatchResult{Import: advanced, Errors: rejected}, nil
+}
@@ -0,0 +1,252 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: import.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const advanceImport = `-- name: AdvanceImport :one
+UPDATE imports
+SET
+    rows_processed = rows_processed + $1,
+    rows_imported = rows_imported + $2,
+    rows_failed = rows_failed + $3
+WHERE id = $4 AND rows_processed = $5 AND finished_at IS NULL
+RETURNING id, name, kind, rows_processed, rows_imported, rows_failed, finished_at, created_at
+`
+
+type AdvanceImportParams struct {
+	RowsProcessed      int64 `json:"rows_processed"`
+	RowsImported       int64 `json:"rows_imported"`
+	RowsFailed         int64 `json:"rows_failed"`
+	ID                 int64 `json:"id"`
+	AfterRowsProcessed int64 `json:"after_rows_processed"`
+}
+
+func (q *Queries) AdvanceImport(ctx context.Context, arg AdvanceImportParams) (Import, error) {
+	row := q.db.QueryRow(ctx, advanceImport,
+		arg.RowsProcessed,
+		arg.RowsImported,
+		arg.RowsFailed,
+		arg.ID,
+		arg.AfterRowsProcessed,
+	)
+	var i Import
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Kind,
+		&i.RowsProcessed,
+		&i.RowsImported,
+		&i.RowsFailed,
+		&i.FinishedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+type CopyImportErrorsParams struct {
+	ImportID int64  `json:"import_id"`
+	Line     int64  `json:"line"`
+	Message  string `json:"message"`
+}
+
+type CopyImportedAccountsParams struct {
+	ExternalID string `json:"external_id"`
+	AccountID  int64  `json:"account_id"`
+	ImportID   int64  `json:"import_id"`
+}
+
+const finishImport = `-- name: FinishImport :one
+UPDATE imports
+SET finished_at = now()
+WHERE id = $1 AND finished_at IS NULL
+RETURNING id, name, kind, rows_processed, rows_imported, rows_failed, finished_at, created_at
+`
+
+func (q *Queries) FinishImport(ctx context.Context, id int64) (Import, error) {
+	row := q.db.QueryRow(ctx, finishImport, id)
+	var i Import
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Kind,
+		&i.RowsProcessed,
+		&i.RowsImported,
+		&i.RowsFailed,
+		&i.FinishedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getImport = `-- name: GetImport :one
+SELECT id, name, kind, rows_processed, rows_imported, rows_failed, finished_at, created_at FROM imports
+WHERE name = $1 LIMIT 1
+`
+
+func (q *Queries) GetImport(ctx context.Context, name string) (Import, error) {
+	row := q.db.QueryRow(ctx, getImport, name)
+	var i Import
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Kind,
+		&i.RowsProcessed,
+		&i.RowsImported,
+		&i.RowsFailed,
+		&i.FinishedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getOrCreateImport = `-- name: GetOrCreateImport :one
+INSERT INTO imports (
+    name,
+    kind
+) VALUES (
+    $1, $2
+)
+ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
+RETURNING id, name, kind, rows_processed, rows_imported, rows_failed, finished_at, created_at
+`
+
+type GetOrCreateImportParams struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+}
+
+func (q *Queries) GetOrCreateImport(ctx context.Context, arg GetOrCreateImportParams) (Import, error) {
+	row := q.db.QueryRow(ctx, getOrCreateImport, arg.Name, arg.Kind)
+	var i Import
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Kind,
+		&i.RowsProcessed,
+		&i.RowsImported,
+		&i.RowsFailed,
+		&i.FinishedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listImportErrors = `-- name: ListImportErrors :many
+SELECT id, import_id, line, message FROM import_errors
+WHERE import_id = $1 AND id > $2
+ORDER BY id
+LIMIT $3
+`
+
+type ListImportErrorsParams struct {
+	ImportID   int64 `json:"import_id"`
+	AfterID    int64 `json:"after_id"`
+	LimitCount int32 `json:"limit_count"`
+}
+
+func (q *Queries) ListImportErrors(ctx context.Context, arg ListImportErrorsParams) ([]ImportError, error) {
+	rows, err := q.db.Query(ctx, listImportErrors, arg.ImportID, arg.AfterID, arg.LimitCount)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ImportError{}
+	for rows.Next() {
+		var i ImportError
+		if err := rows.Scan(
+			&i.ID,
+			&i.ImportID,
+			&i.Line,
+			&i.Message,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listImportedAccountsForUpdate = `-- name: ListImportedAccountsForUpdate :many
+SELECT imported_accounts.external_id, accounts.id, accounts.owner, accounts.balance, accounts.currency, accounts.created_at, accounts.country_code, accounts.is_frozen
+FROM imported_accounts
+JOIN accounts ON accounts.id = imported_accounts.account_id
+WHERE imported_accounts.external_id = ANY($1::varchar[])
+ORDER BY accounts.id
+FOR NO KEY UPDATE OF accounts
+`
+
+type ListImportedAccountsForUpdateRow struct {
+	ExternalID  string        `json:"external_id"`
+	ID          int64         `json:"id"`
+	Owner       string        `json:"owner"`
+	Balance     float64       `json:"balance"`
+	Currency    string        `json:"currency"`
+	CreatedAt   time.Time     `json:"created_at"`
+	CountryCode sql.NullInt32 `json:"country_code"`
+	IsFrozen    bool          `json:"is_frozen"`
+}
+
+func (q *Queries) ListImportedAccountsForUpdate(ctx context.Context, externalIds []string) ([]ListImportedAccountsForUpdateRow, error) {
+	rows, err := q.db.Query(ctx, listImportedAccountsForUpdate, externalIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListImportedAccountsForUpdateRow{}
+	for rows.Next() {
+		var i ListImportedAccountsForUpdateRow
+		if err := rows.Scan(
+			&i.ExternalID,
+			&i.ID,
+			&i.Owner,
+			&i.Balance,
+			&i.Currency,
+			&i.CreatedAt,
+			&i.CountryCode,
+			&i.IsFrozen,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listImportedExternalIDs = `-- name: ListImportedExternalIDs :many
+SELECT external_id FROM imported_accounts
+WHERE external_id = ANY($1::varchar[])
+`
+
+func (q *Queries) ListImportedExternalIDs(ctx context.Context, externalIds []string) ([]string, error) {
+	rows, err := q.db.Query(ctx, listImportedExternalIDs, externalIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []string{}
+	for rows.Next() {
+		var external_id string
+		if err := rows.Scan(&external_id); err != nil {
+			return nil, err
+		}
+		items = append(items, external_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,150 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/arpangoswami/backend-golang-dev/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func startRandomImport(t *testing.T, kind string) Import {
+	t.Helper()
+	imp, err := testQueries.GetOrCreateImport(context.Background(), GetOrCreateImportParams{
+		Name: kind + "-" + util.RandomString(8),
+		Kind: kind,
+	})
+	assert.NoError(t, err)
+	assert.Zero(t, imp.RowsProcessed)
+	return imp
+}
+
+func TestStore_ImportAccountsTransaction(t *testing.T) {
+	store := NewStore(testDB)
+	ctx := context.Background()
+	imp := startRandomImport(t, ImportAccounts)
+	prefix := util.RandomString(8)
+	createdAt := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	rows := []ImportAccountRow{
+		{Line: 2, ExternalID: prefix + "-1", Owner: util.RandomOwner(), Currency: util.USD, Balance: 100, CreatedAt: createdAt},
+		{Line: 4, ExternalID: prefix + "-2", Owner: util.RandomOwner(), Currency: util.USD, CreatedAt: createdAt},
+		{Line: 5, ExternalID: prefix + "-1", Owner: util.RandomOwner(), Currency: util.EUR, Balance: 5, CreatedAt: createdAt},
+	}
+	result, err := store.ImportAccountsTransaction(ctx, ImportAccountsParams{
+		Import: imp,
+		Rows:   rows,
+		Errors: []ImportRowError{{Line: 3, Message: "owner is empty"}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), result.Import.RowsProcessed)
+	assert.Equal(t, int64(2), result.Import.RowsImported)
+	assert.Equal(t, int64(2), result.Import.RowsFailed)
+	assert.Equal(t, []ImportRowError{
+		{Line: 3, Message: "owner is empty"},
+		{Line: 5, Message: fmt.Sprintf("account %q was already imported", prefix+"-1")},
+	}, result.Errors)
+
+	errs, err := store.ListImportErrors(ctx, ListImportErrorsParams{ImportID: imp.ID, LimitCount: 10})
+	assert.NoError(t, err)
+	assert.Len(t, errs, 2)
+
+	accounts, err := store.ListImportedAccountsForUpdate(ctx, []string{prefix + "-1", prefix + "-2"})
+	assert.NoError(t, err)
+	assert.Len(t, accounts, 2)
+	for _, account := range accounts {
+		assert.Equal(t, createdAt, account.CreatedAt)
+	}
+
+	// the opening balance is an entry, like for OpenAccountTransaction
+	mismatches, err := store.ListBalanceMismatches(ctx, 0.000001)
+	assert.NoError(t, err)
+	for _, mismatch := range mismatches {
+		assert.NotEqual(t, accounts[0].ID, mismatch.ID)
+	}
+	entries, err := store.ListLatestEntries(ctx, ListLatestEntriesParams{AccountID: accounts[0].ID, Limit: 10})
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, float64(100), entries[0].Amount)
+
+	// a run that read the import before the batch was committed cannot commit its own
+	_, err = store.ImportAccountsTransaction(ctx, ImportAccountsParams{
+		Import: imp,
+		Rows:   []ImportAccountRow{{Line: 6, ExternalID: prefix + "-3", Owner: util.RandomOwner(), Currency: util.USD, CreatedAt: createdAt}},
+	})
+	assert.ErrorIs(t, err, ErrImportConflict)
+	ids, err := store.ListImportedExternalIDs(ctx, []string{prefix + "-3"})
+	assert.NoError(t, err)
+	assert.Empty(t, ids)
+}
+
+func TestStore_ImportTransfersTransaction(t *testing.T) {
+	store := NewStore(testDB)
+	ctx := context.Background()
+	prefix := util.RandomString(8)
+	accountsImport := startRandomImport(t, ImportAccounts)
+	_, err := store.ImportAccountsTransaction(ctx, ImportAccountsParams{
+		Import: accountsImport,
+		Rows: []ImportAccountRow{
+			{Line: 2, ExternalID: prefix + "-a", Owner: util.RandomOwner(), Currency: util.USD, Balance: 100, CreatedAt: time.Now().UTC()},
+			{Line: 3, ExternalID: prefix + "-b", Owner: util.RandomOwner(), Currency: util.USD, Balance: 50, CreatedAt: time.Now().UTC()},
+			{Line: 4, ExternalID: prefix + "-c", Owner: util.RandomOwner(), Currency: util.EUR, Balance: 10, CreatedAt: time.Now().UTC()},
+		},
+	})
+	assert.NoError(t, err)
+
+	imp := startRandomImport(t, ImportTransfers)
+	createdAt := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	result, err := store.ImportTransfersTransaction(ctx, ImportTransfersParams{
+		Import: imp,
+		Rows: []ImportTransferRow{
+			{Line: 2, FromExternalID: prefix + "-a", ToExternalID: prefix + "-b", Amount: 30, CreatedAt: createdAt},
+			{Line: 3, FromExternalID: prefix + "-b", ToExternalID: prefix + "-a", Amount: 5, CreatedAt: createdAt},
+			{Line: 4, FromExternalID: prefix + "-a", ToExternalID: prefix + "-c", Amount: 1, CreatedAt: createdAt},
+			{Line: 5, FromExternalID: prefix + "-a", ToExternalID: prefix + "-x", Amount: 1, CreatedAt: createdAt},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), result.Import.RowsProcessed)
+	assert.Equal(t, int64(2), result.Import.RowsImported)
+	assert.Equal(t, []ImportRowError{
+		{Line: 4, Message: fmt.Sprintf("currency mismatch: account %q holds USD, account %q holds EUR", prefix+"-a", prefix+"-c")},
+		{Line: 5, Message: fmt.Sprintf("account %q was not imported", prefix+"-x")},
+	}, result.Errors)
+
+	accounts, err := store.ListImportedAccountsForUpdate(ctx, []string{prefix + "-a", prefix + "-b"})
+	assert.NoError(t, err)
+	balances := make(map[string]float64)
+	for _, account := range accounts {
+		balances[account.ExternalID] = account.Balance
+	}
+	assert.Equal(t, map[string]float64{prefix + "-a": 75, prefix + "-b": 75}, balances)
+
+	// every transfer has its two entries, so the balances match the entries
+	mismatches, err := store.ListBalanceMismatches(ctx, 0.000001)
+	assert.NoError(t, err)
+	for _, mismatch := range mismatches {
+		assert.NotEqual(t, accounts[0].ID, mismatch.ID)
+		assert.NotEqual(t, accounts[1].ID, mismatch.ID)
+	}
+	transfers, err := store.ListTransfers(ctx, ListTransfersParams{
+		FromAccountID: accounts[0].ID,
+		ToAccountID:   accounts[0].ID,
+		LimitCount:    10,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, transfers, 2)
+	for _, transfer := range transfers {
+		assert.Equal(t, createdAt, transfer.CreatedAt)
+		assert.False(t, transfer.ReversesTransferID.Valid)
+	}
+
+	finished, err := store.FinishImport(ctx, imp.ID)
+	assert.NoError(t, err)
+	assert.True(t, finished.FinishedAt.Valid)
+	_, err = store.FinishImport(ctx, imp.ID)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
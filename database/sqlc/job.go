@@ -0,0 +1,10 @@
+package db
+
+// Statuses of jobs. A job is running while a worker holds it, until locked_until, and
+// goes back to pending when it fails with attempts left.
+const (
+	JobPending   = "pending"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+)
@@ -0,0 +1,219 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: job.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+const claimJobs = `-- name: ClaimJobs :many
+WITH exhausted AS (
+    UPDATE jobs
+    SET
+        status = 'failed',
+        locked_until = NULL,
+        last_error = 'timed out on the last attempt',
+        finished_at = now()
+    WHERE status = 'running' AND locked_until < now() AND attempts >= max_attempts
+)
+UPDATE jobs
+SET
+    status = 'running',
+    attempts = attempts + 1,
+    locked_until = $1::timestamptz
+WHERE id IN (
+    SELECT id FROM jobs
+    WHERE (status = 'pending' AND run_at <= now())
+        OR (status = 'running' AND locked_until < now() AND attempts < max_attempts)
+    ORDER BY priority DESC, run_at, id
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, kind, payload, priority, status, unique_key, attempts, max_attempts, run_at, locked_until, last_error, finished_at, created_at
+`
+
+type ClaimJobsParams struct {
+	LockedUntil time.Time `json:"locked_until"`
+	LimitCount  int32     `json:"limit_count"`
+}
+
+// A running job whose worker missed locked_until is claimed again while it has attempts left,
+// and failed otherwise.
+func (q *Queries) ClaimJobs(ctx context.Context, arg ClaimJobsParams) ([]Job, error) {
+	rows, err := q.db.Query(ctx, claimJobs, arg.LockedUntil, arg.LimitCount)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Job{}
+	for rows.Next() {
+		var i Job
+		if err := rows.Scan(
+			&i.ID,
+			&i.Kind,
+			&i.Payload,
+			&i.Priority,
+			&i.Status,
+			&i.UniqueKey,
+			&i.Attempts,
+			&i.MaxAttempts,
+			&i.RunAt,
+			&i.LockedUntil,
+			&i.LastError,
+			&i.FinishedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const completeJob = `-- name: CompleteJob :execrows
+UPDATE jobs
+SET
+    status = 'succeeded',
+    locked_until = NULL,
+    last_error = '',
+    finished_at = now()
+WHERE id = $1 AND status = 'running' AND attempts = $2
+`
+
+type CompleteJobParams struct {
+	ID       int64 `json:"id"`
+	Attempts int32 `json:"attempts"`
+}
+
+func (q *Queries) CompleteJob(ctx context.Context, arg CompleteJobParams) (int64, error) {
+	result, err := q.db.Exec(ctx, completeJob, arg.ID, arg.Attempts)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const enqueueJob = `-- name: EnqueueJob :one
+INSERT INTO jobs (
+    kind,
+    payload,
+    priority,
+    unique_key,
+    max_attempts,
+    run_at
+) VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    coalesce($6::timestamptz, now())
+)
+ON CONFLICT (kind, unique_key) WHERE status IN ('pending', 'running') DO NOTHING
+RETURNING id, kind, payload, priority, status, unique_key, attempts, max_attempts, run_at, locked_until, last_error, finished_at, created_at
+`
+
+type EnqueueJobParams struct {
+	Kind        string          `json:"kind"`
+	Payload     json.RawMessage `json:"payload"`
+	Priority    int32           `json:"priority"`
+	UniqueKey   sql.NullString  `json:"unique_key"`
+	MaxAttempts int32           `json:"max_attempts"`
+	RunAt       sql.NullTime    `json:"run_at"`
+}
+
+func (q *Queries) EnqueueJob(ctx context.Context, arg EnqueueJobParams) (Job, error) {
+	row := q.db.QueryRow(ctx, enqueueJob,
+		arg.Kind,
+		arg.Payload,
+		arg.Priority,
+		arg.UniqueKey,
+		arg.MaxAttempts,
+		arg.RunAt,
+	)
+	var i Job
+	err := row.Scan(
+		&i.ID,
+		&i.Kind,
+		&i.Payload,
+		&i.Priority,
+		&i.Status,
+		&i.UniqueKey,
+		&i.Attempts,
+		&i.MaxAttempts,
+		&i.RunAt,
+		&i.LockedUntil,
+		&i.LastError,
+		&i.FinishedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const failJob = `-- name: FailJob :execrows
+UPDATE jobs
+SET
+    status = $1,
+    run_at = $2,
+    locked_until = NULL,
+    last_error = $3,
+    finished_at = CASE WHEN $1 = 'failed' THEN now() END
+WHERE id = $4 AND status = 'running' AND attempts = $5
+`
+
+type FailJobParams struct {
+	Status    string    `json:"status"`
+	RunAt     time.Time `json:"run_at"`
+	LastError string    `json:"last_error"`
+	ID        int64     `json:"id"`
+	Attempts  int32     `json:"attempts"`
+}
+
+func (q *Queries) FailJob(ctx context.Context, arg FailJobParams) (int64, error) {
+	result, err := q.db.Exec(ctx, failJob,
+		arg.Status,
+		arg.RunAt,
+		arg.LastError,
+		arg.ID,
+		arg.Attempts,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const getJob = `-- name: GetJob :one
+SELECT id, kind, payload, priority, status, unique_key, attempts, max_attempts, run_at, locked_until, last_error, finished_at, created_at FROM jobs
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetJob(ctx context.Context, id int64) (Job, error) {
+	row := q.db.QueryRow(ctx, getJob, id)
+	var i Job
+	err := row.Scan(
+		&i.ID,
+		&i.Kind,
+		&i.Payload,
+		&i.Priority,
+		&i.Status,
+		&i.UniqueKey,
+		&i.Attempts,
+		&i.MaxAttempts,
+		&i.RunAt,
+		&i.LockedUntil,
+		&i.LastError,
+		&i.FinishedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
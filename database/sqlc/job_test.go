@@ -0,0 +1,142 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/arpangoswami/backend-golang-dev/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func enqueueRandomJob(t *testing.T, kind string, priority int32) Job {
+	job, err := testQueries.EnqueueJob(context.Background(), EnqueueJobParams{
+		Kind:        kind,
+		Payload:     json.RawMessage(`{}`),
+		Priority:    priority,
+		MaxAttempts: 3,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, JobPending, job.Status)
+	return job
+}
+
+// claimJobsOfKind claims every due job and keeps the ones of kind
+func claimJobsOfKind(t *testing.T, kind string, lockedUntil time.Time) []Job {
+	jobs, err := testQueries.ClaimJobs(context.Background(), ClaimJobsParams{LockedUntil: lockedUntil, LimitCount: 1000})
+	assert.NoError(t, err)
+	var claimed []Job
+	for _, job := range jobs {
+		if job.Kind == kind {
+			claimed = append(claimed, job)
+		}
+	}
+	return claimed
+}
+
+func TestQueries_EnqueueJobUniqueKey(t *testing.T) {
+	ctx := context.Background()
+	arg := EnqueueJobParams{
+		Kind:        "test_" + util.RandomString(8),
+		Payload:     json.RawMessage(`{}`),
+		UniqueKey:   sql.NullString{String: "key", Valid: true},
+		MaxAttempts: 3,
+		RunAt:       sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true},
+	}
+	job, err := testQueries.EnqueueJob(ctx, arg)
+	assert.NoError(t, err)
+	assert.WithinDuration(t, arg.RunAt.Time, job.RunAt, time.Second)
+
+	_, err = testQueries.EnqueueJob(ctx, arg)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	// the key is free again once the job finished
+	_, err = testDB.Exec(context.Background(), "UPDATE jobs SET status = $1 WHERE id = $2", JobSucceeded, job.ID)
+	assert.NoError(t, err)
+	_, err = testQueries.EnqueueJob(ctx, arg)
+	assert.NoError(t, err)
+}
+
+func TestQueries_ClaimJobs(t *testing.T) {
+	ctx := context.Background()
+	kind := "test_" + util.RandomString(8)
+	low := enqueueRandomJob(t, kind, 0)
+	high := enqueueRandomJob(t, kind, 10)
+
+	claimed := claimJobsOfKind(t, kind, time.Now().Add(time.Minute))
+	if assert.Len(t, claimed, 2) {
+		for _, job := range claimed {
+			assert.Equal(t, JobRunning, job.Status)
+			assert.Equal(t, int32(1), job.Attempts)
+		}
+	}
+	// running jobs are not claimed again before their lock expires
+	assert.Empty(t, claimJobsOfKind(t, kind, time.Now().Add(time.Minute)))
+
+	rows, err := testQueries.CompleteJob(ctx, CompleteJobParams{ID: high.ID, Attempts: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), rows)
+
+	// a job whose worker missed the visibility timeout is claimed again, and the late
+	// worker can't record its outcome anymore
+	_, err = testDB.Exec(context.Background(), "UPDATE jobs SET locked_until = now() - interval '1 second' WHERE id = $1", low.ID)
+	assert.NoError(t, err)
+	claimed = claimJobsOfKind(t, kind, time.Now().Add(time.Minute))
+	if assert.Len(t, claimed, 1) {
+		assert.Equal(t, low.ID, claimed[0].ID)
+		assert.Equal(t, int32(2), claimed[0].Attempts)
+	}
+	rows, err = testQueries.FailJob(ctx, FailJobParams{ID: low.ID, Attempts: 1, Status: JobPending, RunAt: time.Now()})
+	assert.NoError(t, err)
+	assert.Zero(t, rows)
+
+	rows, err = testQueries.FailJob(ctx, FailJobParams{ID: low.ID, Attempts: 2, Status: JobFailed, RunAt: time.Now(), LastError: "boom"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), rows)
+	failed, err := testQueries.GetJob(ctx, low.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, JobFailed, failed.Status)
+	assert.True(t, failed.FinishedAt.Valid)
+	assert.False(t, failed.LockedUntil.Valid)
+}
+
+func TestQueries_ClaimJobsExhausted(t *testing.T) {
+	ctx := context.Background()
+	kind := "test_" + util.RandomString(8)
+	job, err := testQueries.EnqueueJob(ctx, EnqueueJobParams{
+		Kind:        kind,
+		Payload:     json.RawMessage(`{}`),
+		MaxAttempts: 1,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, claimJobsOfKind(t, kind, time.Now().Add(time.Minute)), 1)
+
+	// a job that timed out on its last attempt fails instead of being claimed again
+	_, err = testDB.Exec(ctx, "UPDATE jobs SET locked_until = now() - interval '1 second' WHERE id = $1", job.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, claimJobsOfKind(t, kind, time.Now().Add(time.Minute)))
+
+	failed, err := testQueries.GetJob(ctx, job.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, JobFailed, failed.Status)
+	assert.Equal(t, int32(1), failed.Attempts)
+	assert.NotEmpty(t, failed.LastError)
+	assert.True(t, failed.FinishedAt.Valid)
+	assert.False(t, failed.LockedUntil.Valid)
+}
+
+func TestQueries_ClaimJobsPriority(t *testing.T) {
+	kind := "test_" + util.RandomString(8)
+	low := enqueueRandomJob(t, kind, -100)
+	high := enqueueRandomJob(t, kind, 100)
+
+	// the highest priority due job is claimed first
+	jobs, err := testQueries.ClaimJobs(context.Background(), ClaimJobsParams{LockedUntil: time.Now().Add(time.Minute), LimitCount: 1})
+	assert.NoError(t, err)
+	if assert.Len(t, jobs, 1) {
+		assert.NotEqual(t, low.ID, jobs[0].ID)
+		assert.GreaterOrEqual(t, jobs[0].Priority, high.Priority)
+	}
+}
@@ -0,0 +1,59 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: ledger.sql
+
+package db
+
+import (
+	"context"
+)
+
+const createMonthlyPartitions = `-- name: CreateMonthlyPartitions :one
+SELECT create_monthly_partitions(
+    $1::text,
+    localtimestamp,
+    localtimestamp + make_interval(months => $2::integer)
+)::integer AS created
+`
+
+type CreateMonthlyPartitionsParams struct {
+	TableName   string `json:"table_name"`
+	MonthsAhead int32  `json:"months_ahead"`
+}
+
+func (q *Queries) CreateMonthlyPartitions(ctx context.Context, arg CreateMonthlyPartitionsParams) (int32, error) {
+	row := q.db.QueryRow(ctx, createMonthlyPartitions, arg.TableName, arg.MonthsAhead)
+	var created int32
+	err := row.Scan(&created)
+	return created, err
+}
+
+const hasLedger = `-- name: HasLedger :one
+SELECT EXISTS (
+    SELECT 1 FROM accounts
+    UNION ALL SELECT 1 FROM entries
+    UNION ALL SELECT 1 FROM transfers
+    UNION ALL SELECT 1 FROM entries_archive
+    UNION ALL SELECT 1 FROM transfers_archive
+) AS has_ledger
+`
+
+func (q *Queries) HasLedger(ctx context.Context) (bool, error) {
+	row := q.db.QueryRow(ctx, hasLedger)
+	var has_ledger bool
+	err := row.Scan(&has_ledger)
+	return has_ledger, err
+}
+
+const resetLedgerSequences = `-- name: ResetLedgerSequences :exec
+SELECT
+    setval(pg_get_serial_sequence('accounts', 'id'), coalesce((SELECT max(id) FROM accounts), 0) + 1, false),
+    setval(pg_get_serial_sequence('entries', 'id'), coalesce((SELECT max(id) FROM entries), 0) + 1, false),
+    setval(pg_get_serial_sequence('transfers', 'id'), coalesce((SELECT max(id) FROM transfers), 0) + 1, false)
+`
+
+func (q *Queries) ResetLedgerSequences(ctx context.Context) error {
+	_, err := q.db.Exec(ctx, resetLedgerSequences)
+	return err
+}
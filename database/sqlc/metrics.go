@@ -0,0 +1,181 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Transaction outcomes counted by Metrics
+const (
+	TxCommit   = "commit"
+	TxRollback = "rollback"
+	TxRetry    = "retry"
+)
+
+// Metrics holds the Prometheus collectors of a Store, see WithMetrics. The stats of the
+// connection pool are collected separately, see NewPoolStatsCollector.
+type Metrics struct {
+	queryDuration  *prometheus.HistogramVec
+	queryErrors    *prometheus.CounterVec
+	transactions   *prometheus.CounterVec
+	transferAmount *prometheus.HistogramVec
+}
+
+// NewMetrics creates the collectors and registers them with registerer, which panics if
+// they are registered twice. Tests pass their own prometheus.NewRegistry().
+func NewMetrics(registerer prometheus.Registerer) *Metrics {
+	metrics := &Metrics{
+		queryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "bank",
+			Subsystem: "db",
+			Name:      "query_duration_seconds",
+			Help:      "Duration of the queries by sqlc query name.",
+			Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 14),
+		}, []string{"query"}),
+		queryErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "bank",
+			Subsystem: "db",
+			Name:      "query_errors_total",
+			Help:      "Queries that failed by sqlc query name, not counting those finding no rows.",
+		}, []string{"query"}),
+		transactions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "bank",
+			Subsystem: "db",
+			Name:      "transactions_total",
+			Help:      "Transactions by outcome: commit, rollback, or retry after a serialization failure or deadlock.",
+		}, []string{"outcome"}),
+		transferAmount: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "bank",
+			Name:      "transfer_amount",
+			Help:      "Amounts of the transfers made by TransferTransaction by currency, the count being their volume.",
+			Buckets:   prometheus.ExponentialBuckets(1, 10, 7),
+		}, []string{"currency"}),
+	}
+	registerer.MustRegister(metrics.queryDuration, metrics.queryErrors, metrics.transactions, metrics.transferAmount)
+	return metrics
+}
+
+// WithMetrics measures every query (see NewMetricsDBTX), the outcome of the txns and the
+// transfers made by TransferTransaction
+func WithMetrics(metrics *Metrics) StoreOption {
+	return func(store *SQLStore) {
+		store.metrics = metrics
+	}
+}
+
+// observeQuery records a query run by name, a nil metrics records nothing
+func (metrics *Metrics) observeQuery(name string, start time.Time, err error) {
+	if metrics == nil {
+		return
+	}
+	metrics.queryDuration.WithLabelValues(name).Observe(time.Since(start).Seconds())
+	if failed(err) {
+		metrics.queryErrors.WithLabelValues(name).Inc()
+	}
+}
+
+// observeTransaction records the outcome of a txn
+func (metrics *Metrics) observeTransaction(outcome string) {
+	if metrics == nil {
+		return
+	}
+	metrics.transactions.WithLabelValues(outcome).Inc()
+}
+
+// observeTransfer records a transfer in the currency of the account it was made from
+func (metrics *Metrics) observeTransfer(result TransferTransactionResult) {
+	if metrics == nil {
+		return
+	}
+	metrics.transferAmount.WithLabelValues(result.FromAccount.Currency).Observe(result.Transfer.Amount)
+}
+
+// metricsDBTX times every query
+type metricsDBTX struct {
+	dbtx    DBTX
+	metrics *Metrics
+}
+
+// NewMetricsDBTX wraps dbtx so that the duration and failures of every query are recorded
+// under the name of the sqlc query, see QueryName. The rows of a query are read within its duration.
+func NewMetricsDBTX(dbtx DBTX, metrics *Metrics) DBTX {
+	return &metricsDBTX{dbtx: dbtx, metrics: metrics}
+}
+
+func (m *metricsDBTX) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	return execute(ctx, m.dbtx, sql, args, m.start(sql))
+}
+
+func (m *metricsDBTX) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	return query(ctx, m.dbtx, sql, args, m.start(sql))
+}
+
+func (m *metricsDBTX) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	return queryRow(ctx, m.dbtx, sql, args, m.start(sql))
+}
+
+func (m *metricsDBTX) CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error) {
+	return copyFrom(ctx, m.dbtx, tableName, columnNames, rowSrc, m.start(copyStatement(tableName, columnNames)))
+}
+
+// start times a query, the returned func records it
+func (m *metricsDBTX) start(query string) finishFunc {
+	start := time.Now()
+	return func(rows int64, err error) {
+		m.metrics.observeQuery(QueryName(query), start, err)
+	}
+}
+
+// poolStatsCollector exports the stats of a connection pool
+type poolStatsCollector struct {
+	pool *pgxpool.Pool
+
+	acquiredConns        *prometheus.Desc
+	idleConns            *prometheus.Desc
+	totalConns           *prometheus.Desc
+	maxConns             *prometheus.Desc
+	acquireCount         *prometheus.Desc
+	acquireDuration      *prometheus.Desc
+	emptyAcquireCount    *prometheus.Desc
+	canceledAcquireCount *prometheus.Desc
+}
+
+// NewPoolStatsCollector returns a collector of the stats of pool, labelled with name as there may be
+// several pools, e.g. the primary and the replicas
+func NewPoolStatsCollector(pool *pgxpool.Pool, name string) prometheus.Collector {
+	desc := func(metric, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName("bank", "db_pool", metric), help, nil, prometheus.Labels{"pool": name})
+	}
+	return &poolStatsCollector{
+		pool:                 pool,
+		acquiredConns:        desc("acquired_conns", "Connections currently in use."),
+		idleConns:            desc("idle_conns", "Connections currently idle."),
+		totalConns:           desc("total_conns", "Connections currently open, including those being opened."),
+		maxConns:             desc("max_conns", "Maximum size of the pool."),
+		acquireCount:         desc("acquires_total", "Connections acquired from the pool."),
+		acquireDuration:      desc("acquire_duration_seconds_total", "Time spent acquiring connections."),
+		emptyAcquireCount:    desc("empty_acquires_total", "Acquires that waited for a connection as none was idle."),
+		canceledAcquireCount: desc("canceled_acquires_total", "Acquires cancelled by their context."),
+	}
+}
+
+func (c *poolStatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, ch)
+}
+
+func (c *poolStatsCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := c.pool.Stat()
+	ch <- prometheus.MustNewConstMetric(c.acquiredConns, prometheus.GaugeValue, float64(stat.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(c.idleConns, prometheus.GaugeValue, float64(stat.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(c.totalConns, prometheus.GaugeValue, float64(stat.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(c.maxConns, prometheus.GaugeValue, float64(stat.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(c.acquireCount, prometheus.CounterValue, float64(stat.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.acquireDuration, prometheus.CounterValue, stat.AcquireDuration().Seconds())
+	ch <- prometheus.MustNewConstMetric(c.emptyAcquireCount, prometheus.CounterValue, float64(stat.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.canceledAcquireCount, prometheus.CounterValue, float64(stat.CanceledAcquireCount()))
+}
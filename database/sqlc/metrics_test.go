@@ -0,0 +1,62 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsRetryable(t *testing.T) {
+	assert.True(t, isRetryable(&pgconn.PgError{Code: "40001"}))
+	assert.True(t, isRetryable(fmt.Errorf("transfer: %w", &pgconn.PgError{Code: "40P01"})))
+	assert.False(t, isRetryable(&pgconn.PgError{Code: "23505"}))
+	assert.False(t, isRetryable(errors.New("failure")))
+	assert.False(t, isRetryable(nil))
+}
+
+// sampleCount returns the number of observations of a histogram
+func sampleCount(t *testing.T, observer prometheus.Observer) uint64 {
+	var metric dto.Metric
+	assert.NoError(t, observer.(prometheus.Metric).Write(&metric))
+	return metric.GetHistogram().GetSampleCount()
+}
+
+func TestStore_Metrics(t *testing.T) {
+	metrics := NewMetrics(prometheus.NewRegistry())
+	store := NewStore(testDB, WithMetrics(metrics))
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	_, err := store.TransferTransaction(context.Background(), TransferTransactionParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.transactions.WithLabelValues(TxCommit)))
+	assert.Equal(t, uint64(1), sampleCount(t, metrics.transferAmount.WithLabelValues(account1.Currency)))
+	assert.Equal(t, uint64(1), sampleCount(t, metrics.queryDuration.WithLabelValues("CreateTransfer")))
+	assert.Equal(t, uint64(2), sampleCount(t, metrics.queryDuration.WithLabelValues("GetAccountForUpdate")))
+
+	_, err = store.GetAccount(context.Background(), -1)
+	assert.Error(t, err)
+	assert.Zero(t, testutil.CollectAndCount(metrics.queryErrors), "no rows is not an error")
+
+	err = store.ExecTransaction(context.Background(), func(tx Store) error {
+		_, err := tx.AddAccountBalance(context.Background(), AddAccountBalanceParams{ID: account1.ID, Amount: 1})
+		if err != nil {
+			return err
+		}
+		return errors.New("failure")
+	})
+	assert.Error(t, err)
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.transactions.WithLabelValues(TxRollback)))
+}
@@ -6,7 +6,10 @@ package db
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 type Account struct {
@@ -16,6 +19,36 @@ type Account struct {
 	Currency    string        `json:"currency"`
 	CreatedAt   time.Time     `json:"created_at"`
 	CountryCode sql.NullInt32 `json:"country_code"`
+	IsFrozen    bool          `json:"is_frozen"`
+}
+
+// Append-only, rows cannot be updated or deleted
+type AuditLog struct {
+	ID         int64           `json:"id"`
+	Actor      string          `json:"actor"`
+	Action     string          `json:"action"`
+	EntityType string          `json:"entity_type"`
+	EntityID   int64           `json:"entity_id"`
+	Before     json.RawMessage `json:"before"`
+	After      json.RawMessage `json:"after"`
+	RequestID  string          `json:"request_id"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+type BalanceCheckpoint struct {
+	AccountID int64 `json:"account_id"`
+	// Sum of the archived entries: the balance is this plus the sum of the entries left
+	Balance         float64   `json:"balance"`
+	ArchivedEntries int64     `json:"archived_entries"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// Entries moved out of entries once older than the retention, see balance_checkpoints
+type EntriesArchive struct {
+	ID        int64     `json:"id"`
+	AccountID int64     `json:"account_id"`
+	Amount    float64   `json:"amount"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 type Entry struct {
@@ -26,6 +59,74 @@ type Entry struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
+type Import struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	// accounts or transfers, the kind of CSV file imported
+	Kind string `json:"kind"`
+	// CSV records committed, valid or not; an interrupted import resumes after them
+	RowsProcessed int64        `json:"rows_processed"`
+	RowsImported  int64        `json:"rows_imported"`
+	RowsFailed    int64        `json:"rows_failed"`
+	FinishedAt    sql.NullTime `json:"finished_at"`
+	CreatedAt     time.Time    `json:"created_at"`
+}
+
+type ImportError struct {
+	ID       int64  `json:"id"`
+	ImportID int64  `json:"import_id"`
+	Line     int64  `json:"line"`
+	Message  string `json:"message"`
+}
+
+type ImportedAccount struct {
+	// The account id in the system the account was imported from
+	ExternalID string `json:"external_id"`
+	AccountID  int64  `json:"account_id"`
+	ImportID   int64  `json:"import_id"`
+}
+
+type Job struct {
+	ID       int64           `json:"id"`
+	Kind     string          `json:"kind"`
+	Payload  json.RawMessage `json:"payload"`
+	Priority int32           `json:"priority"`
+	// pending, running, succeeded or failed once the attempts are exhausted
+	Status string `json:"status"`
+	// At most one pending or running job of a kind has the same key
+	UniqueKey   sql.NullString `json:"unique_key"`
+	Attempts    int32          `json:"attempts"`
+	MaxAttempts int32          `json:"max_attempts"`
+	RunAt       time.Time      `json:"run_at"`
+	// A running job is claimed again once its worker missed this deadline
+	LockedUntil sql.NullTime `json:"locked_until"`
+	LastError   string       `json:"last_error"`
+	FinishedAt  sql.NullTime `json:"finished_at"`
+	CreatedAt   time.Time    `json:"created_at"`
+}
+
+type OutboxEvent struct {
+	ID            int64           `json:"id"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   int64           `json:"aggregate_id"`
+	EventType     string          `json:"event_type"`
+	Payload       json.RawMessage `json:"payload"`
+	CreatedAt     time.Time       `json:"created_at"`
+	// Set by the relay once the event was handed to the publisher
+	PublishedAt sql.NullTime `json:"published_at"`
+}
+
+type Session struct {
+	ID           uuid.UUID `json:"id"`
+	Username     string    `json:"username"`
+	RefreshToken string    `json:"refresh_token"`
+	UserAgent    string    `json:"user_agent"`
+	ClientIp     string    `json:"client_ip"`
+	IsRevoked    bool      `json:"is_revoked"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
 type Transfer struct {
 	ID            int64 `json:"id"`
 	FromAccountID int64 `json:"from_account_id"`
@@ -33,4 +134,51 @@ type Transfer struct {
 	// Must be positive
 	Amount    float64   `json:"amount"`
 	CreatedAt time.Time `json:"created_at"`
+	// Set on the transfer moving the money of a reversed transfer back
+	ReversesTransferID sql.NullInt64 `json:"reverses_transfer_id"`
+}
+
+// Transfers moved out of transfers once older than the retention
+type TransfersArchive struct {
+	ID                 int64         `json:"id"`
+	FromAccountID      int64         `json:"from_account_id"`
+	ToAccountID        int64         `json:"to_account_id"`
+	Amount             float64       `json:"amount"`
+	CreatedAt          time.Time     `json:"created_at"`
+	ReversesTransferID sql.NullInt64 `json:"reverses_transfer_id"`
+}
+
+type User struct {
+	Username          string    `json:"username"`
+	HashedPassword    string    `json:"hashed_password"`
+	FullName          string    `json:"full_name"`
+	Email             string    `json:"email"`
+	PasswordChangedAt time.Time `json:"password_changed_at"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+type WebhookDelivery struct {
+	ID             int64 `json:"id"`
+	SubscriptionID int64 `json:"subscription_id"`
+	// The outbox event fanned out to the subscription
+	EventID   int64           `json:"event_id"`
+	EventType string          `json:"event_type"`
+	Payload   json.RawMessage `json:"payload"`
+	// pending, succeeded or dead once the attempts are exhausted
+	Status           string       `json:"status"`
+	Attempts         int32        `json:"attempts"`
+	NextAttemptAt    time.Time    `json:"next_attempt_at"`
+	LastResponseCode int32        `json:"last_response_code"`
+	LastError        string       `json:"last_error"`
+	DeliveredAt      sql.NullTime `json:"delivered_at"`
+	CreatedAt        time.Time    `json:"created_at"`
+}
+
+type WebhookSubscription struct {
+	ID         int64     `json:"id"`
+	Owner      string    `json:"owner"`
+	Url        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	Secret     string    `json:"secret"`
+	CreatedAt  time.Time `json:"created_at"`
 }
@@ -0,0 +1,97 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Domain events written to outbox_events.event_type. Account events carry the
+// account as payload, transfer events the TransferTransactionResult.
+const (
+	EventAccountCreated        = "account.created"
+	EventAccountBalanceChanged = "account.balance_changed"
+	EventAccountFrozen         = "account.frozen"
+	EventAccountUnfrozen       = "account.unfrozen"
+	EventAccountDeleted        = "account.deleted"
+	EventTransferCompleted     = "transfer.completed"
+	EventTransferReversed      = "transfer.reversed"
+)
+
+// EventTypes lists every domain event, e.g. to validate webhook subscriptions
+var EventTypes = []string{
+	EventAccountCreated,
+	EventAccountBalanceChanged,
+	EventAccountFrozen,
+	EventAccountUnfrozen,
+	EventAccountDeleted,
+	EventTransferCompleted,
+	EventTransferReversed,
+}
+
+// IsEventType reports whether eventType is one of EventTypes
+func IsEventType(eventType string) bool {
+	return slices.Contains(EventTypes, eventType)
+}
+
+// emit appends an event to the outbox inside the caller's txn, so that it is
+// relayed if and only if the change it describes is committed
+func emit(ctx context.Context, q *Queries, aggregateType string, aggregateID int64, eventType string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshal %s event: %w", eventType, err)
+	}
+	_, err = q.CreateOutboxEvent(ctx, CreateOutboxEventParams{
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		EventType:     eventType,
+		Payload:       data,
+	})
+	return err
+}
+
+type aggregate struct {
+	typ string
+	id  int64
+}
+
+// RelayOutboxTransaction hands up to limit unpublished events to publish, oldest first, and
+// marks the delivered ones as published within a single db txn. The claimed rows stay locked
+// until the txn ends, so concurrent relays cannot reorder them. Once an event fails, the later
+// events of its aggregate are left for the next call; the failures are returned together with
+// the number of published events. An event may be published again if the commit fails.
+func (store *SQLStore) RelayOutboxTransaction(ctx context.Context, limit int32, publish func(context.Context, OutboxEvent) error) (int, error) {
+	var published []int64
+	var errs []error
+	err := store.executeTransaction(ctx, func(q *Queries) error {
+		events, err := q.ListUnpublishedOutboxEventsForUpdate(ctx, limit)
+		if err != nil {
+			return err
+		}
+
+		failed := make(map[aggregate]bool)
+		for _, event := range events {
+			key := aggregate{typ: event.AggregateType, id: event.AggregateID}
+			if failed[key] {
+				continue
+			}
+			if err := publish(ctx, event); err != nil {
+				failed[key] = true
+				errs = append(errs, fmt.Errorf("event [%d] %s: %w", event.ID, event.EventType, err))
+				continue
+			}
+			published = append(published, event.ID)
+		}
+
+		if len(published) == 0 {
+			return nil
+		}
+		return q.MarkOutboxEventsPublished(ctx, published)
+	})
+	if err != nil {
+		return 0, errors.Join(append(errs, err)...)
+	}
+	return len(published), errors.Join(errs...)
+}
@@ -0,0 +1,92 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueries_CreateMonthlyPartitions(t *testing.T) {
+	ctx := context.Background()
+	for _, table := range []string{"entries", "transfers"} {
+		_, err := testQueries.CreateMonthlyPartitions(ctx, CreateMonthlyPartitionsParams{TableName: table, MonthsAhead: 2})
+		assert.NoError(t, err)
+
+		// the partitions exist now
+		created, err := testQueries.CreateMonthlyPartitions(ctx, CreateMonthlyPartitionsParams{TableName: table, MonthsAhead: 2})
+		assert.NoError(t, err)
+		assert.Zero(t, created)
+	}
+}
+
+func TestQueries_CreatedAtDefault(t *testing.T) {
+	ctx := context.Background()
+	from, to := createRandomAccount(t), createRandomAccount(t)
+
+	// created_at is left to its default, which must be the time of the insert and not a constant
+	entry, err := testQueries.CreateEntry(ctx, CreateEntryParams{AccountID: from.ID, Amount: 1})
+	require.NoError(t, err)
+	transfer, err := testQueries.CreateTransfer(ctx, CreateTransferParams{FromAccountID: from.ID, ToAccountID: to.ID, Amount: 1})
+	require.NoError(t, err)
+
+	var now time.Time
+	require.NoError(t, testDB.QueryRow(ctx, "SELECT localtimestamp").Scan(&now))
+	assert.WithinDuration(t, now, entry.CreatedAt, time.Minute)
+	assert.WithinDuration(t, now, transfer.CreatedAt, time.Minute)
+	assert.WithinDuration(t, now, from.CreatedAt, time.Minute)
+}
+
+func TestPartitionPruning(t *testing.T) {
+	ctx := context.Background()
+	for _, table := range []string{"entries", "transfers"} {
+		_, err := testQueries.CreateMonthlyPartitions(ctx, CreateMonthlyPartitionsParams{TableName: table, MonthsAhead: 2})
+		assert.NoError(t, err)
+	}
+
+	// next month, whose partition was created ahead
+	var now time.Time
+	assert.NoError(t, testDB.QueryRow(ctx, "SELECT localtimestamp").Scan(&now))
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, 1, 0)
+	end := start.AddDate(0, 1, 0)
+	startTime := sql.NullTime{Time: start, Valid: true}
+	endTime := sql.NullTime{Time: end, Valid: true}
+
+	plan, err := explain(ctx, testDB, listEntriesAfter, []interface{}{
+		[]int64{1, 2}, "all", startTime, endTime, sql.NullFloat64{}, sql.NullFloat64{}, start, int64(0), int32(10),
+	})
+	assert.NoError(t, err)
+	assert.Contains(t, plan, "entries_p"+start.Format("200601"))
+	assert.NotContains(t, plan, "entries_p"+now.Format("200601"))
+	assert.NotContains(t, plan, "entries_default")
+
+	plan, err = explain(ctx, testDB, listTransfersAfter, []interface{}{
+		"all", int64(1), startTime, endTime, sql.NullFloat64{}, sql.NullFloat64{}, start, int64(0), int32(10),
+	})
+	assert.NoError(t, err)
+	assert.Contains(t, plan, "transfers_p"+start.Format("200601"))
+	assert.NotContains(t, plan, "transfers_p"+now.Format("200601"))
+	assert.NotContains(t, plan, "transfers_default")
+
+	// the offset listings prune the same way once bounded
+	plan, err = explain(ctx, testDB, listEntries, []interface{}{[]int64{1}, startTime, endTime, int32(0), int32(10)})
+	assert.NoError(t, err)
+	assert.Contains(t, plan, "entries_p"+start.Format("200601"))
+	assert.NotContains(t, plan, "entries_p"+now.Format("200601"))
+	assert.NotContains(t, plan, "entries_default")
+
+	plan, err = explain(ctx, testDB, listTransfers, []interface{}{int64(1), int64(1), startTime, endTime, int32(0), int32(10)})
+	assert.NoError(t, err)
+	assert.Contains(t, plan, "transfers_p"+start.Format("200601"))
+	assert.NotContains(t, plan, "transfers_p"+now.Format("200601"))
+	assert.NotContains(t, plan, "transfers_default")
+
+	// without a range of created_at, every partition is read with its account_id index
+	plan, err = explain(ctx, testDB, listEntries, []interface{}{[]int64{1}, sql.NullTime{}, sql.NullTime{}, int32(0), int32(10)})
+	assert.NoError(t, err)
+	assert.Contains(t, plan, "entries_default")
+	assert.Contains(t, plan, "entries_p"+start.Format("200601"))
+}
@@ -6,21 +6,104 @@ package db
 
 import (
 	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
 )
 
 type Querier interface {
+	AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error)
+	AddAccountBalances(ctx context.Context, arg AddAccountBalancesParams) ([]Account, error)
+	AdvanceImport(ctx context.Context, arg AdvanceImportParams) (Import, error)
+	ArchiveEntries(ctx context.Context, arg ArchiveEntriesParams) (int64, error)
+	ArchiveTransfers(ctx context.Context, arg ArchiveTransfersParams) (int64, error)
+	// A running job whose worker missed locked_until is claimed again while it has attempts left,
+	// and failed otherwise.
+	ClaimJobs(ctx context.Context, arg ClaimJobsParams) ([]Job, error)
+	ClaimWebhookDeliveries(ctx context.Context, arg ClaimWebhookDeliveriesParams) ([]WebhookDelivery, error)
+	CompleteJob(ctx context.Context, arg CompleteJobParams) (int64, error)
+	CopyAccounts(ctx context.Context, arg []CopyAccountsParams) (int64, error)
+	CopyAuditLogs(ctx context.Context, arg []CopyAuditLogsParams) (int64, error)
+	CopyEntries(ctx context.Context, arg []CopyEntriesParams) (int64, error)
+	CopyImportErrors(ctx context.Context, arg []CopyImportErrorsParams) (int64, error)
+	CopyImportedAccounts(ctx context.Context, arg []CopyImportedAccountsParams) (int64, error)
+	CopyTransfers(ctx context.Context, arg []CopyTransfersParams) (int64, error)
 	CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error)
+	CreateAuditLog(ctx context.Context, arg CreateAuditLogParams) (AuditLog, error)
 	CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error)
+	CreateMonthlyPartitions(ctx context.Context, arg CreateMonthlyPartitionsParams) (int32, error)
+	CreateOutboxEvent(ctx context.Context, arg CreateOutboxEventParams) (OutboxEvent, error)
+	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
 	CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error)
+	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	CreateWebhookDelivery(ctx context.Context, arg CreateWebhookDeliveryParams) error
+	CreateWebhookSubscription(ctx context.Context, arg CreateWebhookSubscriptionParams) (WebhookSubscription, error)
 	DeleteAccount(ctx context.Context, id int64) error
 	DeleteEntry(ctx context.Context, id int64) error
 	DeleteTransfer(ctx context.Context, id int64) error
+	DeleteWebhookSubscription(ctx context.Context, arg DeleteWebhookSubscriptionParams) (WebhookSubscription, error)
+	EnqueueJob(ctx context.Context, arg EnqueueJobParams) (Job, error)
+	ExportAccounts(ctx context.Context, arg ExportAccountsParams) ([]Account, error)
+	ExportEntries(ctx context.Context, arg ExportEntriesParams) ([]Entry, error)
+	ExportTransfers(ctx context.Context, arg ExportTransfersParams) ([]Transfer, error)
+	FailJob(ctx context.Context, arg FailJobParams) (int64, error)
+	FinishImport(ctx context.Context, id int64) (Import, error)
 	GetAccount(ctx context.Context, id int64) (Account, error)
+	GetAccountForUpdate(ctx context.Context, id int64) (Account, error)
+	GetArchivedTransfer(ctx context.Context, id int64) (TransfersArchive, error)
+	GetBalanceCheckpoint(ctx context.Context, accountID int64) (BalanceCheckpoint, error)
 	GetEntry(ctx context.Context, id int64) (Entry, error)
+	GetImport(ctx context.Context, name string) (Import, error)
+	GetJob(ctx context.Context, id int64) (Job, error)
+	GetOrCreateImport(ctx context.Context, arg GetOrCreateImportParams) (Import, error)
+	GetSession(ctx context.Context, id uuid.UUID) (Session, error)
 	GetTransfer(ctx context.Context, id int64) (Transfer, error)
+	GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error)
+	GetTransferReversal(ctx context.Context, reversesTransferID sql.NullInt64) (Transfer, error)
+	GetUser(ctx context.Context, username string) (User, error)
+	GetWebhookDelivery(ctx context.Context, id int64) (WebhookDelivery, error)
+	GetWebhookSubscription(ctx context.Context, id int64) (WebhookSubscription, error)
+	HasLedger(ctx context.Context) (bool, error)
 	ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error)
+	ListAccountsByOwner(ctx context.Context, arg ListAccountsByOwnerParams) ([]Account, error)
+	ListAccountsByOwnerAfter(ctx context.Context, arg ListAccountsByOwnerAfterParams) ([]Account, error)
+	ListAccountsByOwnerBefore(ctx context.Context, arg ListAccountsByOwnerBeforeParams) ([]Account, error)
+	ListAuditLogs(ctx context.Context, arg ListAuditLogsParams) ([]AuditLog, error)
+	ListBalanceMismatches(ctx context.Context, tolerance float64) ([]ListBalanceMismatchesRow, error)
 	ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error)
+	ListEntriesAfter(ctx context.Context, arg ListEntriesAfterParams) ([]Entry, error)
+	ListEntriesBefore(ctx context.Context, arg ListEntriesBeforeParams) ([]Entry, error)
+	ListEntriesWithArchiveAfter(ctx context.Context, arg ListEntriesWithArchiveAfterParams) ([]Entry, error)
+	ListEntriesWithArchiveBefore(ctx context.Context, arg ListEntriesWithArchiveBeforeParams) ([]Entry, error)
+	ListImportErrors(ctx context.Context, arg ListImportErrorsParams) ([]ImportError, error)
+	ListImportedAccountsForUpdate(ctx context.Context, externalIds []string) ([]ListImportedAccountsForUpdateRow, error)
+	ListImportedExternalIDs(ctx context.Context, externalIds []string) ([]string, error)
+	ListLatestEntries(ctx context.Context, arg ListLatestEntriesParams) ([]Entry, error)
+	ListSessions(ctx context.Context, arg ListSessionsParams) ([]Session, error)
 	ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error)
+	ListTransfersAfter(ctx context.Context, arg ListTransfersAfterParams) ([]Transfer, error)
+	ListTransfersBefore(ctx context.Context, arg ListTransfersBeforeParams) ([]Transfer, error)
+	ListTransfersWithArchiveAfter(ctx context.Context, arg ListTransfersWithArchiveAfterParams) ([]Transfer, error)
+	ListTransfersWithArchiveBefore(ctx context.Context, arg ListTransfersWithArchiveBeforeParams) ([]Transfer, error)
+	ListUnpublishedOutboxEventsForUpdate(ctx context.Context, limit int32) ([]OutboxEvent, error)
+	ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]WebhookDelivery, error)
+	ListWebhookSubscriptions(ctx context.Context, owner string) ([]WebhookSubscription, error)
+	ListWebhookSubscriptionsForEvent(ctx context.Context, arg ListWebhookSubscriptionsForEventParams) ([]WebhookSubscription, error)
+	MarkOutboxEventsPublished(ctx context.Context, ids []int64) error
+	MarkWebhookDeliveryFailed(ctx context.Context, arg MarkWebhookDeliveryFailedParams) (WebhookDelivery, error)
+	MarkWebhookDeliverySucceeded(ctx context.Context, arg MarkWebhookDeliverySucceededParams) (WebhookDelivery, error)
+	NextAccountIDs(ctx context.Context, count int32) ([]int64, error)
+	NextTransferIDs(ctx context.Context, count int32) ([]int64, error)
+	ReplayDeadWebhookDeliveries(ctx context.Context, subscriptionID int64) (int64, error)
+	ReplayWebhookDelivery(ctx context.Context, id int64) (WebhookDelivery, error)
+	ResetLedgerSequences(ctx context.Context) error
+	RestoreAccounts(ctx context.Context, arg []RestoreAccountsParams) (int64, error)
+	RestoreArchivedEntries(ctx context.Context, arg RestoreArchivedEntriesParams) (int64, error)
+	RestoreArchivedTransfers(ctx context.Context, arg RestoreArchivedTransfersParams) (int64, error)
+	RestoreEntries(ctx context.Context, arg []RestoreEntriesParams) (int64, error)
+	RestoreTransfers(ctx context.Context, arg []RestoreTransfersParams) (int64, error)
+	RevokeSession(ctx context.Context, arg RevokeSessionParams) (Session, error)
+	SetAccountFrozen(ctx context.Context, arg SetAccountFrozenParams) (Account, error)
 	UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error)
 }
 
@@ -0,0 +1,171 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// replicationLagQuery returns whether a replica streams the WAL from the primary and how far it is
+// behind it in seconds. A replica that replayed everything it received isn't lagging, even if the
+// primary wrote nothing for a while, as long as it still receives: a replica cut off from the
+// primary has replayed everything too. The status of pg_stat_wal_receiver is only shown to roles
+// with pg_read_all_stats, a replica checked by another role is never found streaming.
+const replicationLagQuery = `SELECT
+    EXISTS (SELECT 1 FROM pg_stat_wal_receiver WHERE status = 'streaming'),
+    COALESCE(CASE
+        WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+        ELSE EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp())
+    END, 0)::float8`
+
+type primaryKey struct{}
+
+// WithPrimary makes the queries run with ctx read from the primary, so that a caller reads its own
+// writes instead of a replica that may not have replayed them yet
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// OnPrimary tells whether WithPrimary was called on ctx
+func OnPrimary(ctx context.Context) bool {
+	primary, _ := ctx.Value(primaryKey{}).(bool)
+	return primary
+}
+
+// IsReplicaQuery tells whether the sqlc query of the given name may read from a replica:
+// GetAccount and the List queries, which include the reports, unless they lock rows
+func IsReplicaQuery(name string) bool {
+	if strings.HasSuffix(name, "ForUpdate") {
+		return false
+	}
+	return name == "GetAccount" || strings.HasPrefix(name, "List")
+}
+
+// replica is a read replica along with the outcome of its last health check
+type replica struct {
+	name    string
+	pool    *pgxpool.Pool
+	healthy atomic.Bool
+}
+
+// ReplicaRouter is a DBTX sending the read-only queries to the healthy replicas in turn and
+// everything else to the primary. Transactions don't go through it: the Store begins them on
+// the primary, so that every query of executeTransaction stays there.
+type ReplicaRouter struct {
+	primary  *pgxpool.Pool
+	replicas []*replica
+	maxLag   time.Duration
+	interval time.Duration
+	next     atomic.Uint64
+}
+
+// NewReplicaRouter returns a router checking every interval that the replicas are reachable and
+// at most maxLag behind the primary. The replicas are only used once a check found them healthy.
+func NewReplicaRouter(primary *pgxpool.Pool, replicas []*pgxpool.Pool, maxLag, interval time.Duration) *ReplicaRouter {
+	router := &ReplicaRouter{
+		primary:  primary,
+		maxLag:   maxLag,
+		interval: interval,
+	}
+	for i, pool := range replicas {
+		router.replicas = append(router.replicas, &replica{name: fmt.Sprintf("replica-%d", i+1), pool: pool})
+	}
+	return router
+}
+
+// CheckOnce checks every replica and returns how many are healthy, along with the reasons the others aren't
+func (router *ReplicaRouter) CheckOnce(ctx context.Context) (int, error) {
+	healthy := 0
+	var errs []error
+	for _, replica := range router.replicas {
+		err := router.check(ctx, replica)
+		replica.healthy.Store(err == nil)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", replica.name, err))
+			continue
+		}
+		healthy++
+	}
+	return healthy, errors.Join(errs...)
+}
+
+func (router *ReplicaRouter) check(ctx context.Context, replica *replica) error {
+	ctx, cancel := context.WithTimeout(ctx, router.interval)
+	defer cancel()
+
+	var streaming bool
+	var lagSeconds float64
+	if err := replica.pool.QueryRow(ctx, replicationLagQuery).Scan(&streaming, &lagSeconds); err != nil {
+		return err
+	}
+	if !streaming {
+		return errors.New("not streaming from the primary")
+	}
+	lag := time.Duration(lagSeconds * float64(time.Second))
+	if lag > router.maxLag {
+		return fmt.Errorf("replication lag %v exceeds %v", lag.Round(time.Millisecond), router.maxLag)
+	}
+	return nil
+}
+
+// Run checks the replicas every interval until ctx is cancelled
+func (router *ReplicaRouter) Run(ctx context.Context) {
+	for {
+		if _, err := router.CheckOnce(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("replica router: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(router.interval):
+		}
+	}
+}
+
+// reader returns the pool the query should read from
+func (router *ReplicaRouter) reader(ctx context.Context, query string) *pgxpool.Pool {
+	if OnPrimary(ctx) || !IsReplicaQuery(QueryName(query)) {
+		return router.primary
+	}
+	// round robin over the replicas, skipping the unhealthy ones
+	start := router.next.Add(1)
+	for i := range uint64(len(router.replicas)) {
+		replica := router.replicas[(start+i)%uint64(len(router.replicas))]
+		if replica.healthy.Load() {
+			return replica.pool
+		}
+	}
+	return router.primary
+}
+
+func (router *ReplicaRouter) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	return router.primary.Exec(ctx, sql, args...)
+}
+
+func (router *ReplicaRouter) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	return router.reader(ctx, sql).Query(ctx, sql, args...)
+}
+
+func (router *ReplicaRouter) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	return router.reader(ctx, sql).QueryRow(ctx, sql, args...)
+}
+
+func (router *ReplicaRouter) CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error) {
+	return router.primary.CopyFrom(ctx, tableName, columnNames, rowSrc)
+}
+
+// WithReplicas runs the queries made outside of txns through router, which reads from the replicas
+func WithReplicas(router *ReplicaRouter) StoreOption {
+	return func(store *SQLStore) {
+		store.router = router
+	}
+}
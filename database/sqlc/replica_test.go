@@ -0,0 +1,69 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsReplicaQuery(t *testing.T) {
+	assert.True(t, IsReplicaQuery("GetAccount"))
+	assert.True(t, IsReplicaQuery("ListEntries"))
+	assert.True(t, IsReplicaQuery("ListBalanceMismatches"))
+	assert.False(t, IsReplicaQuery("GetAccountForUpdate"))
+	assert.False(t, IsReplicaQuery("ListUnpublishedOutboxEventsForUpdate"))
+	assert.False(t, IsReplicaQuery("GetSession"))
+	assert.False(t, IsReplicaQuery("CreateAccount"))
+}
+
+// openUnreachable returns a handle on a database that is never connected to
+func openUnreachable(t *testing.T) *pgxpool.Pool {
+	pool, err := pgxpool.New(context.Background(), "postgresql://localhost:1/none?sslmode=disable")
+	assert.NoError(t, err)
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+func TestReplicaRouter_Reader(t *testing.T) {
+	primary, replica1, replica2 := openUnreachable(t), openUnreachable(t), openUnreachable(t)
+	router := NewReplicaRouter(primary, []*pgxpool.Pool{replica1, replica2}, time.Second, time.Second)
+	ctx := context.Background()
+
+	// replicas are used only once found healthy
+	assert.Same(t, primary, router.reader(ctx, getAccount))
+
+	router.replicas[0].healthy.Store(true)
+	router.replicas[1].healthy.Store(true)
+	first, second := router.reader(ctx, listEntries), router.reader(ctx, listEntries)
+	assert.ElementsMatch(t, []*pgxpool.Pool{replica1, replica2}, []*pgxpool.Pool{first, second})
+
+	assert.Same(t, primary, router.reader(ctx, getAccountForUpdate))
+	assert.Same(t, primary, router.reader(ctx, createAccount))
+	assert.Same(t, primary, router.reader(WithPrimary(ctx), getAccount))
+
+	router.replicas[0].healthy.Store(false)
+	for range 3 {
+		assert.Same(t, replica2, router.reader(ctx, getAccount))
+	}
+}
+
+func TestReplicaRouter_CheckOnce(t *testing.T) {
+	router := NewReplicaRouter(testDB, []*pgxpool.Pool{testDB, openUnreachable(t)}, time.Second, time.Second)
+
+	// the primary standing in for a replica doesn't receive any WAL, so it could be arbitrarily far behind
+	healthy, err := router.CheckOnce(context.Background())
+	assert.Equal(t, 0, healthy)
+	assert.ErrorContains(t, err, "replica-1: not streaming from the primary")
+	assert.ErrorContains(t, err, "replica-2")
+	assert.False(t, router.replicas[0].healthy.Load())
+	assert.False(t, router.replicas[1].healthy.Load())
+
+	store := NewStore(testDB, WithReplicas(router))
+	account := createRandomAccount(t)
+	got, err := store.GetAccount(context.Background(), account.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, account.ID, got.ID)
+}
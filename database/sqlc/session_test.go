@@ -0,0 +1,79 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"github.com/arpangoswami/backend-golang-dev/util"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func createRandomSession(t *testing.T, username string) Session {
+	t.Helper()
+	arg := CreateSessionParams{
+		ID:           uuid.New(),
+		Username:     username,
+		RefreshToken: util.RandomString(32),
+		UserAgent:    "go-test",
+		ClientIp:     "127.0.0.1",
+		ExpiresAt:    time.Now().Add(time.Hour),
+	}
+
+	session, err := testQueries.CreateSession(context.Background(), arg)
+	assert.NoError(t, err)
+	assert.Equal(t, arg.ID, session.ID)
+	assert.Equal(t, arg.Username, session.Username)
+	assert.Equal(t, arg.RefreshToken, session.RefreshToken)
+	assert.False(t, session.IsRevoked)
+	assert.WithinDuration(t, arg.ExpiresAt, session.ExpiresAt, time.Second)
+	assert.NotZero(t, session.CreatedAt)
+	return session
+}
+
+func TestQueries_CreateSession(t *testing.T) {
+	user := createRandomUser(t)
+	createRandomSession(t, user.Username)
+}
+
+func TestQueries_GetSession(t *testing.T) {
+	user := createRandomUser(t)
+	session1 := createRandomSession(t, user.Username)
+	session2, err := testQueries.GetSession(context.Background(), session1.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, session1.ID, session2.ID)
+	assert.Equal(t, session1.RefreshToken, session2.RefreshToken)
+	assert.WithinDurationf(t, session1.CreatedAt, session2.CreatedAt, time.Second,
+		"createdAt1 and createdAt2 should have max difference of 1s")
+}
+
+func TestQueries_ListSessions(t *testing.T) {
+	user := createRandomUser(t)
+	for i := 0; i < 3; i++ {
+		createRandomSession(t, user.Username)
+	}
+	sessions, err := testQueries.ListSessions(context.Background(), ListSessionsParams{
+		Username: user.Username,
+		Limit:    5,
+		Offset:   0,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 3)
+	for _, session := range sessions {
+		assert.Equal(t, user.Username, session.Username)
+	}
+}
+
+func TestQueries_RevokeSession(t *testing.T) {
+	user := createRandomUser(t)
+	session := createRandomSession(t, user.Username)
+	arg := RevokeSessionParams{ID: session.ID, Username: user.Username}
+
+	revoked, err := testQueries.RevokeSession(context.Background(), arg)
+	assert.NoError(t, err)
+	assert.True(t, revoked.IsRevoked)
+
+	_, err = testQueries.RevokeSession(context.Background(), arg)
+	assert.EqualError(t, err, sql.ErrNoRows.Error())
+}
@@ -0,0 +1,165 @@
+package db
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// SlowQueryLog configures the logging of slow queries, see NewSlowQueryDBTX
+type SlowQueryLog struct {
+	Logger *slog.Logger
+	// Threshold is the duration from which a query is logged
+	Threshold time.Duration
+	// Explain, when set, runs EXPLAIN for the queries taking ExplainThreshold or longer, COPY
+	// excepted, and logs their plan. Pass the pool rather than a txn, whose connection may be busy
+	// reading the rows of the slow query. The plan costs a round trip, keep it out of production.
+	Explain          DBTX
+	ExplainThreshold time.Duration
+}
+
+// WithSlowQueryLog logs the queries taking longer than the threshold, including those of txns
+func WithSlowQueryLog(log SlowQueryLog) StoreOption {
+	return func(store *SQLStore) {
+		store.slowQueryLog = &log
+	}
+}
+
+// slowQueryDBTX times every query and logs the slow ones
+type slowQueryDBTX struct {
+	dbtx DBTX
+	log  SlowQueryLog
+}
+
+// NewSlowQueryDBTX wraps dbtx so that the queries taking longer than log.Threshold, including the reading
+// of their rows, are logged with their sqlc name, fingerprint, duration, sanitized arguments and rows.
+func NewSlowQueryDBTX(dbtx DBTX, log SlowQueryLog) DBTX {
+	if log.Logger == nil {
+		log.Logger = slog.Default()
+	}
+	return &slowQueryDBTX{dbtx: dbtx, log: log}
+}
+
+func (s *slowQueryDBTX) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	return execute(ctx, s.dbtx, sql, args, s.start(ctx, sql, args))
+}
+
+func (s *slowQueryDBTX) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	return query(ctx, s.dbtx, sql, args, s.start(ctx, sql, args))
+}
+
+func (s *slowQueryDBTX) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	return queryRow(ctx, s.dbtx, sql, args, s.start(ctx, sql, args))
+}
+
+func (s *slowQueryDBTX) CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error) {
+	return copyFrom(ctx, s.dbtx, tableName, columnNames, rowSrc, s.start(ctx, copyStatement(tableName, columnNames), nil))
+}
+
+// start times a query, the returned func logs it if it was slow
+func (s *slowQueryDBTX) start(ctx context.Context, query string, args []interface{}) finishFunc {
+	start := time.Now()
+	return func(rows int64, err error) {
+		if elapsed := time.Since(start); elapsed >= s.log.Threshold {
+			s.logQuery(ctx, query, args, elapsed, rows, err)
+		}
+	}
+}
+
+func (s *slowQueryDBTX) logQuery(ctx context.Context, query string, args []interface{}, elapsed time.Duration, rows int64, err error) {
+	attrs := []slog.Attr{
+		slog.String("query", QueryName(query)),
+		slog.String("fingerprint", Fingerprint(query)),
+		slog.Duration("duration", elapsed),
+		slog.Int64("rows", rows),
+		slog.Any("args", SanitizeArgs(args)),
+	}
+	if failed(err) {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	} else if s.log.Explain != nil && elapsed >= s.log.ExplainThreshold && QueryName(query) != "COPY" {
+		plan, explainErr := explain(ctx, s.log.Explain, query, args)
+		if explainErr != nil {
+			attrs = append(attrs, slog.String("explain_error", explainErr.Error()))
+		} else {
+			attrs = append(attrs, slog.String("plan", plan))
+		}
+	}
+	s.log.Logger.LogAttrs(ctx, slog.LevelWarn, "slow query", attrs...)
+}
+
+// explain returns the plan postgres picks for the query with these arguments, without running it
+func explain(ctx context.Context, dbtx DBTX, query string, args []interface{}) (string, error) {
+	rows, err := dbtx.Query(ctx, "EXPLAIN "+query, args...)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	var lines []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return "", err
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n"), rows.Err()
+}
+
+var (
+	lineComment   = regexp.MustCompile(`--[^\n]*`)
+	stringLiteral = regexp.MustCompile(`'(?:[^']|'')*'`)
+	numberLiteral = regexp.MustCompile(`\b\d+(?:\.\d+)?\b`)
+)
+
+// Fingerprint identifies the shape of a query: queries differing only by their comments, spacing,
+// case or literal values share it, so that the same ad hoc query can be grouped across logs
+func Fingerprint(query string) string {
+	normalized := lineComment.ReplaceAllString(query, "")
+	normalized = stringLiteral.ReplaceAllString(normalized, "?")
+	normalized = numberLiteral.ReplaceAllString(normalized, "?")
+	normalized = strings.ToLower(strings.Join(strings.Fields(normalized), " "))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:8])
+}
+
+// SanitizeArgs renders the arguments of a query for the logs. Numbers, booleans and times are
+// kept, while the text and bytes, which hold e.g. password hashes, emails and secrets, are
+// replaced by their length.
+func SanitizeArgs(args []interface{}) []string {
+	sanitized := make([]string, len(args))
+	for i, arg := range args {
+		if valuer, ok := arg.(driver.Valuer); ok {
+			value, err := valuer.Value()
+			if err != nil {
+				sanitized[i] = fmt.Sprintf("<%T>", arg)
+				continue
+			}
+			arg = value
+		}
+		switch value := arg.(type) {
+		case nil:
+			sanitized[i] = "NULL"
+		case bool, int, int32, int64, float32, float64:
+			sanitized[i] = fmt.Sprint(value)
+		case time.Time:
+			sanitized[i] = value.Format(time.RFC3339Nano)
+		case string:
+			sanitized[i] = fmt.Sprintf("<string len=%d>", len(value))
+		case []byte:
+			sanitized[i] = fmt.Sprintf("<bytes len=%d>", len(value))
+		default:
+			sanitized[i] = fmt.Sprintf("<%T>", value)
+		}
+	}
+	return sanitized
+}
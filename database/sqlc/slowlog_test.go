@@ -0,0 +1,103 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+)
+
+// execDBTX answers Exec after a delay, the other methods are not used
+type execDBTX struct {
+	DBTX
+	delay time.Duration
+}
+
+func (e execDBTX) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	time.Sleep(e.delay)
+	return pgconn.NewCommandTag("DELETE 3"), nil
+}
+
+// slowQueryLogs returns the JSON records written to buffer
+func slowQueryLogs(t *testing.T, buffer *bytes.Buffer) []map[string]any {
+	var records []map[string]any
+	decoder := json.NewDecoder(buffer)
+	for decoder.More() {
+		var record map[string]any
+		assert.NoError(t, decoder.Decode(&record))
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestSlowQueryDBTX_Exec(t *testing.T) {
+	var buffer bytes.Buffer
+	dbtx := NewSlowQueryDBTX(execDBTX{delay: 5 * time.Millisecond}, SlowQueryLog{
+		Logger:    slog.New(slog.NewJSONHandler(&buffer, nil)),
+		Threshold: time.Millisecond,
+	})
+
+	err := New(dbtx).DeleteAccount(context.Background(), 42)
+	assert.NoError(t, err)
+	records := slowQueryLogs(t, &buffer)
+	assert.Len(t, records, 1)
+	assert.Equal(t, "slow query", records[0]["msg"])
+	assert.Equal(t, "DeleteAccount", records[0]["query"])
+	assert.Equal(t, Fingerprint(deleteAccount), records[0]["fingerprint"])
+	assert.Equal(t, float64(3), records[0]["rows"])
+	assert.Equal(t, []any{"42"}, records[0]["args"])
+	assert.NotContains(t, records[0], "plan")
+
+	dbtx = NewSlowQueryDBTX(execDBTX{}, SlowQueryLog{
+		Logger:    slog.New(slog.NewJSONHandler(&buffer, nil)),
+		Threshold: time.Second,
+	})
+	err = New(dbtx).DeleteAccount(context.Background(), 42)
+	assert.NoError(t, err)
+	assert.Empty(t, slowQueryLogs(t, &buffer))
+}
+
+func TestFingerprint(t *testing.T) {
+	assert.Equal(t,
+		Fingerprint("SELECT * FROM accounts WHERE id = 1 AND owner = 'alice'"),
+		Fingerprint("-- ad hoc\nselect *\n  from accounts where id = 27 and owner = 'bob'"))
+	assert.NotEqual(t, Fingerprint("SELECT * FROM accounts"), Fingerprint("SELECT * FROM entries"))
+	assert.Len(t, Fingerprint(getAccount), 16)
+}
+
+func TestSanitizeArgs(t *testing.T) {
+	createdAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t,
+		[]string{"7", "1.5", "true", "2024-03-01T12:00:00Z", "<string len=6>", "<bytes len=2>", "NULL", "<string len=3>", "<[]int>"},
+		SanitizeArgs([]interface{}{
+			int64(7), 1.5, true, createdAt, "secret", []byte("{}"),
+			sql.NullString{}, sql.NullString{String: "abc", Valid: true}, []int{1},
+		}))
+}
+
+func TestStore_SlowQueryLogExplain(t *testing.T) {
+	var buffer bytes.Buffer
+	store := NewStore(testDB, WithSlowQueryLog(SlowQueryLog{
+		Logger:  slog.New(slog.NewJSONHandler(&buffer, nil)),
+		Explain: testDB,
+	}))
+	account := createRandomAccount(t)
+
+	err := store.ExecTransaction(context.Background(), func(tx Store) error {
+		_, err := tx.GetAccountForUpdate(context.Background(), account.ID)
+		return err
+	})
+	assert.NoError(t, err)
+
+	records := slowQueryLogs(t, &buffer)
+	if assert.Len(t, records, 1) {
+		assert.Equal(t, "GetAccountForUpdate", records[0]["query"])
+		assert.Contains(t, records[0]["plan"], "accounts")
+	}
+}
@@ -3,37 +3,196 @@ package db
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
 )
 
-// Store provides a interface to implement transactions
-type Store struct {
+// Store provides all functions to execute db queries and transactions
+type Store interface {
+	Querier
+	TransferTransaction(ctx context.Context, arg TransferTransactionParams) (TransferTransactionResult, error)
+	RotateSessionTransaction(ctx context.Context, arg RotateSessionTransactionParams) (Session, error)
+	OpenAccountTransaction(ctx context.Context, arg CreateAccountParams) (Account, error)
+	ReverseTransferTransaction(ctx context.Context, transferID int64) (TransferTransactionResult, error)
+	RelayOutboxTransaction(ctx context.Context, limit int32, publish func(context.Context, OutboxEvent) error) (int, error)
+	ExecTransaction(ctx context.Context, fn func(tx Store) error) error
+	ReadSnapshotTransaction(ctx context.Context, fn func(tx Store) error) error
+	ImportAccountsTransaction(ctx context.Context, arg ImportAccountsParams) (ImportBatchResult, error)
+	ImportTransfersTransaction(ctx context.Context, arg ImportTransfersParams) (ImportBatchResult, error)
+}
+
+var (
+	// ErrAccountFrozen is returned when money would move in or out of a frozen account
+	ErrAccountFrozen = errors.New("account is frozen")
+	// ErrTransferReversed is returned when reversing a transfer that was already reversed
+	ErrTransferReversed = errors.New("transfer is already reversed")
+	// ErrReversalNotReversible is returned when reversing a transfer that is itself a reversal
+	ErrReversalNotReversible = errors.New("a reversal cannot be reversed")
+	// ErrTransferArchived is returned when a transfer was moved to transfers_archive, where it can
+	// be read but not reversed
+	ErrTransferArchived = errors.New("transfer is archived")
+	// ErrNestedSnapshot is returned when ReadSnapshotTransaction is called on a store bound to a
+	// txn, whose isolation level cannot change anymore
+	ErrNestedSnapshot = errors.New("a snapshot cannot be read within a txn")
+)
+
+// SQLStore provides a interface to implement transactions on top of a SQL database.
+// Every mutation of an account or transfer is written to audit_log in the same txn,
+// with the actor and request ID found in the context (see WithActor and WithRequestID),
+// and emits a domain event to outbox_events for RelayOutboxTransaction to deliver.
+type SQLStore struct {
 	*Queries
-	database *sql.DB
+	database *pgxpool.Pool
+	// tx is set on the store handed out by ExecTransaction, whose transactions join it
+	tx pgx.Tx
+	// tracerProvider, when set, traces the queries, txns and transfers
+	tracerProvider trace.TracerProvider
+	tracer         trace.Tracer
+	metrics        *Metrics
+	slowQueryLog   *SlowQueryLog
+	// router, when set, sends the reads made outside of txns to the replicas
+	router *ReplicaRouter
+}
+
+// StoreOption configures the Store returned by NewStore
+type StoreOption func(*SQLStore)
+
+// WithTracerProvider creates spans for every query (see NewTracingDBTX), for the begin, commit
+// and rollback of txns and for TransferTransaction as a whole, as children of the caller's span
+func WithTracerProvider(provider trace.TracerProvider) StoreOption {
+	return func(store *SQLStore) {
+		store.tracerProvider = provider
+	}
+}
+
+// NewStore returns a instance of Store object running on the pool
+func NewStore(pool *pgxpool.Pool, opts ...StoreOption) Store {
+	store := &SQLStore{database: pool}
+	for _, opt := range opts {
+		opt(store)
+	}
+	if store.tracerProvider == nil {
+		store.tracer = noop.NewTracerProvider().Tracer(tracerName)
+	} else {
+		store.tracer = store.tracerProvider.Tracer(tracerName)
+	}
+	var dbtx DBTX = pool
+	if store.router != nil {
+		dbtx = store.router
+	}
+	store.Queries = New(store.wrap(dbtx))
+	return store
+}
+
+// wrap decorates the connection or txn queries run on according to the store's options
+func (store *SQLStore) wrap(dbtx DBTX) DBTX {
+	if store.tracerProvider != nil {
+		dbtx = NewTracingDBTX(dbtx, store.tracerProvider)
+	}
+	if store.metrics != nil {
+		dbtx = NewMetricsDBTX(dbtx, store.metrics)
+	}
+	if store.slowQueryLog != nil {
+		dbtx = NewSlowQueryDBTX(dbtx, *store.slowQueryLog)
+	}
+	return dbtx
+}
+
+// ExecTransaction runs fn in a db txn with a Store bound to it. Every call on that store joins the
+// txn, including its transactions, so that several of them, or a transaction and a job enqueued
+// on the store, are committed together. The txn is rolled back if fn returns an error.
+func (store *SQLStore) ExecTransaction(ctx context.Context, fn func(tx Store) error) error {
+	if store.tx != nil {
+		return fn(store)
+	}
+	return store.inTransaction(ctx, pgx.TxOptions{}, func(tx pgx.Tx) error {
+		return fn(store.bound(tx))
+	})
+}
+
+// ReadSnapshotTransaction runs fn in a read-only repeatable read txn with a Store bound to it,
+// so that every query of fn reads the same consistent snapshot of the database
+func (store *SQLStore) ReadSnapshotTransaction(ctx context.Context, fn func(tx Store) error) error {
+	if store.tx != nil {
+		return ErrNestedSnapshot
+	}
+	opts := pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly}
+	return store.inTransaction(ctx, opts, func(tx pgx.Tx) error {
+		return fn(store.bound(tx))
+	})
 }
 
-// NewStore returns a instance of Store object
-func NewStore(db *sql.DB) *Store {
-	return &Store{
-		Queries:  New(db),
-		database: db,
+// bound returns a copy of the store whose calls join tx
+func (store *SQLStore) bound(tx pgx.Tx) *SQLStore {
+	txStore := *store
+	txStore.Queries = New(store.wrap(tx))
+	txStore.tx = tx
+	return &txStore
+}
+
+func (store *SQLStore) executeTransaction(ctx context.Context, fn func(*Queries) error) error {
+	if store.tx != nil {
+		return fn(store.Queries)
+	}
+	return store.inTransaction(ctx, pgx.TxOptions{}, func(tx pgx.Tx) error {
+		return fn(New(store.wrap(tx)))
+	})
+}
+
+// maxTransactionAttempts bounds the runs of a txn failing with a serialization failure or a deadlock
+const maxTransactionAttempts = 3
+
+// inTransaction runs fn in a new txn, running it again in a fresh txn when postgres
+// aborted the first one to resolve a serialization failure or a deadlock
+func (store *SQLStore) inTransaction(ctx context.Context, opts pgx.TxOptions, fn func(pgx.Tx) error) error {
+	for attempt := 1; ; attempt++ {
+		err := store.runTransaction(ctx, opts, fn)
+		if attempt == maxTransactionAttempts || !isRetryable(err) || ctx.Err() != nil {
+			return err
+		}
+		store.metrics.observeTransaction(TxRetry)
 	}
 }
 
-func (store *Store) executeTransaction(ctx context.Context, fn func(*Queries) error) error {
-	tx, err := store.database.BeginTx(ctx, nil)
+func (store *SQLStore) runTransaction(ctx context.Context, opts pgx.TxOptions, fn func(pgx.Tx) error) error {
+	tx, err := traced(ctx, store.tracer, "BEGIN", func(ctx context.Context) (pgx.Tx, error) {
+		return store.database.BeginTx(ctx, opts)
+	})
 	if err != nil {
 		return err
 	}
-	q := New(tx)
-	err = fn(q)
+	err = fn(tx)
 	if err != nil {
-		if rbErr := tx.Rollback(); rbErr != nil {
+		store.metrics.observeTransaction(TxRollback)
+		if _, rbErr := traced(ctx, store.tracer, "ROLLBACK", noResult(tx.Rollback)); rbErr != nil {
 			return fmt.Errorf("transaction error %w; rollback error failed: %w", err, rbErr)
 		}
 		return err
 	}
-	return tx.Commit()
+	_, err = traced(ctx, store.tracer, "COMMIT", noResult(tx.Commit))
+	if err != nil {
+		store.metrics.observeTransaction(TxRollback)
+		return err
+	}
+	store.metrics.observeTransaction(TxCommit)
+	return nil
+}
+
+// isRetryable tells whether postgres aborted the txn to resolve a conflict with another one
+func isRetryable(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == "40001" || pgErr.Code == "40P01"
 }
 
 type TransferTransactionParams struct {
@@ -52,34 +211,408 @@ type TransferTransactionResult struct {
 
 // TransferTransaction performs a money transfer from one account to the other.
 // It creates a transfer record, add account entries, and update accounts' balance within a single db txn
-func (store *Store) TransferTransaction(ctx context.Context, arg TransferTransactionParams) (TransferTransactionResult, error) {
+func (store *SQLStore) TransferTransaction(ctx context.Context, arg TransferTransactionParams) (TransferTransactionResult, error) {
+	return traced(ctx, store.tracer, "TransferTransaction", func(ctx context.Context) (TransferTransactionResult, error) {
+		var result TransferTransactionResult
+		err := store.executeTransaction(ctx, func(q *Queries) error {
+			var err error
+			result, err = transfer(ctx, q, CreateTransferParams{
+				FromAccountID: arg.FromAccountID,
+				ToAccountID:   arg.ToAccountID,
+				Amount:        arg.Amount,
+			})
+			return err
+		})
+		if err == nil {
+			store.metrics.observeTransfer(result)
+		}
+		return result, err
+	}, attribute.Int64("transfer.from_account_id", arg.FromAccountID), attribute.Int64("transfer.to_account_id", arg.ToAccountID))
+}
+
+// ReverseTransferTransaction moves the money of a transfer back with a new transfer linked to it.
+// A transfer can be reversed only once and reversals themselves cannot be reversed
+func (store *SQLStore) ReverseTransferTransaction(ctx context.Context, transferID int64) (TransferTransactionResult, error) {
 	var result TransferTransactionResult
 	err := store.executeTransaction(ctx, func(q *Queries) error {
-		var err error
-		result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
-			FromAccountID: arg.FromAccountID,
-			ToAccountID:   arg.ToAccountID,
-			Amount:        arg.Amount,
-		})
+		// the row lock serializes concurrent reversals of the same transfer
+		original, err := q.GetTransferForUpdate(ctx, transferID)
+		if errors.Is(err, sql.ErrNoRows) {
+			if _, archivedErr := q.GetArchivedTransfer(ctx, transferID); archivedErr == nil {
+				return fmt.Errorf("%w: transfer [%d]", ErrTransferArchived, transferID)
+			}
+		}
 		if err != nil {
 			return err
 		}
-		result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.FromAccountID,
-			Amount:    -arg.Amount,
+		if original.ReversesTransferID.Valid {
+			return ErrReversalNotReversible
+		}
+		_, err = q.GetTransferReversal(ctx, sql.NullInt64{Int64: original.ID, Valid: true})
+		if err == nil {
+			return ErrTransferReversed
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		result, err = transfer(ctx, q, CreateTransferParams{
+			FromAccountID:      original.ToAccountID,
+			ToAccountID:        original.FromAccountID,
+			Amount:             original.Amount,
+			ReversesTransferID: sql.NullInt64{Int64: original.ID, Valid: true},
 		})
+		return err
+	})
+	return result, err
+}
+
+// transfer records the transfer with its two entries and moves the balances, inside the caller's txn
+func transfer(ctx context.Context, q *Queries, arg CreateTransferParams) (TransferTransactionResult, error) {
+	var result TransferTransactionResult
+
+	// lock the accounts in id order so that transfers going opposite ways cannot deadlock
+	firstID, secondID := arg.FromAccountID, arg.ToAccountID
+	if secondID < firstID {
+		firstID, secondID = secondID, firstID
+	}
+	before := make(map[int64]Account, 2)
+	for _, id := range []int64{firstID, secondID} {
+		account, err := q.GetAccountForUpdate(ctx, id)
+		if err != nil {
+			return result, err
+		}
+		if account.IsFrozen {
+			return result, fmt.Errorf("%w: account [%d]", ErrAccountFrozen, account.ID)
+		}
+		before[id] = account
+	}
+
+	var err error
+	result.Transfer, err = q.CreateTransfer(ctx, arg)
+	if err != nil {
+		return result, err
+	}
+	result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID: arg.FromAccountID,
+		Amount:    -arg.Amount,
+	})
+	if err != nil {
+		return result, err
+	}
+	result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID: arg.ToAccountID,
+		Amount:    arg.Amount,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	result.FromAccount, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
+		ID:     arg.FromAccountID,
+		Amount: -arg.Amount,
+	})
+	if err != nil {
+		return result, err
+	}
+	result.ToAccount, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
+		ID:     arg.ToAccountID,
+		Amount: arg.Amount,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	action, event := ActionTransferCreate, EventTransferCompleted
+	if arg.ReversesTransferID.Valid {
+		action, event = ActionTransferReverse, EventTransferReversed
+	}
+	if err := audit(ctx, q, action, EntityTransfer, result.Transfer.ID, nil, result.Transfer); err != nil {
+		return result, err
+	}
+	for _, account := range []Account{result.FromAccount, result.ToAccount} {
+		if err := audit(ctx, q, action, EntityAccount, account.ID, before[account.ID], account); err != nil {
+			return result, err
+		}
+	}
+	return result, emit(ctx, q, EntityTransfer, result.Transfer.ID, event, result)
+}
+
+// OpenAccountTransaction creates an account and records its opening balance as an entry,
+// so that the balance always matches the sum of the account's entries
+func (store *SQLStore) OpenAccountTransaction(ctx context.Context, arg CreateAccountParams) (Account, error) {
+	var account Account
+	err := store.executeTransaction(ctx, func(q *Queries) error {
+		var err error
+		account, err = q.CreateAccount(ctx, arg)
 		if err != nil {
 			return err
 		}
-		result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.ToAccountID,
-			Amount:    arg.Amount,
-		})
+		if arg.Balance != 0 {
+			_, err = q.CreateEntry(ctx, CreateEntryParams{
+				AccountID: account.ID,
+				Amount:    arg.Balance,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		if err := audit(ctx, q, ActionAccountCreate, EntityAccount, account.ID, nil, account); err != nil {
+			return err
+		}
+		return emit(ctx, q, EntityAccount, account.ID, EventAccountCreated, account)
+	})
+	return account, err
+}
+
+// CreateAccount inserts an account, audits it and emits its event within a single db txn
+func (store *SQLStore) CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error) {
+	var account Account
+	err := store.executeTransaction(ctx, func(q *Queries) error {
+		var err error
+		account, err = q.CreateAccount(ctx, arg)
 		if err != nil {
 			return err
 		}
-		// TODO: update accounts balance
+		if err := audit(ctx, q, ActionAccountCreate, EntityAccount, account.ID, nil, account); err != nil {
+			return err
+		}
+		return emit(ctx, q, EntityAccount, account.ID, EventAccountCreated, account)
+	})
+	return account, err
+}
+
+// UpdateAccount overwrites the balance of an account, audits the change and emits its event within a single db txn
+func (store *SQLStore) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error) {
+	return store.updateAccount(ctx, arg.ID, ActionAccountUpdate, EventAccountBalanceChanged, func(q *Queries) (Account, error) {
+		return q.UpdateAccount(ctx, arg)
+	})
+}
+
+// AddAccountBalance moves the balance of an account, audits the change and emits its event within a single db txn
+func (store *SQLStore) AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error) {
+	return store.updateAccount(ctx, arg.ID, ActionAccountAddBalance, EventAccountBalanceChanged, func(q *Queries) (Account, error) {
+		return q.AddAccountBalance(ctx, arg)
+	})
+}
+
+// SetAccountFrozen freezes or unfreezes an account, audits the change and emits its event within a single db txn
+func (store *SQLStore) SetAccountFrozen(ctx context.Context, arg SetAccountFrozenParams) (Account, error) {
+	action, event := ActionAccountUnfreeze, EventAccountUnfrozen
+	if arg.IsFrozen {
+		action, event = ActionAccountFreeze, EventAccountFrozen
+	}
+	return store.updateAccount(ctx, arg.ID, action, event, func(q *Queries) (Account, error) {
+		return q.SetAccountFrozen(ctx, arg)
+	})
+}
+
+// updateAccount locks the account, applies update, records the account before and after it and emits event
+func (store *SQLStore) updateAccount(ctx context.Context, id int64, action, event string, update func(*Queries) (Account, error)) (Account, error) {
+	var account Account
+	err := store.executeTransaction(ctx, func(q *Queries) error {
+		before, err := q.GetAccountForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		account, err = update(q)
+		if err != nil {
+			return err
+		}
+		if err := audit(ctx, q, action, EntityAccount, id, before, account); err != nil {
+			return err
+		}
+		return emit(ctx, q, EntityAccount, id, event, account)
+	})
+	return account, err
+}
+
+// DeleteAccount deletes an account, audits its last state and emits its event within a single db txn.
+// Like the plain query, deleting a missing account is not an error.
+func (store *SQLStore) DeleteAccount(ctx context.Context, id int64) error {
+	return store.executeTransaction(ctx, func(q *Queries) error {
+		before, err := q.GetAccountForUpdate(ctx, id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := q.DeleteAccount(ctx, id); err != nil {
+			return err
+		}
+		if err := audit(ctx, q, ActionAccountDelete, EntityAccount, id, before, nil); err != nil {
+			return err
+		}
+		return emit(ctx, q, EntityAccount, id, EventAccountDeleted, before)
+	})
+}
+
+// AddAccountBalances moves the balances of several accounts, audits every change and emits their
+// events within a single db txn
+func (store *SQLStore) AddAccountBalances(ctx context.Context, arg AddAccountBalancesParams) ([]Account, error) {
+	var accounts []Account
+	err := store.executeTransaction(ctx, func(q *Queries) error {
+		before := make(map[int64]Account, len(arg.Ids))
+		for _, id := range arg.Ids {
+			account, err := q.GetAccountForUpdate(ctx, id)
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			before[id] = account
+		}
+		var err error
+		accounts, err = q.AddAccountBalances(ctx, arg)
+		if err != nil {
+			return err
+		}
+		for _, account := range accounts {
+			if err := audit(ctx, q, ActionAccountAddBalance, EntityAccount, account.ID, before[account.ID], account); err != nil {
+				return err
+			}
+			if err := emit(ctx, q, EntityAccount, account.ID, EventAccountBalanceChanged, account); err != nil {
+				return err
+			}
+		}
 		return nil
 	})
-	return result, err
+	return accounts, err
+}
+
+// CreateTransfer inserts a transfer record without moving any money and audits it within a single
+// db txn. TransferTransaction is the one moving money.
+func (store *SQLStore) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
+	var transfer Transfer
+	err := store.executeTransaction(ctx, func(q *Queries) error {
+		var err error
+		transfer, err = q.CreateTransfer(ctx, arg)
+		if err != nil {
+			return err
+		}
+		return audit(ctx, q, ActionTransferCreate, EntityTransfer, transfer.ID, nil, transfer)
+	})
+	return transfer, err
+}
+
+// DeleteTransfer deletes a transfer and audits its last state within a single db txn.
+// Like the plain query, deleting a missing transfer is not an error.
+func (store *SQLStore) DeleteTransfer(ctx context.Context, id int64) error {
+	return store.executeTransaction(ctx, func(q *Queries) error {
+		before, err := q.GetTransferForUpdate(ctx, id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := q.DeleteTransfer(ctx, id); err != nil {
+			return err
+		}
+		return audit(ctx, q, ActionTransferDelete, EntityTransfer, id, before, nil)
+	})
+}
+
+// CreateEntry inserts an entry without changing the account's balance and audits it within a
+// single db txn
+func (store *SQLStore) CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error) {
+	var entry Entry
+	err := store.executeTransaction(ctx, func(q *Queries) error {
+		var err error
+		entry, err = q.CreateEntry(ctx, arg)
+		if err != nil {
+			return err
+		}
+		return audit(ctx, q, ActionEntryCreate, EntityEntry, entry.ID, nil, entry)
+	})
+	return entry, err
+}
+
+// DeleteEntry deletes an entry and audits its last state within a single db txn.
+// Like the plain query, deleting a missing entry is not an error.
+func (store *SQLStore) DeleteEntry(ctx context.Context, id int64) error {
+	return store.executeTransaction(ctx, func(q *Queries) error {
+		before, err := q.GetEntry(ctx, id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := q.DeleteEntry(ctx, id); err != nil {
+			return err
+		}
+		return audit(ctx, q, ActionEntryDelete, EntityEntry, id, before, nil)
+	})
+}
+
+// RestoreAccounts copies accounts restored from a ledger archive, ids included, and audits every
+// one of them within a single db txn
+func (store *SQLStore) RestoreAccounts(ctx context.Context, arg []RestoreAccountsParams) (int64, error) {
+	var count int64
+	err := store.executeTransaction(ctx, func(q *Queries) error {
+		var err error
+		count, err = q.RestoreAccounts(ctx, arg)
+		if err != nil {
+			return err
+		}
+		audits := make([]CopyAuditLogsParams, len(arg))
+		for i, account := range arg {
+			audits[i], err = auditLogRow(ctx, ActionAccountRestore, EntityAccount, account.ID, nil, Account(account))
+			if err != nil {
+				return err
+			}
+		}
+		_, err = q.CopyAuditLogs(ctx, audits)
+		return err
+	})
+	return count, err
+}
+
+// RestoreTransfers copies transfers restored from a ledger archive, ids included, and audits every
+// one of them within a single db txn
+func (store *SQLStore) RestoreTransfers(ctx context.Context, arg []RestoreTransfersParams) (int64, error) {
+	var count int64
+	err := store.executeTransaction(ctx, func(q *Queries) error {
+		var err error
+		count, err = q.RestoreTransfers(ctx, arg)
+		if err != nil {
+			return err
+		}
+		audits := make([]CopyAuditLogsParams, len(arg))
+		for i, transfer := range arg {
+			audits[i], err = auditLogRow(ctx, ActionTransferRestore, EntityTransfer, transfer.ID, nil, Transfer(transfer))
+			if err != nil {
+				return err
+			}
+		}
+		_, err = q.CopyAuditLogs(ctx, audits)
+		return err
+	})
+	return count, err
+}
+
+type RotateSessionTransactionParams struct {
+	OldSessionID uuid.UUID           `json:"old_session_id"`
+	NewSession   CreateSessionParams `json:"new_session"`
+}
+
+// RotateSessionTransaction revokes a session and replaces it with a new one within a single db txn.
+// Revoking only succeeds once, so a refresh token that was already rotated returns sql.ErrNoRows
+func (store *SQLStore) RotateSessionTransaction(ctx context.Context, arg RotateSessionTransactionParams) (Session, error) {
+	var session Session
+	err := store.executeTransaction(ctx, func(q *Queries) error {
+		_, err := q.RevokeSession(ctx, RevokeSessionParams{
+			ID:       arg.OldSessionID,
+			Username: arg.NewSession.Username,
+		})
+		if err != nil {
+			return err
+		}
+		session, err = q.CreateSession(ctx, arg.NewSession)
+		return err
+	})
+	return session, err
 }
@@ -2,8 +2,15 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"github.com/arpangoswami/backend-golang-dev/util"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"testing"
+	"time"
 )
 
 func TestStore_TransferTransaction(t *testing.T) {
@@ -70,6 +77,420 @@ func TestStore_TransferTransaction(t *testing.T) {
 		_, err = store.GetEntry(context.Background(), toEntry.ID)
 		assert.NoError(t, err)
 
-		// TODO: check accounts balance. Tackle it with lock of store transaction
+		assert.Equal(t, account1.ID, transactionResult.FromAccount.ID)
+		assert.Equal(t, account2.ID, transactionResult.ToAccount.ID)
 	}
+
+	// every transfer moved the amount exactly once, whatever the interleaving
+	updatedAccount1, err := store.GetAccount(context.Background(), account1.ID)
+	assert.NoError(t, err)
+	assert.InDelta(t, account1.Balance-float64(n)*amount, updatedAccount1.Balance, 1e-6)
+
+	updatedAccount2, err := store.GetAccount(context.Background(), account2.ID)
+	assert.NoError(t, err)
+	assert.InDelta(t, account2.Balance+float64(n)*amount, updatedAccount2.Balance, 1e-6)
+}
+
+func TestStore_TransferTransactionDeadlock(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	// half of the transfers go the other way, which deadlocks without a consistent lock order
+	n := 10
+	amount := float64(10)
+	errs := make(chan error)
+
+	for i := 0; i < n; i++ {
+		fromAccountID, toAccountID := account1.ID, account2.ID
+		if i%2 == 1 {
+			fromAccountID, toAccountID = account2.ID, account1.ID
+		}
+		go func() {
+			_, err := store.TransferTransaction(context.Background(), TransferTransactionParams{
+				FromAccountID: fromAccountID,
+				ToAccountID:   toAccountID,
+				Amount:        amount,
+			})
+			errs <- err
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		assert.NoError(t, <-errs)
+	}
+
+	updatedAccount1, err := store.GetAccount(context.Background(), account1.ID)
+	assert.NoError(t, err)
+	assert.InDelta(t, account1.Balance, updatedAccount1.Balance, 1e-6)
+}
+
+func TestStore_TransferTransactionFrozenAccount(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	_, err := store.SetAccountFrozen(context.Background(), SetAccountFrozenParams{ID: account2.ID, IsFrozen: true})
+	assert.NoError(t, err)
+
+	_, err = store.TransferTransaction(context.Background(), TransferTransactionParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        5,
+	})
+	assert.ErrorIs(t, err, ErrAccountFrozen)
+
+	unchanged, err := store.GetAccount(context.Background(), account1.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, account1.Balance, unchanged.Balance)
+}
+
+func TestStore_ReverseTransferTransaction(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	original, err := store.TransferTransaction(context.Background(), TransferTransactionParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        25,
+	})
+	assert.NoError(t, err)
+
+	reversal, err := store.ReverseTransferTransaction(context.Background(), original.Transfer.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, account2.ID, reversal.Transfer.FromAccountID)
+	assert.Equal(t, account1.ID, reversal.Transfer.ToAccountID)
+	assert.Equal(t, original.Transfer.Amount, reversal.Transfer.Amount)
+	assert.Equal(t, sql.NullInt64{Int64: original.Transfer.ID, Valid: true}, reversal.Transfer.ReversesTransferID)
+	assert.InDelta(t, account1.Balance, reversal.ToAccount.Balance, 1e-6)
+	assert.InDelta(t, account2.Balance, reversal.FromAccount.Balance, 1e-6)
+
+	_, err = store.ReverseTransferTransaction(context.Background(), original.Transfer.ID)
+	assert.ErrorIs(t, err, ErrTransferReversed)
+
+	_, err = store.ReverseTransferTransaction(context.Background(), reversal.Transfer.ID)
+	assert.ErrorIs(t, err, ErrReversalNotReversible)
+}
+
+func TestStore_OpenAccountTransaction(t *testing.T) {
+	store := NewStore(testDB)
+
+	currencyCountryCode := util.RandomCurrencyCodeCountryCode()
+	account, err := store.OpenAccountTransaction(context.Background(), CreateAccountParams{
+		Owner:       util.RandomOwner(),
+		Balance:     100,
+		Currency:    currencyCountryCode.CurrencyCode,
+		CountryCode: currencyCountryCode.CountryCode,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, float64(100), account.Balance)
+
+	entries, err := store.ListLatestEntries(context.Background(), ListLatestEntriesParams{AccountID: account.ID, Limit: 5})
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, account.Balance, entries[0].Amount)
+
+	mismatches, err := store.ListBalanceMismatches(context.Background(), 1e-6)
+	assert.NoError(t, err)
+	for _, mismatch := range mismatches {
+		assert.NotEqual(t, account.ID, mismatch.ID)
+	}
+}
+
+func TestStore_AuditLog(t *testing.T) {
+	store := NewStore(testDB)
+	ctx := WithRequestID(WithActor(context.Background(), "auditor"), uuid.NewString())
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	result, err := store.TransferTransaction(ctx, TransferTransactionParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	assert.NoError(t, err)
+	_, err = store.SetAccountFrozen(ctx, SetAccountFrozenParams{ID: account1.ID, IsFrozen: true})
+	assert.NoError(t, err)
+
+	logs, err := store.ListAuditLogs(context.Background(), ListAuditLogsParams{
+		EntityType: sql.NullString{String: EntityAccount, Valid: true},
+		EntityID:   sql.NullInt64{Int64: account1.ID, Valid: true},
+		LimitCount: 10,
+	})
+	assert.NoError(t, err)
+	// the test helper creates accounts with the plain queries, which are not audited
+	assert.Len(t, logs, 2)
+	assert.Equal(t, ActionTransferCreate, logs[0].Action)
+	assert.Equal(t, "auditor", logs[0].Actor)
+	assert.Equal(t, AuditFromContext(ctx).RequestID, logs[0].RequestID)
+	var before, after Account
+	assert.NoError(t, json.Unmarshal(logs[0].Before, &before))
+	assert.NoError(t, json.Unmarshal(logs[0].After, &after))
+	assert.InDelta(t, account1.Balance, before.Balance, 1e-6)
+	assert.InDelta(t, result.FromAccount.Balance, after.Balance, 1e-6)
+
+	assert.Equal(t, ActionAccountFreeze, logs[1].Action)
+	assert.Equal(t, SystemActor, AuditFromContext(context.Background()).Actor)
+
+	transferLogs, err := store.ListAuditLogs(context.Background(), ListAuditLogsParams{
+		EntityType: sql.NullString{String: EntityTransfer, Valid: true},
+		EntityID:   sql.NullInt64{Int64: result.Transfer.ID, Valid: true},
+		LimitCount: 10,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, transferLogs, 1)
+	assert.JSONEq(t, "null", string(transferLogs[0].Before))
+
+	// the log cannot be rewritten
+	_, err = testDB.Exec(context.Background(), "DELETE FROM audit_log WHERE id = $1", logs[0].ID)
+	assert.ErrorContains(t, err, "append-only")
+}
+
+func TestStore_AuditedQueries(t *testing.T) {
+	store := NewStore(testDB)
+	ctx := WithActor(context.Background(), "auditor")
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	listLogs := func(entityType string, id int64) []AuditLog {
+		logs, err := store.ListAuditLogs(context.Background(), ListAuditLogsParams{
+			EntityType: sql.NullString{String: entityType, Valid: true},
+			EntityID:   sql.NullInt64{Int64: id, Valid: true},
+			LimitCount: 10,
+		})
+		assert.NoError(t, err)
+		return logs
+	}
+
+	transfer, err := store.CreateTransfer(ctx, CreateTransferParams{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 1})
+	assert.NoError(t, err)
+	assert.NoError(t, store.DeleteTransfer(ctx, transfer.ID))
+	logs := listLogs(EntityTransfer, transfer.ID)
+	if assert.Len(t, logs, 2) {
+		assert.Equal(t, ActionTransferCreate, logs[0].Action)
+		assert.Equal(t, ActionTransferDelete, logs[1].Action)
+		assert.Equal(t, "auditor", logs[1].Actor)
+		assert.JSONEq(t, "null", string(logs[1].After))
+	}
+
+	entry, err := store.CreateEntry(ctx, CreateEntryParams{AccountID: account1.ID, Amount: 1})
+	assert.NoError(t, err)
+	assert.NoError(t, store.DeleteEntry(ctx, entry.ID))
+	logs = listLogs(EntityEntry, entry.ID)
+	if assert.Len(t, logs, 2) {
+		assert.Equal(t, ActionEntryCreate, logs[0].Action)
+		assert.Equal(t, ActionEntryDelete, logs[1].Action)
+	}
+
+	accounts, err := store.AddAccountBalances(ctx, AddAccountBalancesParams{
+		Ids:     []int64{account1.ID, account2.ID},
+		Amounts: []float64{-1, 1},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, accounts, 2)
+	logs = listLogs(EntityAccount, account2.ID)
+	if assert.Len(t, logs, 1) {
+		assert.Equal(t, ActionAccountAddBalance, logs[0].Action)
+		var before Account
+		assert.NoError(t, json.Unmarshal(logs[0].Before, &before))
+		assert.InDelta(t, account2.Balance, before.Balance, 1e-6)
+	}
+
+	// the rows restored from a ledger archive are audited along with the COPY
+	accountIDs, err := store.NextAccountIDs(ctx, 1)
+	require.NoError(t, err)
+	restored := RestoreAccountsParams{ID: accountIDs[0], Owner: account1.Owner, Currency: account1.Currency, CreatedAt: time.Now()}
+	_, err = store.RestoreAccounts(ctx, []RestoreAccountsParams{restored})
+	require.NoError(t, err)
+	logs = listLogs(EntityAccount, restored.ID)
+	if assert.Len(t, logs, 1) {
+		assert.Equal(t, ActionAccountRestore, logs[0].Action)
+		assert.Equal(t, "auditor", logs[0].Actor)
+		assert.JSONEq(t, "null", string(logs[0].Before))
+	}
+
+	transferIDs, err := store.NextTransferIDs(ctx, 1)
+	require.NoError(t, err)
+	_, err = store.RestoreTransfers(ctx, []RestoreTransfersParams{{
+		ID: transferIDs[0], FromAccountID: account1.ID, ToAccountID: restored.ID, Amount: 1, CreatedAt: time.Now(),
+	}})
+	require.NoError(t, err)
+	logs = listLogs(EntityTransfer, transferIDs[0])
+	if assert.Len(t, logs, 1) {
+		assert.Equal(t, ActionTransferRestore, logs[0].Action)
+	}
+}
+
+func TestStore_RelayOutboxTransaction(t *testing.T) {
+	store := NewStore(testDB)
+	ctx := context.Background()
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	result, err := store.TransferTransaction(ctx, TransferTransactionParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	assert.NoError(t, err)
+	_, err = store.SetAccountFrozen(ctx, SetAccountFrozenParams{ID: account1.ID, IsFrozen: true})
+	assert.NoError(t, err)
+	_, err = store.SetAccountFrozen(ctx, SetAccountFrozenParams{ID: account1.ID, IsFrozen: false})
+	assert.NoError(t, err)
+
+	// the freeze of account1 fails, which holds back its unfreeze
+	var delivered []OutboxEvent
+	publish := func(_ context.Context, event OutboxEvent) error {
+		if event.AggregateType == EntityAccount && event.AggregateID == account1.ID {
+			return errors.New("receiver is down")
+		}
+		delivered = append(delivered, event)
+		return nil
+	}
+	for {
+		published, err := store.RelayOutboxTransaction(ctx, 100, publish)
+		if published == 0 {
+			assert.ErrorContains(t, err, "receiver is down")
+			break
+		}
+	}
+
+	var transferEvent *OutboxEvent
+	for i, event := range delivered {
+		if event.AggregateType == EntityTransfer && event.AggregateID == result.Transfer.ID {
+			transferEvent = &delivered[i]
+		}
+	}
+	if assert.NotNil(t, transferEvent) {
+		assert.Equal(t, EventTransferCompleted, transferEvent.EventType)
+		var payload TransferTransactionResult
+		assert.NoError(t, json.Unmarshal(transferEvent.Payload, &payload))
+		assert.Equal(t, result.Transfer.ID, payload.Transfer.ID)
+	}
+
+	// once the receiver is back, the events of account1 are published in order
+	var accountEvents []string
+	_, err = store.RelayOutboxTransaction(ctx, 100, func(_ context.Context, event OutboxEvent) error {
+		if event.AggregateType == EntityAccount && event.AggregateID == account1.ID {
+			accountEvents = append(accountEvents, event.EventType)
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{EventAccountFrozen, EventAccountUnfrozen}, accountEvents)
+}
+
+func TestStore_ExecTransaction(t *testing.T) {
+	store := NewStore(testDB)
+	ctx := context.Background()
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	kind := "test_" + util.RandomString(8)
+
+	// the transfer and the job are committed together
+	var job Job
+	err := store.ExecTransaction(ctx, func(tx Store) error {
+		result, err := tx.TransferTransaction(ctx, TransferTransactionParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        10,
+		})
+		if err != nil {
+			return err
+		}
+		payload, err := json.Marshal(result.Transfer)
+		if err != nil {
+			return err
+		}
+		job, err = tx.EnqueueJob(ctx, EnqueueJobParams{Kind: kind, Payload: payload, MaxAttempts: 1})
+		return err
+	})
+	assert.NoError(t, err)
+	_, err = store.GetJob(ctx, job.ID)
+	assert.NoError(t, err)
+
+	// and rolled back together
+	failure := errors.New("enqueue failed")
+	var transfer TransferTransactionResult
+	err = store.ExecTransaction(ctx, func(tx Store) error {
+		transfer, err = tx.TransferTransaction(ctx, TransferTransactionParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        10,
+		})
+		if err != nil {
+			return err
+		}
+		return failure
+	})
+	assert.ErrorIs(t, err, failure)
+	_, err = store.GetTransfer(ctx, transfer.Transfer.ID)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	account, err := store.GetAccount(ctx, account1.ID)
+	assert.NoError(t, err)
+	assert.InDelta(t, account1.Balance-10, account.Balance, 1e-6)
+}
+
+func TestStore_ReadSnapshotTransaction(t *testing.T) {
+	store := NewStore(testDB)
+	ctx := context.Background()
+	account := createRandomAccount(t)
+
+	// a change committed after the snapshot was taken isn't seen by it
+	err := store.ReadSnapshotTransaction(ctx, func(tx Store) error {
+		before, err := tx.GetAccount(ctx, account.ID)
+		if err != nil {
+			return err
+		}
+		if _, err := store.AddAccountBalance(ctx, AddAccountBalanceParams{ID: account.ID, Amount: 10}); err != nil {
+			return err
+		}
+		after, err := tx.GetAccount(ctx, account.ID)
+		if err != nil {
+			return err
+		}
+		assert.Equal(t, before.Balance, after.Balance)
+		return nil
+	})
+	assert.NoError(t, err)
+
+	err = store.ExecTransaction(ctx, func(tx Store) error {
+		return tx.ReadSnapshotTransaction(ctx, func(Store) error { return nil })
+	})
+	assert.ErrorIs(t, err, ErrNestedSnapshot)
+}
+
+func TestStore_RotateSessionTransaction(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	oldSession := createRandomSession(t, user.Username)
+
+	arg := RotateSessionTransactionParams{
+		OldSessionID: oldSession.ID,
+		NewSession: CreateSessionParams{
+			ID:           uuid.New(),
+			Username:     user.Username,
+			RefreshToken: util.RandomString(32),
+			UserAgent:    oldSession.UserAgent,
+			ClientIp:     oldSession.ClientIp,
+			ExpiresAt:    time.Now().Add(time.Hour),
+		},
+	}
+	newSession, err := store.RotateSessionTransaction(context.Background(), arg)
+	assert.NoError(t, err)
+	assert.Equal(t, arg.NewSession.ID, newSession.ID)
+	assert.False(t, newSession.IsRevoked)
+
+	revoked, err := store.GetSession(context.Background(), oldSession.ID)
+	assert.NoError(t, err)
+	assert.True(t, revoked.IsRevoked)
+
+	// reusing the rotated refresh token fails and doesn't create another session
+	arg.NewSession.ID = uuid.New()
+	_, err = store.RotateSessionTransaction(context.Background(), arg)
+	assert.EqualError(t, err, sql.ErrNoRows.Error())
+	_, err = store.GetSession(context.Background(), arg.NewSession.ID)
+	assert.EqualError(t, err, sql.ErrNoRows.Error())
 }
@@ -0,0 +1,106 @@
+package db
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName identifies the spans created by this package
+const tracerName = "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+
+var dbSystem = attribute.String("db.system", "postgresql")
+
+// tracingDBTX creates a span around every query, as a child of the span found in its context
+type tracingDBTX struct {
+	dbtx   DBTX
+	tracer trace.Tracer
+}
+
+// NewTracingDBTX wraps dbtx so that every query runs in a span named after the sqlc query, e.g. GetAccount.
+// The span of a query returning rows ends once they are read, finding no rows isn't an error.
+func NewTracingDBTX(dbtx DBTX, provider trace.TracerProvider) DBTX {
+	return &tracingDBTX{dbtx: dbtx, tracer: provider.Tracer(tracerName)}
+}
+
+func (t *tracingDBTX) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	ctx, finish := t.start(ctx, sql)
+	return execute(ctx, t.dbtx, sql, args, finish)
+}
+
+func (t *tracingDBTX) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	ctx, finish := t.start(ctx, sql)
+	return query(ctx, t.dbtx, sql, args, finish)
+}
+
+func (t *tracingDBTX) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	ctx, finish := t.start(ctx, sql)
+	return queryRow(ctx, t.dbtx, sql, args, finish)
+}
+
+func (t *tracingDBTX) CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error) {
+	ctx, finish := t.start(ctx, copyStatement(tableName, columnNames))
+	return copyFrom(ctx, t.dbtx, tableName, columnNames, rowSrc, finish)
+}
+
+// start starts the span of a query, the returned func ends it
+func (t *tracingDBTX) start(ctx context.Context, query string) (context.Context, finishFunc) {
+	name := QueryName(query)
+	ctx, span := t.tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			dbSystem,
+			attribute.String("db.operation.name", name),
+			attribute.String("db.query.text", query),
+		),
+	)
+	return ctx, func(rows int64, err error) {
+		span.SetAttributes(attribute.Int64("db.response.returned_rows", rows))
+		if !failed(err) {
+			err = nil
+		}
+		endSpan(span, err)
+	}
+}
+
+// traced runs fn in a span named name
+func traced[T any](ctx context.Context, tracer trace.Tracer, name string, fn func(ctx context.Context) (T, error), attrs ...attribute.KeyValue) (T, error) {
+	ctx, span := tracer.Start(ctx, name, trace.WithAttributes(append(attrs, dbSystem)...))
+	result, err := fn(ctx)
+	endSpan(span, err)
+	return result, err
+}
+
+// noResult adapts the txn's Commit and Rollback to traced
+func noResult(fn func(context.Context) error) func(context.Context) (struct{}, error) {
+	return func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, fn(ctx)
+	}
+}
+
+// endSpan records err, if any, and ends the span
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// QueryName returns the name sqlc gives a query in its leading "-- name: GetAccount :one" comment,
+// or the first keyword of other queries, e.g. SELECT
+func QueryName(query string) string {
+	query = strings.TrimSpace(query)
+	if rest, ok := strings.CutPrefix(query, "-- name: "); ok {
+		if name, _, ok := strings.Cut(rest, " "); ok {
+			return name
+		}
+	}
+	keyword, _, _ := strings.Cut(strings.Join(strings.Fields(query), " "), " ")
+	return strings.ToUpper(keyword)
+}
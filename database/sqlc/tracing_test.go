@@ -0,0 +1,67 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestQueryName(t *testing.T) {
+	assert.Equal(t, "GetAccount", QueryName(getAccount))
+	assert.Equal(t, "TransferTransaction", QueryName("-- name: TransferTransaction :one\nSELECT 1"))
+	assert.Equal(t, "SELECT", QueryName("\n  select 1"))
+	assert.Equal(t, "", QueryName(""))
+}
+
+func TestStore_Tracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	store := NewStore(testDB, WithTracerProvider(provider))
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "request")
+	_, err := store.TransferTransaction(ctx, TransferTransactionParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        1,
+	})
+	assert.NoError(t, err)
+	parent.End()
+
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	transfer := spans["TransferTransaction"]
+	if assert.NotNil(t, transfer) {
+		assert.Equal(t, parent.SpanContext().SpanID(), transfer.Parent().SpanID())
+	}
+	for _, name := range []string{"BEGIN", "GetAccountForUpdate", "CreateTransfer", "CreateEntry", "COMMIT"} {
+		if assert.Contains(t, spans, name) {
+			assert.Equal(t, transfer.SpanContext().SpanID(), spans[name].Parent().SpanID(), name)
+			assert.Equal(t, parent.SpanContext().TraceID(), spans[name].SpanContext().TraceID(), name)
+		}
+	}
+
+	recorder = tracetest.NewSpanRecorder()
+	provider = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	store = NewStore(testDB, WithTracerProvider(provider))
+	failure := errors.New("failure")
+	err = store.ExecTransaction(context.Background(), func(tx Store) error {
+		return failure
+	})
+	assert.ErrorIs(t, err, failure)
+
+	var names []string
+	for _, span := range recorder.Ended() {
+		names = append(names, span.Name())
+		assert.Equal(t, codes.Unset, span.Status().Code)
+	}
+	assert.Equal(t, []string{"BEGIN", "ROLLBACK"}, names)
+}
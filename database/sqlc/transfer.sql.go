@@ -7,26 +7,43 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
 )
 
+type CopyTransfersParams struct {
+	ID            int64     `json:"id"`
+	FromAccountID int64     `json:"from_account_id"`
+	ToAccountID   int64     `json:"to_account_id"`
+	Amount        float64   `json:"amount"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
 const createTransfer = `-- name: CreateTransfer :one
 INSERT INTO transfers (
 	from_account_id,
     to_account_id,
-    amount
+    amount,
+    reverses_transfer_id
 ) VALUES (
-	$1, $2, $3
-) RETURNING id, from_account_id, to_account_id, amount, created_at
+	$1, $2, $3, $4
+) RETURNING id, from_account_id, to_account_id, amount, created_at, reverses_transfer_id
 `
 
 type CreateTransferParams struct {
-	FromAccountID int64   `json:"from_account_id"`
-	ToAccountID   int64   `json:"to_account_id"`
-	Amount        float64 `json:"amount"`
+	FromAccountID      int64         `json:"from_account_id"`
+	ToAccountID        int64         `json:"to_account_id"`
+	Amount             float64       `json:"amount"`
+	ReversesTransferID sql.NullInt64 `json:"reverses_transfer_id"`
 }
 
 func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
-	row := q.db.QueryRowContext(ctx, createTransfer, arg.FromAccountID, arg.ToAccountID, arg.Amount)
+	row := q.db.QueryRow(ctx, createTransfer,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.ReversesTransferID,
+	)
 	var i Transfer
 	err := row.Scan(
 		&i.ID,
@@ -34,6 +51,7 @@ func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams)
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.ReversesTransferID,
 	)
 	return i, err
 }
@@ -43,17 +61,60 @@ DELETE FROM transfers WHERE id = $1
 `
 
 func (q *Queries) DeleteTransfer(ctx context.Context, id int64) error {
-	_, err := q.db.ExecContext(ctx, deleteTransfer, id)
+	_, err := q.db.Exec(ctx, deleteTransfer, id)
 	return err
 }
 
+const exportTransfers = `-- name: ExportTransfers :many
+SELECT id, from_account_id, to_account_id, amount, created_at, reverses_transfer_id FROM (
+    SELECT id, from_account_id, to_account_id, amount, created_at, reverses_transfer_id FROM transfers
+    UNION ALL
+    SELECT id, from_account_id, to_account_id, amount, created_at, reverses_transfer_id FROM transfers_archive
+) AS transfers
+WHERE id > $1::bigint
+ORDER BY id
+LIMIT $2
+`
+
+type ExportTransfersParams struct {
+	AfterID    int64 `json:"after_id"`
+	LimitCount int32 `json:"limit_count"`
+}
+
+func (q *Queries) ExportTransfers(ctx context.Context, arg ExportTransfersParams) ([]Transfer, error) {
+	rows, err := q.db.Query(ctx, exportTransfers, arg.AfterID, arg.LimitCount)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.ReversesTransferID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getTransfer = `-- name: GetTransfer :one
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
+SELECT id, from_account_id, to_account_id, amount, created_at, reverses_transfer_id FROM transfers
 WHERE id = $1 LIMIT 1
 `
 
 func (q *Queries) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
-	row := q.db.QueryRowContext(ctx, getTransfer, id)
+	row := q.db.QueryRow(ctx, getTransfer, id)
 	var i Transfer
 	err := row.Scan(
 		&i.ID,
@@ -61,33 +122,203 @@ func (q *Queries) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.ReversesTransferID,
+	)
+	return i, err
+}
+
+const getTransferForUpdate = `-- name: GetTransferForUpdate :one
+SELECT id, from_account_id, to_account_id, amount, created_at, reverses_transfer_id FROM transfers
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error) {
+	row := q.db.QueryRow(ctx, getTransferForUpdate, id)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.ReversesTransferID,
+	)
+	return i, err
+}
+
+const getTransferReversal = `-- name: GetTransferReversal :one
+SELECT id, from_account_id, to_account_id, amount, created_at, reverses_transfer_id FROM transfers
+WHERE reverses_transfer_id = $1 LIMIT 1
+`
+
+func (q *Queries) GetTransferReversal(ctx context.Context, reversesTransferID sql.NullInt64) (Transfer, error) {
+	row := q.db.QueryRow(ctx, getTransferReversal, reversesTransferID)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.ReversesTransferID,
 	)
 	return i, err
 }
 
 const listTransfers = `-- name: ListTransfers :many
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
-WHERE
-    from_account_id = $1 OR
-    to_account_id = $2
+SELECT id, from_account_id, to_account_id, amount, created_at, reverses_transfer_id FROM transfers
+WHERE (from_account_id = $1 OR to_account_id = $2)
+    AND created_at >= coalesce($3::timestamp, '-infinity')
+    AND created_at < coalesce($4::timestamp, 'infinity')
 ORDER BY id
-LIMIT $3
-OFFSET $4
+LIMIT $6
+OFFSET $5
 `
 
 type ListTransfersParams struct {
-	FromAccountID int64 `json:"from_account_id"`
-	ToAccountID   int64 `json:"to_account_id"`
-	Limit         int32 `json:"limit"`
-	Offset        int32 `json:"offset"`
+	FromAccountID int64        `json:"from_account_id"`
+	ToAccountID   int64        `json:"to_account_id"`
+	StartTime     sql.NullTime `json:"start_time"`
+	EndTime       sql.NullTime `json:"end_time"`
+	OffsetCount   int32        `json:"offset_count"`
+	LimitCount    int32        `json:"limit_count"`
 }
 
 func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error) {
-	rows, err := q.db.QueryContext(ctx, listTransfers,
+	rows, err := q.db.Query(ctx, listTransfers,
 		arg.FromAccountID,
 		arg.ToAccountID,
-		arg.Limit,
-		arg.Offset,
+		arg.StartTime,
+		arg.EndTime,
+		arg.OffsetCount,
+		arg.LimitCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.ReversesTransferID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransfersAfter = `-- name: ListTransfersAfter :many
+SELECT id, from_account_id, to_account_id, amount, created_at, reverses_transfer_id FROM transfers
+WHERE
+    (($1::text <> 'in' AND from_account_id = $2)
+        OR ($1::text <> 'out' AND to_account_id = $2))
+    AND created_at >= coalesce($3::timestamp, '-infinity')
+    AND created_at < coalesce($4::timestamp, 'infinity')
+    AND amount >= coalesce($5::float, 0)
+    AND amount <= coalesce($6::float, 'infinity')
+    AND (created_at, id) > ($7::timestamp, $8::bigint)
+ORDER BY created_at, id
+LIMIT $9
+`
+
+type ListTransfersAfterParams struct {
+	Direction      string          `json:"direction"`
+	AccountID      int64           `json:"account_id"`
+	StartTime      sql.NullTime    `json:"start_time"`
+	EndTime        sql.NullTime    `json:"end_time"`
+	MinAmount      sql.NullFloat64 `json:"min_amount"`
+	MaxAmount      sql.NullFloat64 `json:"max_amount"`
+	AfterCreatedAt time.Time       `json:"after_created_at"`
+	AfterID        int64           `json:"after_id"`
+	LimitCount     int32           `json:"limit_count"`
+}
+
+func (q *Queries) ListTransfersAfter(ctx context.Context, arg ListTransfersAfterParams) ([]Transfer, error) {
+	rows, err := q.db.Query(ctx, listTransfersAfter,
+		arg.Direction,
+		arg.AccountID,
+		arg.StartTime,
+		arg.EndTime,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.LimitCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.ReversesTransferID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransfersBefore = `-- name: ListTransfersBefore :many
+SELECT id, from_account_id, to_account_id, amount, created_at, reverses_transfer_id FROM transfers
+WHERE
+    (($1::text <> 'in' AND from_account_id = $2)
+        OR ($1::text <> 'out' AND to_account_id = $2))
+    AND created_at >= coalesce($3::timestamp, '-infinity')
+    AND created_at < coalesce($4::timestamp, 'infinity')
+    AND amount >= coalesce($5::float, 0)
+    AND amount <= coalesce($6::float, 'infinity')
+    AND (created_at, id) < ($7::timestamp, $8::bigint)
+ORDER BY created_at DESC, id DESC
+LIMIT $9
+`
+
+type ListTransfersBeforeParams struct {
+	Direction       string          `json:"direction"`
+	AccountID       int64           `json:"account_id"`
+	StartTime       sql.NullTime    `json:"start_time"`
+	EndTime         sql.NullTime    `json:"end_time"`
+	MinAmount       sql.NullFloat64 `json:"min_amount"`
+	MaxAmount       sql.NullFloat64 `json:"max_amount"`
+	BeforeCreatedAt time.Time       `json:"before_created_at"`
+	BeforeID        int64           `json:"before_id"`
+	LimitCount      int32           `json:"limit_count"`
+}
+
+func (q *Queries) ListTransfersBefore(ctx context.Context, arg ListTransfersBeforeParams) ([]Transfer, error) {
+	rows, err := q.db.Query(ctx, listTransfersBefore,
+		arg.Direction,
+		arg.AccountID,
+		arg.StartTime,
+		arg.EndTime,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.BeforeCreatedAt,
+		arg.BeforeID,
+		arg.LimitCount,
 	)
 	if err != nil {
 		return nil, err
@@ -102,16 +333,182 @@ func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([
 			&i.ToAccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.ReversesTransferID,
 		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
 	}
-	if err := rows.Close(); err != nil {
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransfersWithArchiveAfter = `-- name: ListTransfersWithArchiveAfter :many
+SELECT id, from_account_id, to_account_id, amount, created_at, reverses_transfer_id FROM (
+    SELECT id, from_account_id, to_account_id, amount, created_at, reverses_transfer_id FROM transfers
+    UNION ALL
+    SELECT id, from_account_id, to_account_id, amount, created_at, reverses_transfer_id FROM transfers_archive
+) AS transfers
+WHERE
+    (($1::text <> 'in' AND from_account_id = $2::bigint)
+        OR ($1::text <> 'out' AND to_account_id = $2::bigint))
+    AND created_at >= coalesce($3::timestamp, '-infinity')
+    AND created_at < coalesce($4::timestamp, 'infinity')
+    AND amount >= coalesce($5::float, 0)
+    AND amount <= coalesce($6::float, 'infinity')
+    AND (created_at, id) > ($7::timestamp, $8::bigint)
+ORDER BY created_at, id
+LIMIT $9
+`
+
+type ListTransfersWithArchiveAfterParams struct {
+	Direction      string          `json:"direction"`
+	AccountID      int64           `json:"account_id"`
+	StartTime      sql.NullTime    `json:"start_time"`
+	EndTime        sql.NullTime    `json:"end_time"`
+	MinAmount      sql.NullFloat64 `json:"min_amount"`
+	MaxAmount      sql.NullFloat64 `json:"max_amount"`
+	AfterCreatedAt time.Time       `json:"after_created_at"`
+	AfterID        int64           `json:"after_id"`
+	LimitCount     int32           `json:"limit_count"`
+}
+
+func (q *Queries) ListTransfersWithArchiveAfter(ctx context.Context, arg ListTransfersWithArchiveAfterParams) ([]Transfer, error) {
+	rows, err := q.db.Query(ctx, listTransfersWithArchiveAfter,
+		arg.Direction,
+		arg.AccountID,
+		arg.StartTime,
+		arg.EndTime,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.LimitCount,
+	)
+	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.ReversesTransferID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
 	return items, nil
 }
+
+const listTransfersWithArchiveBefore = `-- name: ListTransfersWithArchiveBefore :many
+SELECT id, from_account_id, to_account_id, amount, created_at, reverses_transfer_id FROM (
+    SELECT id, from_account_id, to_account_id, amount, created_at, reverses_transfer_id FROM transfers
+    UNION ALL
+    SELECT id, from_account_id, to_account_id, amount, created_at, reverses_transfer_id FROM transfers_archive
+) AS transfers
+WHERE
+    (($1::text <> 'in' AND from_account_id = $2::bigint)
+        OR ($1::text <> 'out' AND to_account_id = $2::bigint))
+    AND created_at >= coalesce($3::timestamp, '-infinity')
+    AND created_at < coalesce($4::timestamp, 'infinity')
+    AND amount >= coalesce($5::float, 0)
+    AND amount <= coalesce($6::float, 'infinity')
+    AND (created_at, id) < ($7::timestamp, $8::bigint)
+ORDER BY created_at DESC, id DESC
+LIMIT $9
+`
+
+type ListTransfersWithArchiveBeforeParams struct {
+	Direction       string          `json:"direction"`
+	AccountID       int64           `json:"account_id"`
+	StartTime       sql.NullTime    `json:"start_time"`
+	EndTime         sql.NullTime    `json:"end_time"`
+	MinAmount       sql.NullFloat64 `json:"min_amount"`
+	MaxAmount       sql.NullFloat64 `json:"max_amount"`
+	BeforeCreatedAt time.Time       `json:"before_created_at"`
+	BeforeID        int64           `json:"before_id"`
+	LimitCount      int32           `json:"limit_count"`
+}
+
+func (q *Queries) ListTransfersWithArchiveBefore(ctx context.Context, arg ListTransfersWithArchiveBeforeParams) ([]Transfer, error) {
+	rows, err := q.db.Query(ctx, listTransfersWithArchiveBefore,
+		arg.Direction,
+		arg.AccountID,
+		arg.StartTime,
+		arg.EndTime,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.BeforeCreatedAt,
+		arg.BeforeID,
+		arg.LimitCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.ReversesTransferID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const nextTransferIDs = `-- name: NextTransferIDs :many
+SELECT nextval(pg_get_serial_sequence('transfers', 'id'))::bigint AS id
+FROM generate_series(1, $1::int)
+`
+
+func (q *Queries) NextTransferIDs(ctx context.Context, count int32) ([]int64, error) {
+	rows, err := q.db.Query(ctx, nextTransferIDs, count)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+type RestoreTransfersParams struct {
+	ID                 int64         `json:"id"`
+	FromAccountID      int64         `json:"from_account_id"`
+	ToAccountID        int64         `json:"to_account_id"`
+	Amount             float64       `json:"amount"`
+	CreatedAt          time.Time     `json:"created_at"`
+	ReversesTransferID sql.NullInt64 `json:"reverses_transfer_id"`
+}
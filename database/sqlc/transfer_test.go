@@ -96,8 +96,8 @@ func TestQueries_ListTransfers(t *testing.T) {
 		listTransferArgs := ListTransfersParams{
 			FromAccountID: transfer.FromAccountId,
 			ToAccountID:   transfer.ToAccountId,
-			Limit:         5,
-			Offset:        5,
+			LimitCount:    5,
+			OffsetCount:   5,
 		}
 		transfers, err := testQueries.ListTransfers(context.Background(), listTransferArgs)
 		assert.NoError(t, err)
@@ -112,6 +112,53 @@ func TestQueries_ListTransfers(t *testing.T) {
 	cleanupTransfers(t, cleanupList)
 }
 
+func TestQueries_ListTransfersAfterFiltered(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	var transfers []Transfer
+	for _, arg := range []CreateTransferParams{
+		{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 5},
+		{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 50},
+		{FromAccountID: account2.ID, ToAccountID: account1.ID, Amount: 50},
+	} {
+		transfer, err := testQueries.CreateTransfer(context.Background(), arg)
+		assert.NoError(t, err)
+		transfers = append(transfers, transfer)
+	}
+
+	all, err := testQueries.ListTransfersAfter(context.Background(), ListTransfersAfterParams{
+		AccountID:  account1.ID,
+		LimitCount: 10,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, all, 3)
+
+	// only the large transfer sent by account1
+	sent, err := testQueries.ListTransfersAfter(context.Background(), ListTransfersAfterParams{
+		AccountID:  account1.ID,
+		Direction:  "out",
+		MinAmount:  sql.NullFloat64{Float64: 10, Valid: true},
+		LimitCount: 10,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, sent, 1)
+	assert.Equal(t, transfers[1].ID, sent[0].ID)
+
+	received, err := testQueries.ListTransfersAfter(context.Background(), ListTransfersAfterParams{
+		AccountID:  account1.ID,
+		Direction:  "in",
+		EndTime:    sql.NullTime{Time: transfers[0].CreatedAt, Valid: true},
+		LimitCount: 10,
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, received)
+
+	// the balances were never moved, so only the rows are removed
+	for _, transfer := range transfers {
+		assert.NoError(t, testQueries.DeleteTransfer(context.Background(), transfer.ID))
+	}
+}
+
 func undoTransfer(t *testing.T, transferId int64) {
 	t.Helper()
 	transfer, err := testQueries.GetTransfer(context.Background(), transferId)
@@ -0,0 +1,48 @@
+package db
+
+import (
+	"context"
+	"github.com/arpangoswami/backend-golang-dev/util"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func createRandomUser(t *testing.T) User {
+	t.Helper()
+	hashedPassword, err := util.HashPassword(util.RandomString(6))
+	assert.NoError(t, err)
+
+	arg := CreateUserParams{
+		Username:       util.RandomOwner(),
+		HashedPassword: hashedPassword,
+		FullName:       util.RandomOwner(),
+		Email:          util.RandomEmail(),
+	}
+
+	user, err := testQueries.CreateUser(context.Background(), arg)
+	assert.NoError(t, err)
+	assert.Equal(t, arg.Username, user.Username)
+	assert.Equal(t, arg.HashedPassword, user.HashedPassword)
+	assert.Equal(t, arg.FullName, user.FullName)
+	assert.Equal(t, arg.Email, user.Email)
+	assert.True(t, user.PasswordChangedAt.IsZero())
+	assert.NotZero(t, user.CreatedAt)
+	return user
+}
+
+func TestQueries_CreateUser(t *testing.T) {
+	createRandomUser(t)
+}
+
+func TestQueries_GetUser(t *testing.T) {
+	user1 := createRandomUser(t)
+	user2, err := testQueries.GetUser(context.Background(), user1.Username)
+	assert.NoError(t, err)
+	assert.Equal(t, user1.Username, user2.Username)
+	assert.Equal(t, user1.HashedPassword, user2.HashedPassword)
+	assert.Equal(t, user1.FullName, user2.FullName)
+	assert.Equal(t, user1.Email, user2.Email)
+	assert.WithinDurationf(t, user1.CreatedAt, user2.CreatedAt, time.Second,
+		"createdAt1 and createdAt2 should have max difference of 1s")
+}
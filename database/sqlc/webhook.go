@@ -0,0 +1,9 @@
+package db
+
+// Statuses of webhook_deliveries. Pending deliveries are retried until they succeed
+// or run out of attempts, dead ones are kept until they are replayed.
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryDead      = "dead"
+)
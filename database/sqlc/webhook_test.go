@@ -0,0 +1,130 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/arpangoswami/backend-golang-dev/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func createRandomWebhookSubscription(t *testing.T, owner string) WebhookSubscription {
+	arg := CreateWebhookSubscriptionParams{
+		Owner:      owner,
+		Url:        "https://example.com/" + util.RandomString(8),
+		EventTypes: []string{EventTransferCompleted, EventAccountFrozen},
+		Secret:     util.RandomString(32),
+	}
+	subscription, err := testQueries.CreateWebhookSubscription(context.Background(), arg)
+	assert.NoError(t, err)
+	assert.Equal(t, arg.EventTypes, subscription.EventTypes)
+	return subscription
+}
+
+func TestQueries_ListWebhookSubscriptionsForEvent(t *testing.T) {
+	user := createRandomUser(t)
+	subscription := createRandomWebhookSubscription(t, user.Username)
+
+	subscriptions, err := testQueries.ListWebhookSubscriptionsForEvent(context.Background(), ListWebhookSubscriptionsForEventParams{
+		Owners:    []string{user.Username, util.RandomOwner()},
+		EventType: EventTransferCompleted,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []WebhookSubscription{subscription}, subscriptions)
+
+	subscriptions, err = testQueries.ListWebhookSubscriptionsForEvent(context.Background(), ListWebhookSubscriptionsForEventParams{
+		Owners:    []string{user.Username},
+		EventType: EventAccountCreated,
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, subscriptions)
+}
+
+func TestQueries_WebhookDeliveryLifecycle(t *testing.T) {
+	ctx := context.Background()
+	subscription := createRandomWebhookSubscription(t, createRandomUser(t).Username)
+
+	// fanning the same event out twice queues a single delivery
+	arg := CreateWebhookDeliveryParams{
+		SubscriptionID: subscription.ID,
+		EventID:        util.RandomInt(1, 1_000_000),
+		EventType:      EventTransferCompleted,
+		Payload:        json.RawMessage(`{"id":1}`),
+	}
+	assert.NoError(t, testQueries.CreateWebhookDelivery(ctx, arg))
+	assert.NoError(t, testQueries.CreateWebhookDelivery(ctx, arg))
+	deliveries, err := testQueries.ListWebhookDeliveries(ctx, ListWebhookDeliveriesParams{SubscriptionID: subscription.ID, LimitCount: 10})
+	assert.NoError(t, err)
+	if !assert.Len(t, deliveries, 1) {
+		return
+	}
+	delivery := deliveries[0]
+	assert.Equal(t, DeliveryPending, delivery.Status)
+
+	claimed, err := testQueries.ClaimWebhookDeliveries(ctx, ClaimWebhookDeliveriesParams{
+		LeaseUntil: time.Now().Add(time.Minute),
+		LimitCount: 1000,
+	})
+	assert.NoError(t, err)
+	assert.Contains(t, deliveryIDs(claimed), delivery.ID)
+
+	// a leased delivery is not claimed again
+	claimed, err = testQueries.ClaimWebhookDeliveries(ctx, ClaimWebhookDeliveriesParams{
+		LeaseUntil: time.Now().Add(time.Minute),
+		LimitCount: 1000,
+	})
+	assert.NoError(t, err)
+	assert.NotContains(t, deliveryIDs(claimed), delivery.ID)
+
+	dead, err := testQueries.MarkWebhookDeliveryFailed(ctx, MarkWebhookDeliveryFailedParams{
+		ID:            delivery.ID,
+		Status:        DeliveryDead,
+		NextAttemptAt: time.Now(),
+		ResponseCode:  500,
+		LastError:     "receiver responded 500 Internal Server Error",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), dead.Attempts)
+
+	deliveries, err = testQueries.ListWebhookDeliveries(ctx, ListWebhookDeliveriesParams{
+		SubscriptionID: subscription.ID,
+		Status:         sql.NullString{String: DeliveryDead, Valid: true},
+		LimitCount:     10,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, deliveries, 1)
+
+	replayed, err := testQueries.ReplayDeadWebhookDeliveries(ctx, subscription.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), replayed)
+
+	delivered, err := testQueries.MarkWebhookDeliverySucceeded(ctx, MarkWebhookDeliverySucceededParams{ID: delivery.ID, ResponseCode: 204})
+	assert.NoError(t, err)
+	assert.Equal(t, DeliverySucceeded, delivered.Status)
+	assert.Equal(t, int32(1), delivered.Attempts)
+	assert.Empty(t, delivered.LastError)
+	assert.True(t, delivered.DeliveredAt.Valid)
+
+	delivery, err = testQueries.ReplayWebhookDelivery(ctx, delivery.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, DeliveryPending, delivery.Status)
+	assert.Zero(t, delivery.Attempts)
+	assert.False(t, delivery.DeliveredAt.Valid)
+
+	// deleting the subscription deletes its history
+	_, err = testQueries.DeleteWebhookSubscription(ctx, DeleteWebhookSubscriptionParams{ID: subscription.ID, Owner: subscription.Owner})
+	assert.NoError(t, err)
+	_, err = testQueries.GetWebhookDelivery(ctx, delivery.ID)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func deliveryIDs(deliveries []WebhookDelivery) []int64 {
+	ids := make([]int64, len(deliveries))
+	for i, delivery := range deliveries {
+		ids[i] = delivery.ID
+	}
+	return ids
+}
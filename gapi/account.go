@@ -0,0 +1,67 @@
+package gapi
+
+import (
+	"context"
+
+	"github.com/arpangoswami/backend-golang-dev/pb"
+)
+
+func (server *Server) CreateAccount(ctx context.Context, req *pb.CreateAccountRequest) (*pb.Account, error) {
+	ctx, err := server.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if violations := collectViolations(validateCurrency("currency", req.GetCurrency())); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	account, err := server.bank.CreateAccount(ctx, req.GetCurrency())
+	if err != nil {
+		return nil, storeError(err)
+	}
+	return convertAccount(account), nil
+}
+
+func (server *Server) GetAccount(ctx context.Context, req *pb.GetAccountRequest) (*pb.Account, error) {
+	ctx, err := server.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if violations := collectViolations(validateID("id", req.GetId())); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	account, err := server.bank.GetAccount(ctx, req.GetId())
+	if err != nil {
+		return nil, storeError(err)
+	}
+	return convertAccount(account), nil
+}
+
+func (server *Server) ListAccounts(ctx context.Context, req *pb.ListAccountsRequest) (*pb.ListAccountsResponse, error) {
+	ctx, err := server.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if violations := collectViolations(validatePageSize("page_size", req.GetPageSize())); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	page, err := server.bank.ListAccounts(ctx, req.GetCursor(), req.GetPageSize())
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	rsp := &pb.ListAccountsResponse{
+		Accounts:   make([]*pb.Account, len(page.Items)),
+		NextCursor: page.NextCursor,
+		PrevCursor: page.PrevCursor,
+	}
+	for i, account := range page.Items {
+		rsp.Accounts[i] = convertAccount(account)
+	}
+	return rsp, nil
+}
@@ -0,0 +1,185 @@
+package gapi
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	mockdb "github.com/arpangoswami/backend-golang-dev/database/mock"
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+	"github.com/arpangoswami/backend-golang-dev/pb"
+	"github.com/arpangoswami/backend-golang-dev/util"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func randomAccount() db.Account {
+	currencyCountryCode := util.RandomCurrencyCodeCountryCode()
+	return db.Account{
+		ID:          util.RandomInt(1, 1000),
+		Owner:       util.RandomOwner(),
+		Balance:     util.RandomMoney(1000),
+		Currency:    currencyCountryCode.CurrencyCode,
+		CountryCode: currencyCountryCode.CountryCode,
+	}
+}
+
+func TestServer_CreateAccount(t *testing.T) {
+	account := randomAccount()
+	account.Balance = 0
+
+	testCases := []struct {
+		name          string
+		req           *pb.CreateAccountRequest
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, res *pb.Account, err error)
+	}{
+		{
+			name: "OK",
+			req:  &pb.CreateAccountRequest{Currency: account.Currency},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.CreateAccountParams{
+					Owner:       account.Owner,
+					Currency:    account.Currency,
+					CountryCode: account.CountryCode,
+				}
+				store.EXPECT().CreateAccount(gomock.Any(), gomock.Eq(arg)).Times(1).Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, res *pb.Account, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, account.ID, res.GetId())
+				assert.Equal(t, account.Owner, res.GetOwner())
+				assert.Equal(t, account.CountryCode.Int32, res.GetCountryCode())
+			},
+		},
+		{
+			name: "InvalidCurrency",
+			req:  &pb.CreateAccountRequest{Currency: "XYZ"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.Account, err error) {
+				assert.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "DuplicateAccount",
+			req:  &pb.CreateAccountRequest{Currency: account.Currency},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateAccount(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.Account{}, &pgconn.PgError{Code: uniqueViolation})
+			},
+			checkResponse: func(t *testing.T, res *pb.Account, err error) {
+				assert.Equal(t, codes.AlreadyExists, status.Code(err))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			ctx := newContextWithBearerToken(t, server.tokenMaker, account.Owner, time.Minute)
+			res, err := server.CreateAccount(ctx, tc.req)
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
+
+func TestServer_GetAccount(t *testing.T) {
+	account := randomAccount()
+
+	testCases := []struct {
+		name       string
+		id         int64
+		buildStubs func(store *mockdb.MockStore)
+		code       codes.Code
+	}{
+		{
+			name: "OK",
+			id:   account.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+			},
+			code: codes.OK,
+		},
+		{
+			name: "NotFound",
+			id:   account.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+			},
+			code: codes.NotFound,
+		},
+		{
+			name: "InternalError",
+			id:   account.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(1).Return(db.Account{}, sql.ErrConnDone)
+			},
+			code: codes.Internal,
+		},
+		{
+			name: "PermissionDenied",
+			id:   account.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				other := account
+				other.Owner = "unauthorized"
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(other, nil)
+			},
+			code: codes.PermissionDenied,
+		},
+		{
+			name: "InvalidID",
+			id:   0,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.InvalidArgument,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			ctx := newContextWithBearerToken(t, server.tokenMaker, account.Owner, time.Minute)
+			res, err := server.GetAccount(ctx, &pb.GetAccountRequest{Id: tc.id})
+			assert.Equal(t, tc.code, status.Code(err))
+			if tc.code == codes.OK {
+				assert.Equal(t, account.Balance, res.GetBalance())
+			}
+		})
+	}
+}
+
+func TestServer_ListAccounts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	owner := util.RandomOwner()
+	accounts := []db.Account{randomAccount(), randomAccount()}
+	store.EXPECT().ListAccountsByOwnerAfter(gomock.Any(), gomock.Eq(db.ListAccountsByOwnerAfterParams{Owner: owner, LimitCount: 6})).
+		Times(1).Return(accounts, nil)
+
+	server := newTestServer(t, store)
+	ctx := newContextWithBearerToken(t, server.tokenMaker, owner, time.Minute)
+	res, err := server.ListAccounts(ctx, &pb.ListAccountsRequest{PageSize: 5})
+	assert.NoError(t, err)
+	assert.Len(t, res.GetAccounts(), len(accounts))
+	assert.Empty(t, res.GetNextCursor())
+
+	_, err = server.ListAccounts(ctx, &pb.ListAccountsRequest{PageSize: 50})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = server.ListAccounts(ctx, &pb.ListAccountsRequest{PageSize: 5, Cursor: "not-a-cursor"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
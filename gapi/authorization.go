@@ -0,0 +1,68 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+	"github.com/arpangoswami/backend-golang-dev/token"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	authorizationHeader = "authorization"
+	authorizationBearer = "bearer"
+)
+
+// authenticate verifies the bearer token sent in the request metadata and returns a
+// context carrying the authenticated user. The gateway forwards the HTTP Authorization
+// header under the same metadata key, so REST and gRPC callers are checked alike.
+// The user and the x-request-id metadata are recorded in the audit log of the changes made, an
+// invalid request ID being replaced with a new one.
+func (server *Server) authenticate(ctx context.Context) (context.Context, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, unauthenticatedError(fmt.Errorf("missing metadata"))
+	}
+
+	values := md.Get(authorizationHeader)
+	if len(values) == 0 {
+		return nil, unauthenticatedError(fmt.Errorf("missing authorization header"))
+	}
+
+	fields := strings.Fields(values[0])
+	if len(fields) < 2 {
+		return nil, unauthenticatedError(fmt.Errorf("invalid authorization header format"))
+	}
+
+	authType := strings.ToLower(fields[0])
+	if authType != authorizationBearer {
+		return nil, unauthenticatedError(fmt.Errorf("unsupported authorization type: %s", authType))
+	}
+
+	payload, err := server.tokenMaker.VerifyToken(fields[1])
+	if err == nil && payload.TokenType != token.TokenTypeAccess {
+		err = token.ErrWrongTokenType
+	}
+	if err != nil {
+		return nil, unauthenticatedError(fmt.Errorf("invalid access token: %w", err))
+	}
+
+	ctx = db.WithActor(ctx, payload.Username)
+	if requestIDs := md.Get(requestIDHeader); len(requestIDs) > 0 {
+		requestID := requestIDs[0]
+		if !db.ValidRequestID(requestID) {
+			requestID = uuid.NewString()
+		}
+		ctx = db.WithRequestID(ctx, requestID)
+	}
+	return token.NewContext(ctx, payload), nil
+}
+
+func unauthenticatedError(err error) error {
+	return status.Errorf(codes.Unauthenticated, "unauthorized: %s", err)
+}
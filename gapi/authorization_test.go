@@ -0,0 +1,56 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+	"github.com/arpangoswami/backend-golang-dev/token"
+	"github.com/arpangoswami/backend-golang-dev/util"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestServer_AuthenticateAuditInfo(t *testing.T) {
+	server := newTestServer(t, nil)
+	username := util.RandomOwner()
+
+	ctx := newContextWithBearerToken(t, server.tokenMaker, username, time.Minute)
+	md, _ := metadata.FromIncomingContext(ctx)
+	md.Set(requestIDHeader, "req-123")
+
+	authCtx, err := server.authenticate(metadata.NewIncomingContext(ctx, md))
+	assert.NoError(t, err)
+	assert.Equal(t, db.AuditInfo{Actor: username, RequestID: "req-123"}, db.AuditFromContext(authCtx))
+
+	// an invalid request ID is replaced rather than written to the audit log
+	md.Set(requestIDHeader, "req-123\nforged")
+	authCtx, err = server.authenticate(metadata.NewIncomingContext(ctx, md))
+	assert.NoError(t, err)
+	assert.NoError(t, uuid.Validate(db.AuditFromContext(authCtx).RequestID))
+}
+
+func TestServer_AuthenticateRefreshToken(t *testing.T) {
+	server := newTestServer(t, nil)
+
+	refreshToken, _, err := server.tokenMaker.CreateToken(util.RandomOwner(), token.TokenTypeRefresh, time.Minute)
+	assert.NoError(t, err)
+	md := metadata.Pairs(authorizationHeader, fmt.Sprintf("%s %s", authorizationBearer, refreshToken))
+
+	_, err = server.authenticate(metadata.NewIncomingContext(context.Background(), md))
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestIncomingHeaderMatcher(t *testing.T) {
+	key, ok := incomingHeaderMatcher("X-Request-Id")
+	assert.True(t, ok)
+	assert.Equal(t, requestIDHeader, key)
+
+	_, ok = incomingHeaderMatcher("X-Custom")
+	assert.False(t, ok)
+}
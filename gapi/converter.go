@@ -0,0 +1,119 @@
+package gapi
+
+import (
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+	"github.com/arpangoswami/backend-golang-dev/pb"
+	"github.com/arpangoswami/backend-golang-dev/service"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func convertAccount(account db.Account) *pb.Account {
+	result := &pb.Account{
+		Id:        account.ID,
+		Owner:     account.Owner,
+		Balance:   account.Balance,
+		Currency:  account.Currency,
+		CreatedAt: timestamppb.New(account.CreatedAt),
+		IsFrozen:  account.IsFrozen,
+	}
+	if account.CountryCode.Valid {
+		result.CountryCode = &account.CountryCode.Int32
+	}
+	return result
+}
+
+func convertEntry(entry db.Entry) *pb.Entry {
+	return &pb.Entry{
+		Id:        entry.ID,
+		AccountId: entry.AccountID,
+		Amount:    entry.Amount,
+		CreatedAt: timestamppb.New(entry.CreatedAt),
+	}
+}
+
+func convertTransfer(transfer db.Transfer) *pb.Transfer {
+	result := &pb.Transfer{
+		Id:            transfer.ID,
+		FromAccountId: transfer.FromAccountID,
+		ToAccountId:   transfer.ToAccountID,
+		Amount:        transfer.Amount,
+		CreatedAt:     timestamppb.New(transfer.CreatedAt),
+	}
+	if transfer.ReversesTransferID.Valid {
+		result.ReversesTransferId = &transfer.ReversesTransferID.Int64
+	}
+	return result
+}
+
+func convertUser(user db.User) *pb.User {
+	return &pb.User{
+		Username:          user.Username,
+		FullName:          user.FullName,
+		Email:             user.Email,
+		PasswordChangedAt: timestamppb.New(user.PasswordChangedAt),
+		CreatedAt:         timestamppb.New(user.CreatedAt),
+	}
+}
+
+func convertSession(session db.Session) *pb.Session {
+	return &pb.Session{
+		Id:        session.ID.String(),
+		UserAgent: session.UserAgent,
+		ClientIp:  session.ClientIp,
+		IsRevoked: session.IsRevoked,
+		ExpiresAt: timestamppb.New(session.ExpiresAt),
+		CreatedAt: timestamppb.New(session.CreatedAt),
+	}
+}
+
+// convertWebhookSubscription leaves out the secret, which is only handed out when the subscription is created
+func convertWebhookSubscription(subscription db.WebhookSubscription) *pb.WebhookSubscription {
+	return &pb.WebhookSubscription{
+		Id:         subscription.ID,
+		Url:        subscription.Url,
+		EventTypes: subscription.EventTypes,
+		CreatedAt:  timestamppb.New(subscription.CreatedAt),
+	}
+}
+
+func convertWebhookDelivery(delivery db.WebhookDelivery) *pb.WebhookDelivery {
+	result := &pb.WebhookDelivery{
+		Id:               delivery.ID,
+		SubscriptionId:   delivery.SubscriptionID,
+		EventId:          delivery.EventID,
+		EventType:        delivery.EventType,
+		Payload:          string(delivery.Payload),
+		Status:           delivery.Status,
+		Attempts:         delivery.Attempts,
+		NextAttemptAt:    timestamppb.New(delivery.NextAttemptAt),
+		LastResponseCode: delivery.LastResponseCode,
+		LastError:        delivery.LastError,
+		CreatedAt:        timestamppb.New(delivery.CreatedAt),
+	}
+	if delivery.DeliveredAt.Valid {
+		result.DeliveredAt = timestamppb.New(delivery.DeliveredAt.Time)
+	}
+	return result
+}
+
+func convertListFilter(filter *pb.ListFilter) service.ListFilter {
+	result := service.ListFilter{
+		MinAmount:       filter.GetMinAmount(),
+		MaxAmount:       filter.GetMaxAmount(),
+		Descending:      filter.GetOrder() == pb.SortOrder_SORT_ORDER_DESC,
+		IncludeArchived: filter.GetIncludeArchived(),
+	}
+	switch filter.GetDirection() {
+	case pb.Direction_DIRECTION_IN:
+		result.Direction = service.DirectionIn
+	case pb.Direction_DIRECTION_OUT:
+		result.Direction = service.DirectionOut
+	}
+	if filter.GetStartTime() != nil {
+		result.StartTime = filter.GetStartTime().AsTime()
+	}
+	if filter.GetEndTime() != nil {
+		result.EndTime = filter.GetEndTime().AsTime()
+	}
+	return result
+}
@@ -0,0 +1,65 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+
+	"github.com/arpangoswami/backend-golang-dev/pb"
+	"github.com/arpangoswami/backend-golang-dev/service"
+)
+
+func (server *Server) GetEntry(ctx context.Context, req *pb.GetEntryRequest) (*pb.Entry, error) {
+	ctx, err := server.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if violations := collectViolations(validateID("id", req.GetId())); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	entry, err := server.bank.GetEntry(ctx, req.GetId())
+	if err != nil {
+		return nil, storeError(err)
+	}
+	return convertEntry(entry), nil
+}
+
+func (server *Server) ListEntries(ctx context.Context, req *pb.ListEntriesRequest) (*pb.ListEntriesResponse, error) {
+	ctx, err := server.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	violations := collectViolations(validatePageSize("page_size", req.GetPageSize()))
+	if len(req.GetAccountIds()) == 0 {
+		violations = append(violations, fieldViolation("account_ids", errors.New("must not be empty")))
+	}
+	for _, accountID := range req.GetAccountIds() {
+		violations = append(violations, collectViolations(validateID("account_ids", accountID))...)
+	}
+	violations = append(violations, validateListFilter("filter", req.GetFilter())...)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	page, err := server.bank.ListEntries(ctx, service.ListEntriesParams{
+		AccountIDs: req.GetAccountIds(),
+		Filter:     convertListFilter(req.GetFilter()),
+		Cursor:     req.GetCursor(),
+		PageSize:   req.GetPageSize(),
+	})
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	rsp := &pb.ListEntriesResponse{
+		Entries:    make([]*pb.Entry, len(page.Items)),
+		NextCursor: page.NextCursor,
+		PrevCursor: page.PrevCursor,
+	}
+	for i, entry := range page.Items {
+		rsp.Entries[i] = convertEntry(entry)
+	}
+	return rsp, nil
+}
@@ -0,0 +1,87 @@
+package gapi
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	mockdb "github.com/arpangoswami/backend-golang-dev/database/mock"
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+	"github.com/arpangoswami/backend-golang-dev/pb"
+	"github.com/arpangoswami/backend-golang-dev/util"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestServer_GetEntry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetEntry(gomock.Any(), gomock.Eq(int64(7))).Times(1).Return(db.Entry{}, sql.ErrNoRows)
+
+	server := newTestServer(t, store)
+	ctx := newContextWithBearerToken(t, server.tokenMaker, util.RandomOwner(), time.Minute)
+	_, err := server.GetEntry(ctx, &pb.GetEntryRequest{Id: 7})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestServer_ListEntries(t *testing.T) {
+	owner := util.RandomOwner()
+	entries := []db.Entry{
+		{ID: 1, AccountID: 3, Amount: 10},
+		{ID: 2, AccountID: 4, Amount: -10},
+	}
+
+	testCases := []struct {
+		name       string
+		req        *pb.ListEntriesRequest
+		buildStubs func(store *mockdb.MockStore)
+		code       codes.Code
+	}{
+		{
+			name: "OK",
+			req:  &pb.ListEntriesRequest{AccountIds: []int64{3, 4}, PageSize: 5},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(int64(3))).Times(1).Return(db.Account{ID: 3, Owner: owner}, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(int64(4))).Times(1).Return(db.Account{ID: 4, Owner: owner}, nil)
+				arg := db.ListEntriesAfterParams{AccountIds: []int64{3, 4}, LimitCount: 6}
+				store.EXPECT().ListEntriesAfter(gomock.Any(), gomock.Eq(arg)).Times(1).Return(entries, nil)
+			},
+			code: codes.OK,
+		},
+		{
+			name: "PermissionDenied",
+			req:  &pb.ListEntriesRequest{AccountIds: []int64{3}, PageSize: 5},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(int64(3))).Times(1).Return(db.Account{ID: 3, Owner: "other"}, nil)
+				store.EXPECT().ListEntriesAfter(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.PermissionDenied,
+		},
+		{
+			name: "MissingAccountIDs",
+			req:  &pb.ListEntriesRequest{PageSize: 5},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListEntriesAfter(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.InvalidArgument,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			ctx := newContextWithBearerToken(t, server.tokenMaker, owner, time.Minute)
+			res, err := server.ListEntries(ctx, tc.req)
+			assert.Equal(t, tc.code, status.Code(err))
+			if tc.code == codes.OK {
+				assert.Len(t, res.GetEntries(), len(entries))
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package gapi
+
+import (
+	"database/sql"
+	"errors"
+
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+	"github.com/arpangoswami/backend-golang-dev/pagination"
+	"github.com/arpangoswami/backend-golang-dev/service"
+	"github.com/arpangoswami/backend-golang-dev/webhook"
+	"github.com/jackc/pgx/v5/pgconn"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Postgres error codes that are mapped to client errors
+const (
+	foreignKeyViolation = "23503"
+	uniqueViolation     = "23505"
+	checkViolation      = "23514"
+)
+
+func fieldViolation(field string, err error) *errdetails.BadRequest_FieldViolation {
+	return &errdetails.BadRequest_FieldViolation{
+		Field:       field,
+		Description: err.Error(),
+	}
+}
+
+func invalidArgumentError(violations []*errdetails.BadRequest_FieldViolation) error {
+	badRequest := &errdetails.BadRequest{FieldViolations: violations}
+	statusInvalid := status.New(codes.InvalidArgument, "invalid parameters")
+
+	statusDetails, err := statusInvalid.WithDetails(badRequest)
+	if err != nil {
+		return statusInvalid.Err()
+	}
+	return statusDetails.Err()
+}
+
+// reasonTransferArchived tags the FailedPrecondition of an archived transfer, which the
+// gateway answers with 410 Gone like the REST API
+const reasonTransferArchived = "TRANSFER_ARCHIVED"
+
+func archivedError(err error) error {
+	statusArchived := status.New(codes.FailedPrecondition, err.Error())
+
+	statusDetails, detailsErr := statusArchived.WithDetails(&errdetails.ErrorInfo{Reason: reasonTransferArchived})
+	if detailsErr != nil {
+		return statusArchived.Err()
+	}
+	return statusDetails.Err()
+}
+
+// storeError maps an error returned by the store or the service layer to a gRPC status
+func storeError(err error) error {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, service.ErrUnauthenticated):
+		return status.Error(codes.Unauthenticated, err.Error())
+	case errors.Is(err, service.ErrForbidden):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, service.ErrCurrencyMismatch), errors.Is(err, pagination.ErrInvalidCursor),
+		errors.Is(err, webhook.ErrForbiddenDestination):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, db.ErrAccountFrozen):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, db.ErrTransferArchived):
+		return archivedError(err)
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case uniqueViolation:
+			return status.Error(codes.AlreadyExists, err.Error())
+		case foreignKeyViolation, checkViolation:
+			return status.Error(codes.FailedPrecondition, err.Error())
+		}
+	}
+	return status.Error(codes.Internal, err.Error())
+}
@@ -0,0 +1,33 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+	"github.com/arpangoswami/backend-golang-dev/token"
+	"github.com/arpangoswami/backend-golang-dev/util"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/metadata"
+)
+
+func newTestServer(t *testing.T, store db.Store) *Server {
+	t.Helper()
+	tokenMaker, err := token.NewPasetoMaker(util.RandomString(32))
+	assert.NoError(t, err)
+	return NewServer(store, tokenMaker, time.Minute, time.Hour)
+}
+
+// newContextWithBearerToken returns an incoming context authorized as username
+func newContextWithBearerToken(t *testing.T, tokenMaker token.Maker, username string, duration time.Duration) context.Context {
+	t.Helper()
+	accessToken, _, err := tokenMaker.CreateToken(username, token.TokenTypeAccess, duration)
+	assert.NoError(t, err)
+
+	md := metadata.MD{
+		authorizationHeader: []string{fmt.Sprintf("%s %s", authorizationBearer, accessToken)},
+	}
+	return metadata.NewIncomingContext(context.Background(), md)
+}
@@ -0,0 +1,118 @@
+package gapi
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+	"github.com/arpangoswami/backend-golang-dev/pb"
+	"github.com/arpangoswami/backend-golang-dev/service"
+	"github.com/arpangoswami/backend-golang-dev/token"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// Server serves gRPC requests for our banking service
+type Server struct {
+	pb.UnimplementedAccountServiceServer
+	pb.UnimplementedEntryServiceServer
+	pb.UnimplementedTransferServiceServer
+	pb.UnimplementedUserServiceServer
+	pb.UnimplementedWebhookServiceServer
+	store                db.Store
+	bank                 *service.Bank
+	tokenMaker           token.Maker
+	accessTokenDuration  time.Duration
+	refreshTokenDuration time.Duration
+}
+
+// NewServer creates a new gRPC server backed by the store
+func NewServer(store db.Store, tokenMaker token.Maker, accessTokenDuration, refreshTokenDuration time.Duration) *Server {
+	return &Server{
+		store:                store,
+		bank:                 service.NewBank(store),
+		tokenMaker:           tokenMaker,
+		accessTokenDuration:  accessTokenDuration,
+		refreshTokenDuration: refreshTokenDuration,
+	}
+}
+
+// Register attaches every service implemented by the server to a gRPC server
+func (server *Server) Register(grpcServer *grpc.Server) {
+	pb.RegisterAccountServiceServer(grpcServer, server)
+	pb.RegisterEntryServiceServer(grpcServer, server)
+	pb.RegisterTransferServiceServer(grpcServer, server)
+	pb.RegisterUserServiceServer(grpcServer, server)
+	pb.RegisterWebhookServiceServer(grpcServer, server)
+}
+
+// NewGatewayHandler returns an HTTP handler translating REST calls into calls on the same
+// server implementation, so gRPC and REST clients share one code path
+func (server *Server) NewGatewayHandler(ctx context.Context) (http.Handler, error) {
+	mux := runtime.NewServeMux(
+		runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
+			MarshalOptions: protojson.MarshalOptions{
+				UseProtoNames:   true,
+				EmitUnpopulated: true,
+			},
+			UnmarshalOptions: protojson.UnmarshalOptions{
+				DiscardUnknown: true,
+			},
+		}),
+		runtime.WithIncomingHeaderMatcher(incomingHeaderMatcher),
+		runtime.WithErrorHandler(gatewayErrorHandler),
+	)
+
+	if err := pb.RegisterAccountServiceHandlerServer(ctx, mux, server); err != nil {
+		return nil, err
+	}
+	if err := pb.RegisterEntryServiceHandlerServer(ctx, mux, server); err != nil {
+		return nil, err
+	}
+	if err := pb.RegisterTransferServiceHandlerServer(ctx, mux, server); err != nil {
+		return nil, err
+	}
+	if err := pb.RegisterUserServiceHandlerServer(ctx, mux, server); err != nil {
+		return nil, err
+	}
+	if err := pb.RegisterWebhookServiceHandlerServer(ctx, mux, server); err != nil {
+		return nil, err
+	}
+	return mux, nil
+}
+
+// gatewayErrorHandler writes errors like runtime.DefaultHTTPErrorHandler, except for the statuses
+// the REST API answers with a code no gRPC code translates to
+func gatewayErrorHandler(ctx context.Context, mux *runtime.ServeMux, marshaler runtime.Marshaler, w http.ResponseWriter, r *http.Request, err error) {
+	if st, ok := status.FromError(err); ok {
+		for _, detail := range st.Details() {
+			if info, ok := detail.(*errdetails.ErrorInfo); ok && info.GetReason() == reasonTransferArchived {
+				w = statusWriter{ResponseWriter: w, code: http.StatusGone}
+			}
+		}
+	}
+	runtime.DefaultHTTPErrorHandler(ctx, mux, marshaler, w, r, err)
+}
+
+// statusWriter replaces the status code written by the default error handler
+type statusWriter struct {
+	http.ResponseWriter
+	code int
+}
+
+func (w statusWriter) WriteHeader(int) {
+	w.ResponseWriter.WriteHeader(w.code)
+}
+
+// incomingHeaderMatcher also forwards X-Request-ID, which is not a permanent HTTP header
+func incomingHeaderMatcher(key string) (string, bool) {
+	if strings.EqualFold(key, requestIDHeader) {
+		return requestIDHeader, true
+	}
+	return runtime.DefaultHeaderMatcher(key)
+}
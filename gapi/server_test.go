@@ -0,0 +1,94 @@
+package gapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/arpangoswami/backend-golang-dev/database/mock"
+	"github.com/arpangoswami/backend-golang-dev/pb"
+	"github.com/arpangoswami/backend-golang-dev/token"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestServer_GRPC(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	account := randomAccount()
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+
+	server := newTestServer(t, store)
+	listener := bufconn.Listen(1024 * 1024)
+	grpcServer := grpc.NewServer()
+	server.Register(grpcServer)
+	go grpcServer.Serve(listener)
+	defer grpcServer.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.NoError(t, err)
+	defer conn.Close()
+	client := pb.NewAccountServiceClient(conn)
+
+	_, err = client.GetAccount(context.Background(), &pb.GetAccountRequest{Id: account.ID})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	accessToken, _, err := server.tokenMaker.CreateToken(account.Owner, token.TokenTypeAccess, time.Minute)
+	assert.NoError(t, err)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), authorizationHeader, "Bearer "+accessToken)
+
+	res, err := client.GetAccount(ctx, &pb.GetAccountRequest{Id: account.ID})
+	assert.NoError(t, err)
+	assert.Equal(t, account.Owner, res.GetOwner())
+}
+
+func TestServer_Gateway(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	account := randomAccount()
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+
+	server := newTestServer(t, store)
+	handler, err := server.NewGatewayHandler(context.Background())
+	assert.NoError(t, err)
+
+	accessToken, _, err := server.tokenMaker.CreateToken(account.Owner, token.TokenTypeAccess, time.Minute)
+	assert.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/v1/accounts/%d", account.ID), nil)
+	request.Header.Set("Authorization", "Bearer "+accessToken)
+	handler.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var body map[string]any
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+	assert.Equal(t, account.Owner, body["owner"])
+	assert.Equal(t, account.Currency, body["currency"])
+
+	recorder = httptest.NewRecorder()
+	request = httptest.NewRequest(http.MethodGet, "/v1/accounts/0", nil)
+	request.Header.Set("Authorization", "Bearer "+accessToken)
+	handler.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/v1/accounts/%d", account.ID), nil))
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
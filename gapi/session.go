@@ -0,0 +1,103 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+	"github.com/arpangoswami/backend-golang-dev/pb"
+	"github.com/arpangoswami/backend-golang-dev/token"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// RenewAccessToken exchanges a refresh token for a new access token, rotating the refresh token
+func (server *Server) RenewAccessToken(ctx context.Context, req *pb.RenewAccessTokenRequest) (*pb.RenewAccessTokenResponse, error) {
+	if req.GetRefreshToken() == "" {
+		return nil, invalidArgumentError(collectViolations(fieldViolation("refresh_token", errors.New("must not be empty"))))
+	}
+
+	session, err := server.bank.RefreshSession(ctx, server.tokenMaker, req.GetRefreshToken())
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	accessToken, accessPayload, err := server.tokenMaker.CreateToken(session.Username, token.TokenTypeAccess, server.accessTokenDuration)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create access token: %s", err)
+	}
+	refreshToken, newRefreshPayload, err := server.tokenMaker.CreateToken(session.Username, token.TokenTypeRefresh, server.refreshTokenDuration)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create refresh token: %s", err)
+	}
+
+	mtdt := server.extractMetadata(ctx)
+	newSession, err := server.store.RotateSessionTransaction(ctx, db.RotateSessionTransactionParams{
+		OldSessionID: session.ID,
+		NewSession: db.CreateSessionParams{
+			ID:           newRefreshPayload.ID,
+			Username:     session.Username,
+			RefreshToken: refreshToken,
+			UserAgent:    mtdt.UserAgent,
+			ClientIp:     mtdt.ClientIP,
+			ExpiresAt:    newRefreshPayload.ExpiredAt,
+		},
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, unauthenticatedError(errors.New("session is revoked"))
+		}
+		return nil, storeError(err)
+	}
+
+	return &pb.RenewAccessTokenResponse{
+		SessionId:             newSession.ID.String(),
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  timestamppb.New(accessPayload.ExpiredAt),
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: timestamppb.New(newRefreshPayload.ExpiredAt),
+	}, nil
+}
+
+func (server *Server) ListSessions(ctx context.Context, req *pb.ListSessionsRequest) (*pb.ListSessionsResponse, error) {
+	ctx, err := server.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if violations := validatePage(req.GetPageId(), req.GetPageSize()); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	sessions, err := server.bank.ListSessions(ctx, req.GetPageSize(), (req.GetPageId()-1)*req.GetPageSize())
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	rsp := &pb.ListSessionsResponse{Sessions: make([]*pb.Session, len(sessions))}
+	for i, session := range sessions {
+		rsp.Sessions[i] = convertSession(session)
+	}
+	return rsp, nil
+}
+
+func (server *Server) RevokeSession(ctx context.Context, req *pb.RevokeSessionRequest) (*pb.Session, error) {
+	ctx, err := server.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionID, err := uuid.Parse(req.GetId())
+	if err != nil {
+		return nil, invalidArgumentError(collectViolations(fieldViolation("id", err)))
+	}
+
+	session, err := server.bank.RevokeSession(ctx, sessionID)
+	if err != nil {
+		return nil, storeError(err)
+	}
+	return convertSession(session), nil
+}
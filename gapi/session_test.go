@@ -0,0 +1,190 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	mockdb "github.com/arpangoswami/backend-golang-dev/database/mock"
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+	"github.com/arpangoswami/backend-golang-dev/pb"
+	"github.com/arpangoswami/backend-golang-dev/token"
+	"github.com/arpangoswami/backend-golang-dev/util"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestServer_RenewAccessToken(t *testing.T) {
+	username := util.RandomOwner()
+
+	testCases := []struct {
+		name         string
+		tokenType    token.TokenType
+		buildSession func(session *db.Session)
+		buildStubs   func(store *mockdb.MockStore, session db.Session)
+		code         codes.Code
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil)
+				store.EXPECT().RotateSessionTransaction(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, arg db.RotateSessionTransactionParams) (db.Session, error) {
+						assert.Equal(t, session.ID, arg.OldSessionID)
+						return db.Session{ID: arg.NewSession.ID, Username: username}, nil
+					})
+			},
+			code: codes.OK,
+		},
+		{
+			name: "RevokedSession",
+			buildSession: func(session *db.Session) {
+				session.IsRevoked = true
+			},
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil)
+				store.EXPECT().RotateSessionTransaction(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.Unauthenticated,
+		},
+		{
+			name: "SessionNotFound",
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Any()).Times(1).Return(db.Session{}, sql.ErrNoRows)
+				store.EXPECT().RotateSessionTransaction(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.Unauthenticated,
+		},
+		{
+			name: "RevokedConcurrently",
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil)
+				store.EXPECT().RotateSessionTransaction(gomock.Any(), gomock.Any()).Times(1).Return(db.Session{}, sql.ErrNoRows)
+			},
+			code: codes.Unauthenticated,
+		},
+		{
+			name:      "AccessToken",
+			tokenType: token.TokenTypeAccess,
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.Unauthenticated,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			server := newTestServer(t, store)
+
+			tokenType := token.TokenTypeRefresh
+			if tc.tokenType != "" {
+				tokenType = tc.tokenType
+			}
+			refreshToken, payload, err := server.tokenMaker.CreateToken(username, tokenType, time.Hour)
+			assert.NoError(t, err)
+			session := db.Session{
+				ID:           payload.ID,
+				Username:     username,
+				RefreshToken: refreshToken,
+				ExpiresAt:    payload.ExpiredAt,
+			}
+			if tc.buildSession != nil {
+				tc.buildSession(&session)
+			}
+			tc.buildStubs(store, session)
+
+			res, err := server.RenewAccessToken(context.Background(), &pb.RenewAccessTokenRequest{RefreshToken: refreshToken})
+			assert.Equal(t, tc.code, status.Code(err))
+			if tc.code == codes.OK {
+				accessPayload, err := server.tokenMaker.VerifyToken(res.GetAccessToken())
+				assert.NoError(t, err)
+				assert.Equal(t, username, accessPayload.Username)
+				assert.NotEqual(t, refreshToken, res.GetRefreshToken())
+			}
+		})
+	}
+}
+
+func TestServer_ListSessions(t *testing.T) {
+	username := util.RandomOwner()
+	sessions := []db.Session{
+		{ID: uuid.New(), Username: username, RefreshToken: util.RandomString(32)},
+		{ID: uuid.New(), Username: username, RefreshToken: util.RandomString(32)},
+	}
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	arg := db.ListSessionsParams{Username: username, Limit: 5, Offset: 0}
+	store.EXPECT().ListSessions(gomock.Any(), gomock.Eq(arg)).Times(1).Return(sessions, nil)
+
+	server := newTestServer(t, store)
+	ctx := newContextWithBearerToken(t, server.tokenMaker, username, time.Minute)
+	res, err := server.ListSessions(ctx, &pb.ListSessionsRequest{PageId: 1, PageSize: 5})
+	assert.NoError(t, err)
+	assert.Len(t, res.GetSessions(), len(sessions))
+
+	_, err = server.ListSessions(context.Background(), &pb.ListSessionsRequest{PageId: 1, PageSize: 5})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestServer_RevokeSession(t *testing.T) {
+	username := util.RandomOwner()
+	sessionID := uuid.New()
+
+	testCases := []struct {
+		name       string
+		sessionID  string
+		buildStubs func(store *mockdb.MockStore)
+		code       codes.Code
+	}{
+		{
+			name:      "OK",
+			sessionID: sessionID.String(),
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.RevokeSessionParams{ID: sessionID, Username: username}
+				store.EXPECT().RevokeSession(gomock.Any(), gomock.Eq(arg)).Times(1).
+					Return(db.Session{ID: sessionID, Username: username, IsRevoked: true}, nil)
+			},
+			code: codes.OK,
+		},
+		{
+			name:      "NotFound",
+			sessionID: sessionID.String(),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().RevokeSession(gomock.Any(), gomock.Any()).Times(1).Return(db.Session{}, sql.ErrNoRows)
+			},
+			code: codes.NotFound,
+		},
+		{
+			name:      "InvalidID",
+			sessionID: "not-a-uuid",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().RevokeSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.InvalidArgument,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			ctx := newContextWithBearerToken(t, server.tokenMaker, username, time.Minute)
+			res, err := server.RevokeSession(ctx, &pb.RevokeSessionRequest{Id: tc.sessionID})
+			assert.Equal(t, tc.code, status.Code(err))
+			if tc.code == codes.OK {
+				assert.True(t, res.GetIsRevoked())
+			}
+		})
+	}
+}
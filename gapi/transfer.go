@@ -0,0 +1,101 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+
+	"github.com/arpangoswami/backend-golang-dev/pb"
+	"github.com/arpangoswami/backend-golang-dev/service"
+)
+
+func (server *Server) CreateTransfer(ctx context.Context, req *pb.CreateTransferRequest) (*pb.CreateTransferResponse, error) {
+	ctx, err := server.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	violations := collectViolations(
+		validateID("from_account_id", req.GetFromAccountId()),
+		validateID("to_account_id", req.GetToAccountId()),
+		validateCurrency("currency", req.GetCurrency()),
+	)
+	if req.GetFromAccountId() == req.GetToAccountId() {
+		violations = append(violations, fieldViolation("to_account_id", errors.New("must differ from from_account_id")))
+	}
+	if req.GetAmount() <= 0 {
+		violations = append(violations, fieldViolation("amount", errors.New("must be positive")))
+	}
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	result, err := server.bank.Transfer(ctx, service.TransferParams{
+		FromAccountID: req.GetFromAccountId(),
+		ToAccountID:   req.GetToAccountId(),
+		Amount:        req.GetAmount(),
+		Currency:      req.GetCurrency(),
+	})
+	if err != nil {
+		return nil, storeError(err)
+	}
+	return &pb.CreateTransferResponse{
+		Transfer:    convertTransfer(result.Transfer),
+		FromAccount: convertAccount(result.FromAccount),
+		ToAccount:   convertAccount(result.ToAccount),
+		FromEntry:   convertEntry(result.FromEntry),
+		ToEntry:     convertEntry(result.ToEntry),
+	}, nil
+}
+
+func (server *Server) GetTransfer(ctx context.Context, req *pb.GetTransferRequest) (*pb.Transfer, error) {
+	ctx, err := server.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if violations := collectViolations(validateID("id", req.GetId())); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	transfer, err := server.bank.GetTransfer(ctx, req.GetId(), req.GetIncludeArchived())
+	if err != nil {
+		return nil, storeError(err)
+	}
+	return convertTransfer(transfer), nil
+}
+
+func (server *Server) ListTransfers(ctx context.Context, req *pb.ListTransfersRequest) (*pb.ListTransfersResponse, error) {
+	ctx, err := server.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	violations := collectViolations(
+		validateID("account_id", req.GetAccountId()),
+		validatePageSize("page_size", req.GetPageSize()),
+	)
+	violations = append(violations, validateListFilter("filter", req.GetFilter())...)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	page, err := server.bank.ListTransfers(ctx, service.ListTransfersParams{
+		AccountID: req.GetAccountId(),
+		Filter:    convertListFilter(req.GetFilter()),
+		Cursor:    req.GetCursor(),
+		PageSize:  req.GetPageSize(),
+	})
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	rsp := &pb.ListTransfersResponse{
+		Transfers:  make([]*pb.Transfer, len(page.Items)),
+		NextCursor: page.NextCursor,
+		PrevCursor: page.PrevCursor,
+	}
+	for i, transfer := range page.Items {
+		rsp.Transfers[i] = convertTransfer(transfer)
+	}
+	return rsp, nil
+}
@@ -0,0 +1,193 @@
+package gapi
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	mockdb "github.com/arpangoswami/backend-golang-dev/database/mock"
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+	"github.com/arpangoswami/backend-golang-dev/pb"
+	"github.com/arpangoswami/backend-golang-dev/util"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestServer_CreateTransfer(t *testing.T) {
+	account1 := randomAccount()
+	account2 := randomAccount()
+	account3 := randomAccount()
+	account1.ID, account2.ID, account3.ID = 1, 2, 3
+	account1.Currency = util.USD
+	account2.Currency = util.USD
+	account3.Currency = util.INR
+
+	testCases := []struct {
+		name       string
+		req        *pb.CreateTransferRequest
+		buildStubs func(store *mockdb.MockStore)
+		code       codes.Code
+	}{
+		{
+			name: "OK",
+			req:  &pb.CreateTransferRequest{FromAccountId: 1, ToAccountId: 2, Amount: 10, Currency: util.USD},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				arg := db.TransferTransactionParams{FromAccountID: 1, ToAccountID: 2, Amount: 10}
+				store.EXPECT().TransferTransaction(gomock.Any(), gomock.Eq(arg)).Times(1).
+					Return(db.TransferTransactionResult{Transfer: db.Transfer{ID: 9}}, nil)
+			},
+			code: codes.OK,
+		},
+		{
+			name: "CurrencyMismatch",
+			req:  &pb.CreateTransferRequest{FromAccountId: 1, ToAccountId: 3, Amount: 10, Currency: util.USD},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account3.ID)).Times(1).Return(account3, nil)
+				store.EXPECT().TransferTransaction(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "PermissionDenied",
+			req:  &pb.CreateTransferRequest{FromAccountId: 2, ToAccountId: 1, Amount: 10, Currency: util.USD},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTransaction(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.PermissionDenied,
+		},
+		{
+			name: "InvalidAmount",
+			req:  &pb.CreateTransferRequest{FromAccountId: 1, ToAccountId: 2, Amount: -1, Currency: util.USD},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTransaction(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.InvalidArgument,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			ctx := newContextWithBearerToken(t, server.tokenMaker, account1.Owner, time.Minute)
+			res, err := server.CreateTransfer(ctx, tc.req)
+			assert.Equal(t, tc.code, status.Code(err))
+			if tc.code == codes.OK {
+				assert.Equal(t, int64(9), res.GetTransfer().GetId())
+			}
+		})
+	}
+}
+
+func TestServer_ListTransfers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().ListTransfersAfter(gomock.Any(), gomock.Any()).Times(0)
+
+	server := newTestServer(t, store)
+	ctx := newContextWithBearerToken(t, server.tokenMaker, util.RandomOwner(), time.Minute)
+	_, err := server.ListTransfers(ctx, &pb.ListTransfersRequest{PageSize: 5})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestServer_ListTransfersFiltered(t *testing.T) {
+	account := randomAccount()
+	start := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	transfers := []db.Transfer{{ID: 1, FromAccountID: account.ID, ToAccountID: account.ID + 1, Amount: 50}}
+
+	testCases := []struct {
+		name       string
+		filter     *pb.ListFilter
+		buildStubs func(store *mockdb.MockStore)
+		code       codes.Code
+	}{
+		{
+			name: "OK",
+			filter: &pb.ListFilter{
+				Direction: pb.Direction_DIRECTION_OUT,
+				StartTime: timestamppb.New(start),
+				MinAmount: 10,
+				MaxAmount: 100,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				arg := db.ListTransfersAfterParams{
+					AccountID:  account.ID,
+					Direction:  "out",
+					StartTime:  sql.NullTime{Time: start, Valid: true},
+					MinAmount:  sql.NullFloat64{Float64: 10, Valid: true},
+					MaxAmount:  sql.NullFloat64{Float64: 100, Valid: true},
+					LimitCount: 6,
+				}
+				store.EXPECT().ListTransfersAfter(gomock.Any(), gomock.Eq(arg)).Times(1).Return(transfers, nil)
+			},
+			code: codes.OK,
+		},
+		{
+			name:   "Descending",
+			filter: &pb.ListFilter{Order: pb.SortOrder_SORT_ORDER_DESC},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().ListTransfersBefore(gomock.Any(), gomock.Any()).Times(1).Return(transfers, nil)
+			},
+			code: codes.OK,
+		},
+		{
+			name:   "IncludeArchived",
+			filter: &pb.ListFilter{IncludeArchived: true},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().ListTransfersWithArchiveAfter(gomock.Any(), gomock.Any()).Times(1).Return(transfers, nil)
+			},
+			code: codes.OK,
+		},
+		{
+			name: "InvertedRanges",
+			filter: &pb.ListFilter{
+				StartTime: timestamppb.New(start),
+				EndTime:   timestamppb.New(start.Add(-time.Hour)),
+				MinAmount: 100,
+				MaxAmount: 10,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListTransfersAfter(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.InvalidArgument,
+		},
+		{
+			name:   "UnknownDirection",
+			filter: &pb.ListFilter{Direction: pb.Direction(7)},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListTransfersAfter(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.InvalidArgument,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			ctx := newContextWithBearerToken(t, server.tokenMaker, account.Owner, time.Minute)
+			res, err := server.ListTransfers(ctx, &pb.ListTransfersRequest{AccountId: account.ID, PageSize: 5, Filter: tc.filter})
+			assert.Equal(t, tc.code, status.Code(err))
+			if tc.code == codes.OK {
+				assert.Len(t, res.GetTransfers(), len(transfers))
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	mockdb "github.com/arpangoswami/backend-golang-dev/database/mock"
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+	"github.com/arpangoswami/backend-golang-dev/pb"
+	"github.com/arpangoswami/backend-golang-dev/util"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestServer_CreateUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	username := util.RandomOwner()
+	store.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, arg db.CreateUserParams) (db.User, error) {
+			assert.NoError(t, util.CheckPassword("secret", arg.HashedPassword))
+			return db.User{Username: arg.Username, FullName: arg.FullName, Email: arg.Email}, nil
+		})
+
+	server := newTestServer(t, store)
+	res, err := server.CreateUser(context.Background(), &pb.CreateUserRequest{
+		Username: username,
+		FullName: "Full Name",
+		Email:    util.RandomEmail(),
+		Password: "secret",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, username, res.GetUsername())
+
+	_, err = server.CreateUser(context.Background(), &pb.CreateUserRequest{Username: "no spaces", Password: "x"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestServer_LoginUser(t *testing.T) {
+	password := util.RandomString(6)
+	hashedPassword, err := util.HashPassword(password)
+	assert.NoError(t, err)
+	user := db.User{Username: util.RandomOwner(), HashedPassword: hashedPassword}
+
+	testCases := []struct {
+		name       string
+		password   string
+		buildStubs func(store *mockdb.MockStore)
+		code       codes.Code
+	}{
+		{
+			name:     "OK",
+			password: password,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateSessionParams) (db.Session, error) {
+						assert.Equal(t, user.Username, arg.Username)
+						return db.Session{ID: arg.ID, Username: arg.Username, RefreshToken: arg.RefreshToken}, nil
+					})
+			},
+			code: codes.OK,
+		},
+		{
+			name:     "WrongPassword",
+			password: "wrong-password",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+			},
+			code: codes.Unauthenticated,
+		},
+		{
+			name:     "UserNotFound",
+			password: password,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(1).Return(db.User{}, sql.ErrNoRows)
+			},
+			code: codes.Unauthenticated,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			res, err := server.LoginUser(context.Background(), &pb.LoginUserRequest{
+				Username: user.Username,
+				Password: tc.password,
+			})
+			assert.Equal(t, tc.code, status.Code(err))
+			if tc.code == codes.OK {
+				payload, err := server.tokenMaker.VerifyToken(res.GetAccessToken())
+				assert.NoError(t, err)
+				assert.Equal(t, user.Username, payload.Username)
+				refreshPayload, err := server.tokenMaker.VerifyToken(res.GetRefreshToken())
+				assert.NoError(t, err)
+				assert.Equal(t, res.GetSessionId(), refreshPayload.ID.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package gapi
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+	"github.com/arpangoswami/backend-golang-dev/pb"
+	"github.com/arpangoswami/backend-golang-dev/util"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+const (
+	minPageSize = 5
+	maxPageSize = 10
+)
+
+var isValidUsername = regexp.MustCompile(`^[a-zA-Z0-9]+$`).MatchString
+
+func validateID(field string, id int64) *errdetails.BadRequest_FieldViolation {
+	if id < 1 {
+		return fieldViolation(field, fmt.Errorf("must be a positive integer"))
+	}
+	return nil
+}
+
+func validateCurrency(field string, currency string) *errdetails.BadRequest_FieldViolation {
+	if !util.IsSupportedCurrency(currency) {
+		return fieldViolation(field, fmt.Errorf("unsupported currency %q", currency))
+	}
+	return nil
+}
+
+func validateUsername(field string, username string) *errdetails.BadRequest_FieldViolation {
+	if !isValidUsername(username) {
+		return fieldViolation(field, fmt.Errorf("must contain only letters and digits"))
+	}
+	return nil
+}
+
+func validatePassword(field string, password string) *errdetails.BadRequest_FieldViolation {
+	if len(password) < minPasswordLength {
+		return fieldViolation(field, fmt.Errorf("must contain at least %d characters", minPasswordLength))
+	}
+	return nil
+}
+
+func validatePage(pageID int32, pageSize int32) (violations []*errdetails.BadRequest_FieldViolation) {
+	if pageID < 1 {
+		violations = append(violations, fieldViolation("page_id", fmt.Errorf("must be at least 1")))
+	}
+	return collectViolations(append(violations, validatePageSize("page_size", pageSize))...)
+}
+
+func validatePageSize(field string, pageSize int32) *errdetails.BadRequest_FieldViolation {
+	if pageSize < minPageSize || pageSize > maxPageSize {
+		return fieldViolation(field, fmt.Errorf("must be between %d and %d", minPageSize, maxPageSize))
+	}
+	return nil
+}
+
+func validateListFilter(field string, filter *pb.ListFilter) (violations []*errdetails.BadRequest_FieldViolation) {
+	if _, ok := pb.Direction_name[int32(filter.GetDirection())]; !ok {
+		violations = append(violations, fieldViolation(field+".direction", fmt.Errorf("unknown direction")))
+	}
+	if _, ok := pb.SortOrder_name[int32(filter.GetOrder())]; !ok {
+		violations = append(violations, fieldViolation(field+".order", fmt.Errorf("unknown sort order")))
+	}
+	if filter.GetStartTime() != nil && filter.GetEndTime() != nil &&
+		!filter.GetEndTime().AsTime().After(filter.GetStartTime().AsTime()) {
+		violations = append(violations, fieldViolation(field+".end_time", fmt.Errorf("must be after start_time")))
+	}
+	if filter.GetMinAmount() < 0 {
+		violations = append(violations, fieldViolation(field+".min_amount", fmt.Errorf("must not be negative")))
+	}
+	if filter.GetMaxAmount() != 0 && filter.GetMaxAmount() < filter.GetMinAmount() {
+		violations = append(violations, fieldViolation(field+".max_amount", fmt.Errorf("must not be less than min_amount")))
+	}
+	return violations
+}
+
+func validateWebhookURL(field string, value string) *errdetails.BadRequest_FieldViolation {
+	u, err := url.Parse(value)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return fieldViolation(field, fmt.Errorf("must be an absolute https URL"))
+	}
+	return nil
+}
+
+func validateEventTypes(field string, eventTypes []string) (violations []*errdetails.BadRequest_FieldViolation) {
+	if len(eventTypes) == 0 {
+		return collectViolations(fieldViolation(field, fmt.Errorf("must contain at least one event type")))
+	}
+	seen := make(map[string]bool, len(eventTypes))
+	for i, eventType := range eventTypes {
+		switch {
+		case !db.IsEventType(eventType):
+			violations = append(violations, fieldViolation(fmt.Sprintf("%s[%d]", field, i), fmt.Errorf("unknown event type %q", eventType)))
+		case seen[eventType]:
+			violations = append(violations, fieldViolation(fmt.Sprintf("%s[%d]", field, i), fmt.Errorf("duplicate event type %q", eventType)))
+		}
+		seen[eventType] = true
+	}
+	return violations
+}
+
+func validateDeliveryStatus(field string, status string) *errdetails.BadRequest_FieldViolation {
+	switch status {
+	case "", db.DeliveryPending, db.DeliverySucceeded, db.DeliveryDead:
+		return nil
+	}
+	return fieldViolation(field, fmt.Errorf("must be %s, %s or %s", db.DeliveryPending, db.DeliverySucceeded, db.DeliveryDead))
+}
+
+// collectViolations drops the nil results of individual field checks
+func collectViolations(checks ...*errdetails.BadRequest_FieldViolation) (violations []*errdetails.BadRequest_FieldViolation) {
+	for _, violation := range checks {
+		if violation != nil {
+			violations = append(violations, violation)
+		}
+	}
+	return violations
+}
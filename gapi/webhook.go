@@ -0,0 +1,121 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/arpangoswami/backend-golang-dev/pb"
+	"github.com/arpangoswami/backend-golang-dev/service"
+)
+
+func (server *Server) CreateWebhookSubscription(ctx context.Context, req *pb.CreateWebhookSubscriptionRequest) (*pb.WebhookSubscription, error) {
+	ctx, err := server.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	violations := collectViolations(validateWebhookURL("url", req.GetUrl()))
+	violations = append(violations, validateEventTypes("event_types", req.GetEventTypes())...)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	subscription, err := server.bank.CreateWebhookSubscription(ctx, req.GetUrl(), req.GetEventTypes())
+	if err != nil {
+		return nil, storeError(err)
+	}
+	rsp := convertWebhookSubscription(subscription)
+	rsp.Secret = subscription.Secret
+	return rsp, nil
+}
+
+func (server *Server) ListWebhookSubscriptions(ctx context.Context, req *pb.ListWebhookSubscriptionsRequest) (*pb.ListWebhookSubscriptionsResponse, error) {
+	ctx, err := server.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	subscriptions, err := server.bank.ListWebhookSubscriptions(ctx)
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	rsp := &pb.ListWebhookSubscriptionsResponse{Subscriptions: make([]*pb.WebhookSubscription, len(subscriptions))}
+	for i, subscription := range subscriptions {
+		rsp.Subscriptions[i] = convertWebhookSubscription(subscription)
+	}
+	return rsp, nil
+}
+
+func (server *Server) DeleteWebhookSubscription(ctx context.Context, req *pb.DeleteWebhookSubscriptionRequest) (*pb.WebhookSubscription, error) {
+	ctx, err := server.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if violations := collectViolations(validateID("id", req.GetId())); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	subscription, err := server.bank.DeleteWebhookSubscription(ctx, req.GetId())
+	if err != nil {
+		return nil, storeError(err)
+	}
+	return convertWebhookSubscription(subscription), nil
+}
+
+func (server *Server) ListWebhookDeliveries(ctx context.Context, req *pb.ListWebhookDeliveriesRequest) (*pb.ListWebhookDeliveriesResponse, error) {
+	ctx, err := server.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	violations := collectViolations(
+		validateID("subscription_id", req.GetSubscriptionId()),
+		validateDeliveryStatus("status", req.GetStatus()),
+		validatePageSize("page_size", req.GetPageSize()),
+	)
+	if req.GetAfterId() < 0 {
+		violations = append(violations, fieldViolation("after_id", fmt.Errorf("must not be negative")))
+	}
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	deliveries, err := server.bank.ListWebhookDeliveries(ctx, service.ListWebhookDeliveriesParams{
+		SubscriptionID: req.GetSubscriptionId(),
+		Status:         req.GetStatus(),
+		AfterID:        req.GetAfterId(),
+		PageSize:       req.GetPageSize(),
+	})
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	rsp := &pb.ListWebhookDeliveriesResponse{Deliveries: make([]*pb.WebhookDelivery, len(deliveries))}
+	for i, delivery := range deliveries {
+		rsp.Deliveries[i] = convertWebhookDelivery(delivery)
+	}
+	return rsp, nil
+}
+
+func (server *Server) ReplayWebhookDelivery(ctx context.Context, req *pb.ReplayWebhookDeliveryRequest) (*pb.WebhookDelivery, error) {
+	ctx, err := server.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	violations := collectViolations(
+		validateID("subscription_id", req.GetSubscriptionId()),
+		validateID("delivery_id", req.GetDeliveryId()),
+	)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	delivery, err := server.bank.ReplayWebhookDelivery(ctx, req.GetSubscriptionId(), req.GetDeliveryId())
+	if err != nil {
+		return nil, storeError(err)
+	}
+	return convertWebhookDelivery(delivery), nil
+}
@@ -0,0 +1,178 @@
+package gapi
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	mockdb "github.com/arpangoswami/backend-golang-dev/database/mock"
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+	"github.com/arpangoswami/backend-golang-dev/pb"
+	"github.com/arpangoswami/backend-golang-dev/util"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestServer_CreateWebhookSubscription(t *testing.T) {
+	username := util.RandomOwner()
+
+	testCases := []struct {
+		name       string
+		req        *pb.CreateWebhookSubscriptionRequest
+		buildStubs func(store *mockdb.MockStore)
+		code       codes.Code
+	}{
+		{
+			name: "OK",
+			req:  &pb.CreateWebhookSubscriptionRequest{Url: "https://example.com/hook", EventTypes: []string{db.EventTransferCompleted}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhookSubscription(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateWebhookSubscriptionParams) (db.WebhookSubscription, error) {
+						assert.Equal(t, username, arg.Owner)
+						return db.WebhookSubscription{ID: 1, Owner: arg.Owner, Url: arg.Url, Secret: arg.Secret}, nil
+					})
+			},
+			code: codes.OK,
+		},
+		{
+			name: "RelativeURL",
+			req:  &pb.CreateWebhookSubscriptionRequest{Url: "/hook", EventTypes: []string{db.EventTransferCompleted}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhookSubscription(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "PlainHTTP",
+			req:  &pb.CreateWebhookSubscriptionRequest{Url: "http://example.com/hook", EventTypes: []string{db.EventTransferCompleted}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhookSubscription(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "LoopbackURL",
+			req:  &pb.CreateWebhookSubscriptionRequest{Url: "https://[::1]:8443/hook", EventTypes: []string{db.EventTransferCompleted}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhookSubscription(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "DuplicateEventType",
+			req: &pb.CreateWebhookSubscriptionRequest{
+				Url:        "https://example.com/hook",
+				EventTypes: []string{db.EventTransferCompleted, db.EventTransferCompleted},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhookSubscription(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "UnknownEventType",
+			req:  &pb.CreateWebhookSubscriptionRequest{Url: "https://example.com/hook", EventTypes: []string{"account.robbed"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhookSubscription(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.InvalidArgument,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			ctx := newContextWithBearerToken(t, server.tokenMaker, username, time.Minute)
+			rsp, err := server.CreateWebhookSubscription(ctx, tc.req)
+			assert.Equal(t, tc.code, status.Code(err))
+			if tc.code == codes.OK {
+				assert.NotEmpty(t, rsp.GetSecret())
+			}
+		})
+	}
+}
+
+func TestServer_ListWebhookSubscriptions(t *testing.T) {
+	username := util.RandomOwner()
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().ListWebhookSubscriptions(gomock.Any(), gomock.Eq(username)).Times(1).
+		Return([]db.WebhookSubscription{{ID: 1, Owner: username, Secret: "whsec_a"}}, nil)
+
+	server := newTestServer(t, store)
+	ctx := newContextWithBearerToken(t, server.tokenMaker, username, time.Minute)
+	rsp, err := server.ListWebhookSubscriptions(ctx, &pb.ListWebhookSubscriptionsRequest{})
+	assert.NoError(t, err)
+	if assert.Len(t, rsp.GetSubscriptions(), 1) {
+		assert.Empty(t, rsp.GetSubscriptions()[0].GetSecret())
+	}
+}
+
+func TestServer_ReplayWebhookDelivery(t *testing.T) {
+	subscription := db.WebhookSubscription{ID: 4, Owner: util.RandomOwner()}
+
+	testCases := []struct {
+		name       string
+		username   string
+		buildStubs func(store *mockdb.MockStore)
+		code       codes.Code
+	}{
+		{
+			name:     "OK",
+			username: subscription.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetWebhookSubscription(gomock.Any(), gomock.Eq(subscription.ID)).Times(1).Return(subscription, nil)
+				store.EXPECT().GetWebhookDelivery(gomock.Any(), gomock.Eq(int64(6))).Times(1).
+					Return(db.WebhookDelivery{ID: 6, SubscriptionID: subscription.ID, Status: db.DeliveryDead}, nil)
+				store.EXPECT().ReplayWebhookDelivery(gomock.Any(), gomock.Eq(int64(6))).Times(1).
+					Return(db.WebhookDelivery{ID: 6, SubscriptionID: subscription.ID, Status: db.DeliveryPending}, nil)
+			},
+			code: codes.OK,
+		},
+		{
+			name:     "OtherSubscription",
+			username: subscription.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetWebhookSubscription(gomock.Any(), gomock.Eq(subscription.ID)).Times(1).Return(subscription, nil)
+				store.EXPECT().GetWebhookDelivery(gomock.Any(), gomock.Eq(int64(6))).Times(1).
+					Return(db.WebhookDelivery{ID: 6, SubscriptionID: subscription.ID + 1}, nil)
+				store.EXPECT().ReplayWebhookDelivery(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.NotFound,
+		},
+		{
+			name:     "PermissionDenied",
+			username: "someoneelse",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetWebhookSubscription(gomock.Any(), gomock.Eq(subscription.ID)).Times(1).Return(subscription, nil)
+				store.EXPECT().ReplayWebhookDelivery(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.PermissionDenied,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			ctx := newContextWithBearerToken(t, server.tokenMaker, tc.username, time.Minute)
+			rsp, err := server.ReplayWebhookDelivery(ctx, &pb.ReplayWebhookDeliveryRequest{
+				SubscriptionId: subscription.ID,
+				DeliveryId:     6,
+			})
+			assert.Equal(t, tc.code, status.Code(err))
+			if tc.code == codes.OK {
+				assert.Equal(t, db.DeliveryPending, rsp.GetStatus())
+			}
+		})
+	}
+}
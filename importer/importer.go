@@ -0,0 +1,162 @@
+// Package importer loads the accounts and transfers of customers migrated from the old system out of
+// CSV files. A file is streamed and imported in batches, each validated, copied into the tables and
+// recorded as processed in a single transaction, so that an interrupted import resumes after the
+// last committed batch when it is run again under the same name. Invalid records don't stop an
+// import: they are stored with their line in import_errors and the next records are imported.
+package importer
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+)
+
+// DefaultBatchSize is the number of CSV records imported per transaction
+const DefaultBatchSize = 1000
+
+var (
+	// ErrInvalidHeader is returned when the first record of a file doesn't name the columns of its kind
+	ErrInvalidHeader = errors.New("invalid CSV header")
+	// ErrFileChanged is returned when resuming an import whose file has fewer records than were processed
+	ErrFileChanged = errors.New("file has fewer records than the import processed")
+)
+
+// Importer imports CSV files into a Store
+type Importer struct {
+	store     db.Store
+	batchSize int
+}
+
+// New returns an importer committing batchSize records at a time, DefaultBatchSize when it isn't positive
+func New(store db.Store, batchSize int) *Importer {
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	return &Importer{store: store, batchSize: batchSize}
+}
+
+// ImportAccounts imports the accounts of r, see AccountColumns, and returns the import's totals.
+// Running it again with the same name resumes the import, or does nothing once it finished.
+func (importer *Importer) ImportAccounts(ctx context.Context, name string, r io.Reader) (db.Import, error) {
+	return run(ctx, importer, name, db.ImportAccounts, r, accountColumns, parseAccount,
+		func(ctx context.Context, imp db.Import, rows []db.ImportAccountRow, errs []db.ImportRowError) (db.ImportBatchResult, error) {
+			return importer.store.ImportAccountsTransaction(ctx, db.ImportAccountsParams{Import: imp, Rows: rows, Errors: errs})
+		})
+}
+
+// ImportTransfers imports the transfers of r, see TransferColumns, between accounts imported before.
+// Like ImportAccounts, running it again with the same name resumes the import.
+func (importer *Importer) ImportTransfers(ctx context.Context, name string, r io.Reader) (db.Import, error) {
+	return run(ctx, importer, name, db.ImportTransfers, r, transferColumns, parseTransfer,
+		func(ctx context.Context, imp db.Import, rows []db.ImportTransferRow, errs []db.ImportRowError) (db.ImportBatchResult, error) {
+			return importer.store.ImportTransfersTransaction(ctx, db.ImportTransfersParams{Import: imp, Rows: rows, Errors: errs})
+		})
+}
+
+// commitFunc imports a batch of valid rows along with the records rejected while parsing them
+type commitFunc[T any] func(ctx context.Context, imp db.Import, rows []T, errs []db.ImportRowError) (db.ImportBatchResult, error)
+
+// run streams the records of r after the ones the import already processed and commits them in batches
+func run[T any](ctx context.Context, importer *Importer, name, kind string, r io.Reader, columns []column, parse parseFunc[T], commit commitFunc[T]) (db.Import, error) {
+	imp, err := importer.store.GetOrCreateImport(ctx, db.GetOrCreateImportParams{Name: name, Kind: kind})
+	if err != nil {
+		return db.Import{}, err
+	}
+	if imp.Kind != kind {
+		return imp, fmt.Errorf("import %q is an import of %s, not %s", name, imp.Kind, kind)
+	}
+	if imp.FinishedAt.Valid {
+		return imp, nil
+	}
+
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err != nil {
+		return imp, fmt.Errorf("%w: %v", ErrInvalidHeader, err)
+	}
+	fields, err := indexColumns(header, columns)
+	if err != nil {
+		return imp, err
+	}
+
+	// the records of the committed batches are read again, without being parsed
+	for skipped := int64(0); skipped < imp.RowsProcessed; skipped++ {
+		_, _, err := readRecord(reader)
+		if errors.Is(err, io.EOF) {
+			return imp, fmt.Errorf("import %q: %w", name, ErrFileChanged)
+		}
+		var parseErr *csv.ParseError
+		if err != nil && !errors.As(err, &parseErr) {
+			return imp, err
+		}
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return imp, err
+		}
+
+		var rows []T
+		var errs []db.ImportRowError
+		eof := false
+		for len(rows)+len(errs) < importer.batchSize {
+			record, line, err := readRecord(reader)
+			if errors.Is(err, io.EOF) {
+				eof = true
+				break
+			}
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				errs = append(errs, db.ImportRowError{Line: line, Message: parseErr.Err.Error()})
+				continue
+			}
+			if err != nil {
+				return imp, err
+			}
+
+			row, err := parse(fields.of(record), line)
+			if err != nil {
+				errs = append(errs, db.ImportRowError{Line: line, Message: err.Error()})
+				continue
+			}
+			rows = append(rows, row)
+		}
+
+		if len(rows)+len(errs) > 0 {
+			result, err := commit(ctx, imp, rows, errs)
+			if err != nil {
+				return imp, err
+			}
+			imp = result.Import
+		}
+		if eof {
+			break
+		}
+	}
+
+	finished, err := importer.store.FinishImport(ctx, imp.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return imp, fmt.Errorf("import %q: %w", name, db.ErrImportConflict)
+	}
+	return finished, err
+}
+
+// readRecord reads the next record of a file and returns the line it starts on. Like the records
+// read fine, a malformed record is followed by the next one, it is returned as a *csv.ParseError.
+func readRecord(reader *csv.Reader) ([]string, int64, error) {
+	record, err := reader.Read()
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return nil, int64(parseErr.StartLine), err
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	line, _ := reader.FieldPos(0)
+	return record, int64(line), nil
+}
@@ -0,0 +1,245 @@
+package importer
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	mockdb "github.com/arpangoswami/backend-golang-dev/database/mock"
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+	"github.com/arpangoswami/backend-golang-dev/util"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+const accountsCSV = `external_id,owner,currency,balance,country_code,created_at
+A-1,alice,USD,100,,2020-01-02T03:04:05Z
+A-2,bob,eur,0,7,
+A-3,carol,XYZ,10,,
+A-4,,USD,10,,
+A-5,dave,USD,-1,,
+A-6,erin,USD
+A-7,frank,INR,12.5,,2021-06-01T00:00:00+02:00
+`
+
+// batch is what the importer committed in one transaction
+type batch[T any] struct {
+	rows []T
+	errs []db.ImportRowError
+}
+
+// advance makes the mock store commit a batch like the real one, which accepts every row
+func advance[T any](batches *[]batch[T]) func(imp db.Import, rows []T, errs []db.ImportRowError) (db.ImportBatchResult, error) {
+	return func(imp db.Import, rows []T, errs []db.ImportRowError) (db.ImportBatchResult, error) {
+		*batches = append(*batches, batch[T]{rows: rows, errs: errs})
+		imp.RowsProcessed += int64(len(rows) + len(errs))
+		imp.RowsImported += int64(len(rows))
+		imp.RowsFailed += int64(len(errs))
+		return db.ImportBatchResult{Import: imp, Errors: errs}, nil
+	}
+}
+
+func expectImport(store *mockdb.MockStore, imp db.Import) {
+	store.EXPECT().GetOrCreateImport(gomock.Any(), gomock.Eq(db.GetOrCreateImportParams{Name: imp.Name, Kind: imp.Kind})).
+		Times(1).Return(imp, nil)
+}
+
+func expectFinish(store *mockdb.MockStore, imp db.Import) {
+	store.EXPECT().FinishImport(gomock.Any(), gomock.Eq(imp.ID)).Times(1).
+		DoAndReturn(func(_ context.Context, id int64) (db.Import, error) {
+			imp.FinishedAt = sql.NullTime{Time: time.Now(), Valid: true}
+			return imp, nil
+		})
+}
+
+func TestImporter_ImportAccounts(t *testing.T) {
+	store := mockdb.NewMockStore(gomock.NewController(t))
+	imp := db.Import{ID: 1, Name: "accounts-2024", Kind: db.ImportAccounts}
+	expectImport(store, imp)
+
+	var batches []batch[db.ImportAccountRow]
+	commit := advance(&batches)
+	store.EXPECT().ImportAccountsTransaction(gomock.Any(), gomock.Any()).Times(4).
+		DoAndReturn(func(_ context.Context, arg db.ImportAccountsParams) (db.ImportBatchResult, error) {
+			return commit(arg.Import, arg.Rows, arg.Errors)
+		})
+	expectFinish(store, db.Import{ID: 1, Name: imp.Name, Kind: imp.Kind, RowsProcessed: 7, RowsImported: 3, RowsFailed: 4})
+
+	result, err := New(store, 2).ImportAccounts(context.Background(), imp.Name, strings.NewReader(accountsCSV))
+	assert.NoError(t, err)
+	assert.True(t, result.FinishedAt.Valid)
+	assert.Equal(t, int64(7), result.RowsProcessed)
+
+	// every batch holds 2 records, valid or not, the last one what remains
+	assert.Len(t, batches, 4)
+	first := batches[0].rows
+	assert.Len(t, first, 2)
+	assert.Equal(t, db.ImportAccountRow{
+		Line:        2,
+		ExternalID:  "A-1",
+		Owner:       "alice",
+		Currency:    util.USD,
+		Balance:     100,
+		CountryCode: util.CountryCodeForCurrency(util.USD),
+		CreatedAt:   time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+	}, first[0])
+	assert.Equal(t, util.EUR, first[1].Currency)
+	assert.Equal(t, sql.NullInt32{Int32: 7, Valid: true}, first[1].CountryCode)
+	assert.WithinDuration(t, time.Now(), first[1].CreatedAt, time.Minute)
+
+	var errs []db.ImportRowError
+	for _, batch := range batches {
+		errs = append(errs, batch.errs...)
+	}
+	assert.Equal(t, []db.ImportRowError{
+		{Line: 4, Message: `unsupported currency "XYZ"`},
+		{Line: 5, Message: "owner is empty"},
+		{Line: 6, Message: "balance must not be negative"},
+		{Line: 7, Message: "wrong number of fields"},
+	}, errs)
+
+	last := batches[3].rows
+	assert.Len(t, last, 1)
+	assert.Equal(t, int64(8), last[0].Line)
+	assert.Equal(t, time.Date(2021, 5, 31, 22, 0, 0, 0, time.UTC), last[0].CreatedAt)
+}
+
+func TestImporter_Resume(t *testing.T) {
+	store := mockdb.NewMockStore(gomock.NewController(t))
+	// the first 5 records were committed before the import was interrupted
+	imp := db.Import{ID: 1, Name: "accounts-2024", Kind: db.ImportAccounts, RowsProcessed: 5, RowsImported: 2, RowsFailed: 3}
+	expectImport(store, imp)
+
+	var batches []batch[db.ImportAccountRow]
+	commit := advance(&batches)
+	store.EXPECT().ImportAccountsTransaction(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, arg db.ImportAccountsParams) (db.ImportBatchResult, error) {
+			assert.Equal(t, int64(5), arg.Import.RowsProcessed)
+			return commit(arg.Import, arg.Rows, arg.Errors)
+		})
+	expectFinish(store, db.Import{ID: 1, Name: imp.Name, Kind: imp.Kind, RowsProcessed: 7, RowsImported: 3, RowsFailed: 4})
+
+	_, err := New(store, 10).ImportAccounts(context.Background(), imp.Name, strings.NewReader(accountsCSV))
+	assert.NoError(t, err)
+	assert.Len(t, batches, 1)
+	assert.Equal(t, []db.ImportRowError{{Line: 7, Message: "wrong number of fields"}}, batches[0].errs)
+	assert.Len(t, batches[0].rows, 1)
+	assert.Equal(t, "A-7", batches[0].rows[0].ExternalID)
+}
+
+func TestImporter_ResumeFileChanged(t *testing.T) {
+	store := mockdb.NewMockStore(gomock.NewController(t))
+	imp := db.Import{ID: 1, Name: "accounts-2024", Kind: db.ImportAccounts, RowsProcessed: 100}
+	expectImport(store, imp)
+	store.EXPECT().ImportAccountsTransaction(gomock.Any(), gomock.Any()).Times(0)
+	store.EXPECT().FinishImport(gomock.Any(), gomock.Any()).Times(0)
+
+	_, err := New(store, 10).ImportAccounts(context.Background(), imp.Name, strings.NewReader(accountsCSV))
+	assert.ErrorIs(t, err, ErrFileChanged)
+}
+
+func TestImporter_Finished(t *testing.T) {
+	store := mockdb.NewMockStore(gomock.NewController(t))
+	imp := db.Import{ID: 1, Name: "accounts-2024", Kind: db.ImportAccounts, RowsProcessed: 7, FinishedAt: sql.NullTime{Time: time.Now(), Valid: true}}
+	expectImport(store, imp)
+	store.EXPECT().ImportAccountsTransaction(gomock.Any(), gomock.Any()).Times(0)
+	store.EXPECT().FinishImport(gomock.Any(), gomock.Any()).Times(0)
+
+	result, err := New(store, 10).ImportAccounts(context.Background(), imp.Name, strings.NewReader(accountsCSV))
+	assert.NoError(t, err)
+	assert.Equal(t, imp, result)
+}
+
+func TestImporter_KindMismatch(t *testing.T) {
+	store := mockdb.NewMockStore(gomock.NewController(t))
+	store.EXPECT().GetOrCreateImport(gomock.Any(), gomock.Any()).Times(1).
+		Return(db.Import{ID: 1, Name: "legacy", Kind: db.ImportAccounts}, nil)
+	store.EXPECT().ImportTransfersTransaction(gomock.Any(), gomock.Any()).Times(0)
+
+	_, err := New(store, 10).ImportTransfers(context.Background(), "legacy", strings.NewReader("from_account,to_account,amount\n"))
+	assert.ErrorContains(t, err, "is an import of accounts, not transfers")
+}
+
+func TestImporter_InvalidHeader(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input string
+	}{
+		{name: "Empty", input: ""},
+		{name: "MissingColumn", input: "external_id,owner,balance\nA-1,alice,10\n"},
+		{name: "DuplicateColumn", input: "external_id,owner,currency,balance,owner\n"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := mockdb.NewMockStore(gomock.NewController(t))
+			expectImport(store, db.Import{ID: 1, Name: "accounts", Kind: db.ImportAccounts})
+			store.EXPECT().ImportAccountsTransaction(gomock.Any(), gomock.Any()).Times(0)
+
+			_, err := New(store, 10).ImportAccounts(context.Background(), "accounts", strings.NewReader(tc.input))
+			assert.ErrorIs(t, err, ErrInvalidHeader)
+		})
+	}
+}
+
+func TestImporter_ImportTransfers(t *testing.T) {
+	store := mockdb.NewMockStore(gomock.NewController(t))
+	imp := db.Import{ID: 2, Name: "transfers-2024", Kind: db.ImportTransfers}
+	expectImport(store, imp)
+
+	input := `amount,from_account,to_account,created_at
+10,A-1,A-2,2020-01-02T03:04:05Z
+0,A-1,A-2,
+5,A-1,A-1,
+x,A-1,A-2,
+5,A-1,,
+5,A-2,A-1,yesterday
+"7.5",A-2,A-1,
+`
+	var batches []batch[db.ImportTransferRow]
+	commit := advance(&batches)
+	store.EXPECT().ImportTransfersTransaction(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, arg db.ImportTransfersParams) (db.ImportBatchResult, error) {
+			return commit(arg.Import, arg.Rows, arg.Errors)
+		})
+	expectFinish(store, db.Import{ID: 2, Name: imp.Name, Kind: imp.Kind, RowsProcessed: 7, RowsImported: 2, RowsFailed: 5})
+
+	result, err := New(store, 0).ImportTransfers(context.Background(), imp.Name, strings.NewReader(input))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), result.RowsImported)
+
+	assert.Len(t, batches, 1)
+	assert.Equal(t, []db.ImportTransferRow{
+		{Line: 2, FromExternalID: "A-1", ToExternalID: "A-2", Amount: 10, CreatedAt: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{Line: 8, FromExternalID: "A-2", ToExternalID: "A-1", Amount: 7.5, CreatedAt: batches[0].rows[1].CreatedAt},
+	}, batches[0].rows)
+	assert.Equal(t, []db.ImportRowError{
+		{Line: 3, Message: "amount must be positive"},
+		{Line: 4, Message: "cannot transfer to the same account"},
+		{Line: 5, Message: `invalid amount "x"`},
+		{Line: 6, Message: "to_account is empty"},
+		{Line: 7, Message: `invalid created_at "yesterday", want an RFC 3339 time`},
+	}, batches[0].errs)
+}
+
+func TestImporter_Cancelled(t *testing.T) {
+	store := mockdb.NewMockStore(gomock.NewController(t))
+	imp := db.Import{ID: 1, Name: "accounts-2024", Kind: db.ImportAccounts}
+	expectImport(store, imp)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// the import stops between batches, leaving the committed ones for the next run to skip
+	store.EXPECT().ImportAccountsTransaction(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, arg db.ImportAccountsParams) (db.ImportBatchResult, error) {
+			cancel()
+			var batches []batch[db.ImportAccountRow]
+			return advance(&batches)(arg.Import, arg.Rows, arg.Errors)
+		})
+	store.EXPECT().FinishImport(gomock.Any(), gomock.Any()).Times(0)
+
+	result, err := New(store, 2).ImportAccounts(ctx, imp.Name, strings.NewReader(accountsCSV))
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, int64(2), result.RowsProcessed)
+}
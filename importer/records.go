@@ -0,0 +1,174 @@
+package importer
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+	"github.com/arpangoswami/backend-golang-dev/util"
+)
+
+// column is a column of a CSV file, which is named by its header
+type column struct {
+	name     string
+	required bool
+}
+
+var accountColumns = []column{
+	{name: "external_id", required: true},
+	{name: "owner", required: true},
+	{name: "currency", required: true},
+	{name: "balance", required: true},
+	{name: "country_code"},
+	{name: "created_at"},
+}
+
+var transferColumns = []column{
+	{name: "from_account", required: true},
+	{name: "to_account", required: true},
+	{name: "amount", required: true},
+	{name: "created_at"},
+}
+
+// AccountColumns lists the columns of an accounts file, in any order. external_id is the id of the
+// account in the old system, which the transfers refer to; the country code defaults to the one of
+// the currency and created_at, an RFC 3339 time, to the time of the import.
+var AccountColumns = columnNames(accountColumns)
+
+// TransferColumns lists the columns of a transfers file, in any order. The accounts are external ids
+// from an accounts file imported before; created_at, an RFC 3339 time, defaults to the time of the import.
+var TransferColumns = columnNames(transferColumns)
+
+func columnNames(columns []column) []string {
+	names := make([]string, len(columns))
+	for i, column := range columns {
+		names[i] = column.name
+	}
+	return names
+}
+
+// fieldIndex maps the columns to their index in the records of a file
+type fieldIndex map[string]int
+
+// indexColumns locates the columns in the header of a file, which must hold every required column
+func indexColumns(header []string, columns []column) (fieldIndex, error) {
+	index := make(fieldIndex, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := index[name]; ok {
+			return nil, fmt.Errorf("%w: duplicate column %q", ErrInvalidHeader, name)
+		}
+		index[name] = i
+	}
+	for _, column := range columns {
+		if _, ok := index[column.name]; column.required && !ok {
+			return nil, fmt.Errorf("%w: missing column %q", ErrInvalidHeader, column.name)
+		}
+	}
+	return index, nil
+}
+
+// fields are the values of a record by column, a missing optional column having an empty value
+type fields map[string]string
+
+func (index fieldIndex) of(record []string) fields {
+	values := make(fields, len(index))
+	for name, i := range index {
+		values[name] = strings.TrimSpace(record[i])
+	}
+	return values
+}
+
+// parseFunc validates a record starting on line and turns it into a row
+type parseFunc[T any] func(values fields, line int64) (T, error)
+
+func parseAccount(values fields, line int64) (db.ImportAccountRow, error) {
+	row := db.ImportAccountRow{
+		Line:       line,
+		ExternalID: values["external_id"],
+		Owner:      values["owner"],
+		Currency:   strings.ToUpper(values["currency"]),
+	}
+	if row.ExternalID == "" {
+		return row, errors.New("external_id is empty")
+	}
+	if row.Owner == "" {
+		return row, errors.New("owner is empty")
+	}
+	if !util.IsSupportedCurrency(row.Currency) {
+		return row, fmt.Errorf("unsupported currency %q", values["currency"])
+	}
+
+	var err error
+	if row.Balance, err = parseAmount("balance", values["balance"]); err != nil {
+		return row, err
+	}
+	if row.Balance < 0 {
+		return row, errors.New("balance must not be negative")
+	}
+
+	row.CountryCode = util.CountryCodeForCurrency(row.Currency)
+	if value := values["country_code"]; value != "" {
+		code, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			return row, fmt.Errorf("invalid country_code %q", value)
+		}
+		row.CountryCode = sql.NullInt32{Int32: int32(code), Valid: true}
+	}
+
+	row.CreatedAt, err = parseCreatedAt(values["created_at"])
+	return row, err
+}
+
+func parseTransfer(values fields, line int64) (db.ImportTransferRow, error) {
+	row := db.ImportTransferRow{
+		Line:           line,
+		FromExternalID: values["from_account"],
+		ToExternalID:   values["to_account"],
+	}
+	if row.FromExternalID == "" {
+		return row, errors.New("from_account is empty")
+	}
+	if row.ToExternalID == "" {
+		return row, errors.New("to_account is empty")
+	}
+	if row.FromExternalID == row.ToExternalID {
+		return row, errors.New("cannot transfer to the same account")
+	}
+
+	var err error
+	if row.Amount, err = parseAmount("amount", values["amount"]); err != nil {
+		return row, err
+	}
+	if row.Amount <= 0 {
+		return row, errors.New("amount must be positive")
+	}
+
+	row.CreatedAt, err = parseCreatedAt(values["created_at"])
+	return row, err
+}
+
+func parseAmount(name, value string) (float64, error) {
+	amount, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsInf(amount, 0) || math.IsNaN(amount) {
+		return 0, fmt.Errorf("invalid %s %q", name, value)
+	}
+	return amount, nil
+}
+
+// parseCreatedAt parses an RFC 3339 time, now when empty, in UTC like the timestamps of the tables
+func parseCreatedAt(value string) (time.Time, error) {
+	if value == "" {
+		return time.Now().UTC(), nil
+	}
+	createdAt, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid created_at %q, want an RFC 3339 time", value)
+	}
+	return createdAt.UTC(), nil
+}
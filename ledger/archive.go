@@ -0,0 +1,244 @@
+package ledger
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"time"
+
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+)
+
+// FormatVersion is the version of the archives written by Export, the only one Import reads
+const FormatVersion = 1
+
+// Types of the lines of an archive
+const (
+	TypeHeader   = "header"
+	TypeAccount  = "account"
+	TypeTransfer = "transfer"
+	TypeEntry    = "entry"
+	TypeTrailer  = "trailer"
+)
+
+// sections lists the types of records in the order they appear in an archive: transfers and
+// entries refer to accounts, and a reversal to the transfer it reverses, which has a lower id
+var sections = []string{TypeAccount, TypeTransfer, TypeEntry}
+
+// line is a line of an archive, its data depending on its type
+type line struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// Header is the first line of an archive
+type Header struct {
+	Version    int       `json:"version"`
+	ExportedAt time.Time `json:"exported_at"`
+}
+
+// Trailer is the last line of an archive, a missing trailer means the archive was truncated
+type Trailer struct {
+	Counts Counts `json:"counts"`
+	// SHA256 is the hex encoded SHA-256 of every line before the trailer, newlines included
+	SHA256 string `json:"sha256"`
+}
+
+// Counts are the numbers of records of each type in an archive
+type Counts struct {
+	Accounts  int64 `json:"accounts"`
+	Transfers int64 `json:"transfers"`
+	Entries   int64 `json:"entries"`
+}
+
+// Account is an account record, without the database/sql types of db.Account
+type Account struct {
+	ID          int64     `json:"id"`
+	Owner       string    `json:"owner"`
+	Balance     float64   `json:"balance"`
+	Currency    string    `json:"currency"`
+	CountryCode *int32    `json:"country_code"`
+	IsFrozen    bool      `json:"is_frozen"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Transfer is a transfer record, ReversesTransferID being set on reversals
+type Transfer struct {
+	ID                 int64     `json:"id"`
+	FromAccountID      int64     `json:"from_account_id"`
+	ToAccountID        int64     `json:"to_account_id"`
+	Amount             float64   `json:"amount"`
+	ReversesTransferID *int64    `json:"reverses_transfer_id"`
+	CreatedAt          time.Time `json:"created_at"`
+}
+
+// Entry is an entry record
+type Entry struct {
+	ID        int64     `json:"id"`
+	AccountID int64     `json:"account_id"`
+	Amount    float64   `json:"amount"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newAccount(account db.Account) Account {
+	record := Account{
+		ID:        account.ID,
+		Owner:     account.Owner,
+		Balance:   account.Balance,
+		Currency:  account.Currency,
+		IsFrozen:  account.IsFrozen,
+		CreatedAt: account.CreatedAt,
+	}
+	if account.CountryCode.Valid {
+		record.CountryCode = &account.CountryCode.Int32
+	}
+	return record
+}
+
+func (record Account) restoreParams() db.RestoreAccountsParams {
+	params := db.RestoreAccountsParams{
+		ID:        record.ID,
+		Owner:     record.Owner,
+		Balance:   record.Balance,
+		Currency:  record.Currency,
+		IsFrozen:  record.IsFrozen,
+		CreatedAt: record.CreatedAt,
+	}
+	if record.CountryCode != nil {
+		params.CountryCode.Int32, params.CountryCode.Valid = *record.CountryCode, true
+	}
+	return params
+}
+
+func newTransfer(transfer db.Transfer) Transfer {
+	record := Transfer{
+		ID:            transfer.ID,
+		FromAccountID: transfer.FromAccountID,
+		ToAccountID:   transfer.ToAccountID,
+		Amount:        transfer.Amount,
+		CreatedAt:     transfer.CreatedAt,
+	}
+	if transfer.ReversesTransferID.Valid {
+		record.ReversesTransferID = &transfer.ReversesTransferID.Int64
+	}
+	return record
+}
+
+func (record Transfer) restoreParams() db.RestoreTransfersParams {
+	params := db.RestoreTransfersParams{
+		ID:            record.ID,
+		FromAccountID: record.FromAccountID,
+		ToAccountID:   record.ToAccountID,
+		Amount:        record.Amount,
+		CreatedAt:     record.CreatedAt,
+	}
+	if record.ReversesTransferID != nil {
+		params.ReversesTransferID.Int64, params.ReversesTransferID.Valid = *record.ReversesTransferID, true
+	}
+	return params
+}
+
+func newEntry(entry db.Entry) Entry {
+	return Entry(entry)
+}
+
+func (record Entry) restoreParams() db.RestoreEntriesParams {
+	return db.RestoreEntriesParams(record)
+}
+
+// archiveWriter writes the lines of an archive, hashing them for the trailer
+type archiveWriter struct {
+	w      *bufio.Writer
+	hash   hash.Hash
+	counts Counts
+}
+
+func newArchiveWriter(w io.Writer) *archiveWriter {
+	return &archiveWriter{w: bufio.NewWriter(w), hash: sha256.New()}
+}
+
+// write appends a line holding data, hashed unless it is the trailer
+func (writer *archiveWriter) write(typ string, data any) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("marshal %s: %w", typ, err)
+	}
+	encoded, err := json.Marshal(line{Type: typ, Data: raw})
+	if err != nil {
+		return fmt.Errorf("marshal %s: %w", typ, err)
+	}
+	encoded = append(encoded, '\n')
+	if typ != TypeTrailer {
+		writer.hash.Write(encoded)
+	}
+	_, err = writer.w.Write(encoded)
+	return err
+}
+
+// close writes the trailer and flushes the archive
+func (writer *archiveWriter) close() error {
+	trailer := Trailer{Counts: writer.counts, SHA256: hex.EncodeToString(writer.hash.Sum(nil))}
+	if err := writer.write(TypeTrailer, trailer); err != nil {
+		return err
+	}
+	return writer.w.Flush()
+}
+
+// archiveReader reads the lines of an archive, hashing them to check the trailer
+type archiveReader struct {
+	r    *bufio.Reader
+	hash hash.Hash
+	// number is the number of the last line read, for the errors
+	number int64
+}
+
+func newArchiveReader(r io.Reader) *archiveReader {
+	return &archiveReader{r: bufio.NewReader(r), hash: sha256.New()}
+}
+
+// next returns the next line, io.EOF once the archive is read
+func (reader *archiveReader) next() (line, error) {
+	raw, err := reader.r.ReadBytes('\n')
+	if len(raw) == 0 && err != nil {
+		return line{}, err
+	}
+	if err != nil && !errors.Is(err, io.EOF) {
+		return line{}, err
+	}
+	reader.number++
+
+	var l line
+	if err := json.Unmarshal(raw, &l); err != nil {
+		return line{}, reader.corrupt("%v", err)
+	}
+	if l.Type != TypeTrailer {
+		reader.hash.Write(raw)
+	}
+	return l, nil
+}
+
+// decode decodes the data of the last line read
+func (reader *archiveReader) decode(l line, v any) error {
+	decoder := json.NewDecoder(bytes.NewReader(l.Data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return reader.corrupt("%s: %v", l.Type, err)
+	}
+	return nil
+}
+
+// sum returns the hex encoded SHA-256 of the lines read before the trailer
+func (reader *archiveReader) sum() string {
+	return hex.EncodeToString(reader.hash.Sum(nil))
+}
+
+// corrupt returns an ErrCorruptArchive error about the last line read
+func (reader *archiveReader) corrupt(format string, args ...any) error {
+	return fmt.Errorf("%w: line %d: %s", ErrCorruptArchive, reader.number, fmt.Sprintf(format, args...))
+}
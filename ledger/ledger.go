@@ -0,0 +1,282 @@
+// Package ledger moves the complete state of a bank between environments. Export writes the
+// accounts, transfers and entries read from a single snapshot to a versioned JSON lines archive,
+// which Import restores into an empty database. The archive holds one record per line between a
+// header naming its format version and a trailer with the number of records of each type and the
+// SHA-256 of every line before it, so that a truncated or altered archive is refused.
+//
+// Users, sessions, the audit log, the outbox, webhooks, jobs and imports are not part of the ledger.
+package ledger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+)
+
+// batchSize is the number of records read or restored at once
+const batchSize = 1000
+
+// balanceTolerance is the largest difference between a balance and the sum of its entries
+// Import accepts, to absorb float rounding, like bankctl reconcile
+const balanceTolerance = 0.000001
+
+var (
+	// ErrNotEmpty is returned when importing into a database that already holds accounts, transfers or entries
+	ErrNotEmpty = errors.New("the database already holds a ledger")
+	// ErrUnsupportedVersion is returned when importing an archive of another format version
+	ErrUnsupportedVersion = errors.New("unsupported archive version")
+	// ErrCorruptArchive is returned when an archive is malformed, truncated or doesn't match its trailer
+	ErrCorruptArchive = errors.New("corrupt archive")
+	// ErrBalanceMismatch is returned when the balance of an imported account isn't the sum of its entries
+	ErrBalanceMismatch = errors.New("account balances do not match their entries")
+)
+
+// Export writes the ledger, read from a consistent snapshot of store, to w and returns the number of records written
+func Export(ctx context.Context, store db.Store, w io.Writer) (Counts, error) {
+	writer := newArchiveWriter(w)
+	err := store.ReadSnapshotTransaction(ctx, func(tx db.Store) error {
+		if err := writer.write(TypeHeader, Header{Version: FormatVersion, ExportedAt: time.Now().UTC()}); err != nil {
+			return err
+		}
+
+		err := exportSection(ctx, writer, TypeAccount, &writer.counts.Accounts,
+			func(afterID int64) ([]db.Account, error) {
+				return tx.ExportAccounts(ctx, db.ExportAccountsParams{AfterID: afterID, LimitCount: batchSize})
+			},
+			func(account db.Account) (int64, Account) { return account.ID, newAccount(account) })
+		if err != nil {
+			return err
+		}
+		err = exportSection(ctx, writer, TypeTransfer, &writer.counts.Transfers,
+			func(afterID int64) ([]db.Transfer, error) {
+				return tx.ExportTransfers(ctx, db.ExportTransfersParams{AfterID: afterID, LimitCount: batchSize})
+			},
+			func(transfer db.Transfer) (int64, Transfer) { return transfer.ID, newTransfer(transfer) })
+		if err != nil {
+			return err
+		}
+		err = exportSection(ctx, writer, TypeEntry, &writer.counts.Entries,
+			func(afterID int64) ([]db.Entry, error) {
+				return tx.ExportEntries(ctx, db.ExportEntriesParams{AfterID: afterID, LimitCount: batchSize})
+			},
+			func(entry db.Entry) (int64, Entry) { return entry.ID, newEntry(entry) })
+		if err != nil {
+			return err
+		}
+		return writer.close()
+	})
+	return writer.counts, err
+}
+
+// exportSection pages through the rows of a table by id, list returning the batch after an id, and
+// writes them as records of type typ
+func exportSection[Row, Record any](ctx context.Context, writer *archiveWriter, typ string, count *int64,
+	list func(afterID int64) ([]Row, error), record func(Row) (int64, Record)) error {
+	var afterID int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		rows, err := list(afterID)
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			id, data := record(row)
+			if err := writer.write(typ, data); err != nil {
+				return err
+			}
+			afterID = id
+			*count++
+		}
+		if len(rows) < batchSize {
+			return nil
+		}
+	}
+}
+
+// Import restores the archive read from r into the empty database of store and returns the number of
+// records restored. Everything is restored within a single db txn, which is only committed once the
+// archive matched its trailer and the balance of every account the sum of its entries. The sequences
+// of the ids are moved past the restored rows.
+func Import(ctx context.Context, store db.Store, r io.Reader) (Counts, error) {
+	var counts Counts
+	err := store.ExecTransaction(ctx, func(tx db.Store) error {
+		hasLedger, err := tx.HasLedger(ctx)
+		if err != nil {
+			return err
+		}
+		if hasLedger {
+			return ErrNotEmpty
+		}
+
+		restorer := &restorer{tx: tx}
+		if err := restorer.restore(ctx, newArchiveReader(r)); err != nil {
+			return err
+		}
+		if err := tx.ResetLedgerSequences(ctx); err != nil {
+			return err
+		}
+		counts = restorer.counts
+		return checkBalances(ctx, tx)
+	})
+	if err != nil {
+		return Counts{}, err
+	}
+	return counts, nil
+}
+
+// restorer copies the records of an archive into the tables, a batch at a time
+type restorer struct {
+	tx        db.Store
+	counts    Counts
+	accounts  []db.RestoreAccountsParams
+	transfers []db.RestoreTransfersParams
+	entries   []db.RestoreEntriesParams
+}
+
+func (restorer *restorer) restore(ctx context.Context, reader *archiveReader) error {
+	first, err := reader.next()
+	if errors.Is(err, io.EOF) {
+		return fmt.Errorf("%w: empty archive", ErrCorruptArchive)
+	}
+	if err != nil {
+		return err
+	}
+	if first.Type != TypeHeader {
+		return reader.corrupt("missing header")
+	}
+	var header Header
+	if err := reader.decode(first, &header); err != nil {
+		return err
+	}
+	if header.Version != FormatVersion {
+		return fmt.Errorf("%w %d, want %d", ErrUnsupportedVersion, header.Version, FormatVersion)
+	}
+
+	section := 0
+	for {
+		l, err := reader.next()
+		if errors.Is(err, io.EOF) {
+			return fmt.Errorf("%w: missing trailer, the archive is truncated", ErrCorruptArchive)
+		}
+		if err != nil {
+			return err
+		}
+		if l.Type == TypeTrailer {
+			if err := restorer.flush(ctx); err != nil {
+				return err
+			}
+			return restorer.checkTrailer(reader, l)
+		}
+
+		// the records of a section are all restored before the next one, which may refer to them
+		index := slices.Index(sections, l.Type)
+		if index < 0 {
+			return reader.corrupt("unknown record type %q", l.Type)
+		}
+		if index < section {
+			return reader.corrupt("%s record after the %s records", l.Type, sections[section])
+		}
+		if index > section {
+			if err := restorer.flush(ctx); err != nil {
+				return err
+			}
+			section = index
+		}
+		if err := restorer.add(ctx, reader, l); err != nil {
+			return err
+		}
+	}
+}
+
+// add buffers the record of a line, restoring the buffered records once a batch is full
+func (restorer *restorer) add(ctx context.Context, reader *archiveReader, l line) error {
+	switch l.Type {
+	case TypeAccount:
+		var record Account
+		if err := reader.decode(l, &record); err != nil {
+			return err
+		}
+		restorer.accounts = append(restorer.accounts, record.restoreParams())
+		restorer.counts.Accounts++
+	case TypeTransfer:
+		var record Transfer
+		if err := reader.decode(l, &record); err != nil {
+			return err
+		}
+		restorer.transfers = append(restorer.transfers, record.restoreParams())
+		restorer.counts.Transfers++
+	case TypeEntry:
+		var record Entry
+		if err := reader.decode(l, &record); err != nil {
+			return err
+		}
+		restorer.entries = append(restorer.entries, record.restoreParams())
+		restorer.counts.Entries++
+	}
+	if len(restorer.accounts)+len(restorer.transfers)+len(restorer.entries) < batchSize {
+		return nil
+	}
+	return restorer.flush(ctx)
+}
+
+// flush copies the buffered records into their table
+func (restorer *restorer) flush(ctx context.Context) error {
+	if len(restorer.accounts) > 0 {
+		if _, err := restorer.tx.RestoreAccounts(ctx, restorer.accounts); err != nil {
+			return err
+		}
+	}
+	if len(restorer.transfers) > 0 {
+		if _, err := restorer.tx.RestoreTransfers(ctx, restorer.transfers); err != nil {
+			return err
+		}
+	}
+	if len(restorer.entries) > 0 {
+		if _, err := restorer.tx.RestoreEntries(ctx, restorer.entries); err != nil {
+			return err
+		}
+	}
+	restorer.accounts, restorer.transfers, restorer.entries = nil, nil, nil
+	return nil
+}
+
+// checkTrailer checks that the archive holds the records its trailer counts and hashes, and nothing after it
+func (restorer *restorer) checkTrailer(reader *archiveReader, l line) error {
+	var trailer Trailer
+	if err := reader.decode(l, &trailer); err != nil {
+		return err
+	}
+	if sum := reader.sum(); trailer.SHA256 != sum {
+		return reader.corrupt("checksum %s does not match the trailer's %s", sum, trailer.SHA256)
+	}
+	if trailer.Counts != restorer.counts {
+		return reader.corrupt("read %+v records, the trailer counts %+v", restorer.counts, trailer.Counts)
+	}
+	if _, err := reader.next(); !errors.Is(err, io.EOF) {
+		return reader.corrupt("data after the trailer")
+	}
+	return nil
+}
+
+// checkBalances fails when the balance of an account isn't the sum of its entries
+func checkBalances(ctx context.Context, tx db.Store) error {
+	mismatches, err := tx.ListBalanceMismatches(ctx, balanceTolerance)
+	if err != nil {
+		return err
+	}
+	if len(mismatches) == 0 {
+		return nil
+	}
+	ids := make([]int64, len(mismatches))
+	for i, mismatch := range mismatches {
+		ids[i] = mismatch.ID
+	}
+	return fmt.Errorf("%w: accounts %v", ErrBalanceMismatch, ids)
+}
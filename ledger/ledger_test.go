@@ -0,0 +1,237 @@
+package ledger
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	mockdb "github.com/arpangoswami/backend-golang-dev/database/mock"
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+	"github.com/arpangoswami/backend-golang-dev/util"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+var createdAt = time.Date(2022, 3, 4, 5, 6, 7, 0, time.UTC)
+
+var (
+	testAccounts = []db.Account{
+		{ID: 1, Owner: "alice", Balance: 70, Currency: util.USD, CountryCode: sql.NullInt32{Int32: 91, Valid: true}, CreatedAt: createdAt},
+		{ID: 2, Owner: "bob", Balance: 30, Currency: util.USD, IsFrozen: true, CreatedAt: createdAt},
+	}
+	testTransfers = []db.Transfer{
+		{ID: 1, FromAccountID: 1, ToAccountID: 2, Amount: 40, CreatedAt: createdAt},
+		{ID: 2, FromAccountID: 2, ToAccountID: 1, Amount: 40, ReversesTransferID: sql.NullInt64{Int64: 1, Valid: true}, CreatedAt: createdAt},
+		{ID: 3, FromAccountID: 1, ToAccountID: 2, Amount: 30, CreatedAt: createdAt},
+	}
+	testEntries = []db.Entry{
+		{ID: 1, AccountID: 1, Amount: 100, CreatedAt: createdAt},
+		{ID: 2, AccountID: 1, Amount: -30, CreatedAt: createdAt},
+		{ID: 3, AccountID: 2, Amount: 30, CreatedAt: createdAt},
+	}
+)
+
+// inTransaction makes the mock store run the functions of its transactions against itself
+func inTransaction(store *mockdb.MockStore) func(ctx context.Context, fn func(tx db.Store) error) error {
+	return func(_ context.Context, fn func(tx db.Store) error) error {
+		return fn(store)
+	}
+}
+
+// export exports the test ledger with the mock store
+func export(t *testing.T) []byte {
+	t.Helper()
+	store := mockdb.NewMockStore(gomock.NewController(t))
+	store.EXPECT().ReadSnapshotTransaction(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(inTransaction(store))
+	store.EXPECT().ExportAccounts(gomock.Any(), gomock.Eq(db.ExportAccountsParams{LimitCount: batchSize})).
+		Times(1).Return(testAccounts, nil)
+	store.EXPECT().ExportTransfers(gomock.Any(), gomock.Eq(db.ExportTransfersParams{LimitCount: batchSize})).
+		Times(1).Return(testTransfers, nil)
+	store.EXPECT().ExportEntries(gomock.Any(), gomock.Eq(db.ExportEntriesParams{LimitCount: batchSize})).
+		Times(1).Return(testEntries, nil)
+
+	var archive bytes.Buffer
+	counts, err := Export(context.Background(), store, &archive)
+	assert.NoError(t, err)
+	assert.Equal(t, Counts{Accounts: 2, Transfers: 3, Entries: 3}, counts)
+	return archive.Bytes()
+}
+
+// expectImport makes the mock store accept the import of an archive into an empty database
+func expectImport(store *mockdb.MockStore) {
+	store.EXPECT().ExecTransaction(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(inTransaction(store))
+	store.EXPECT().HasLedger(gomock.Any()).Times(1).Return(false, nil)
+}
+
+func TestExport(t *testing.T) {
+	archive := export(t)
+	lines := strings.Split(strings.TrimSuffix(string(archive), "\n"), "\n")
+	assert.Len(t, lines, 10)
+
+	var first line
+	assert.NoError(t, json.Unmarshal([]byte(lines[0]), &first))
+	assert.Equal(t, TypeHeader, first.Type)
+	var header Header
+	assert.NoError(t, json.Unmarshal(first.Data, &header))
+	assert.Equal(t, FormatVersion, header.Version)
+
+	types := make([]string, len(lines))
+	for i, raw := range lines {
+		var l line
+		assert.NoError(t, json.Unmarshal([]byte(raw), &l))
+		types[i] = l.Type
+	}
+	assert.Equal(t, []string{
+		TypeHeader, TypeAccount, TypeAccount, TypeTransfer, TypeTransfer, TypeTransfer,
+		TypeEntry, TypeEntry, TypeEntry, TypeTrailer,
+	}, types)
+	assert.Contains(t, lines[1], `"country_code":91`)
+	assert.Contains(t, lines[2], `"country_code":null`)
+	assert.Contains(t, lines[4], `"reverses_transfer_id":1`)
+}
+
+func TestExport_Pages(t *testing.T) {
+	store := mockdb.NewMockStore(gomock.NewController(t))
+	store.EXPECT().ReadSnapshotTransaction(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(inTransaction(store))
+
+	page := make([]db.Account, batchSize)
+	for i := range page {
+		page[i] = db.Account{ID: int64(i + 1), Owner: util.RandomOwner(), Currency: util.USD, CreatedAt: createdAt}
+	}
+	gomock.InOrder(
+		store.EXPECT().ExportAccounts(gomock.Any(), gomock.Eq(db.ExportAccountsParams{LimitCount: batchSize})).
+			Times(1).Return(page, nil),
+		store.EXPECT().ExportAccounts(gomock.Any(), gomock.Eq(db.ExportAccountsParams{AfterID: batchSize, LimitCount: batchSize})).
+			Times(1).Return(testAccounts[:0], nil),
+	)
+	store.EXPECT().ExportTransfers(gomock.Any(), gomock.Any()).Times(1).Return(nil, nil)
+	store.EXPECT().ExportEntries(gomock.Any(), gomock.Any()).Times(1).Return(nil, nil)
+
+	counts, err := Export(context.Background(), store, &bytes.Buffer{})
+	assert.NoError(t, err)
+	assert.Equal(t, Counts{Accounts: batchSize}, counts)
+}
+
+func TestImport(t *testing.T) {
+	archive := export(t)
+
+	store := mockdb.NewMockStore(gomock.NewController(t))
+	expectImport(store)
+	var accounts []db.RestoreAccountsParams
+	var transfers []db.RestoreTransfersParams
+	var entries []db.RestoreEntriesParams
+	gomock.InOrder(
+		store.EXPECT().RestoreAccounts(gomock.Any(), gomock.Any()).Times(1).
+			DoAndReturn(func(_ context.Context, arg []db.RestoreAccountsParams) (int64, error) {
+				accounts = arg
+				return int64(len(arg)), nil
+			}),
+		store.EXPECT().RestoreTransfers(gomock.Any(), gomock.Any()).Times(1).
+			DoAndReturn(func(_ context.Context, arg []db.RestoreTransfersParams) (int64, error) {
+				transfers = arg
+				return int64(len(arg)), nil
+			}),
+		store.EXPECT().RestoreEntries(gomock.Any(), gomock.Any()).Times(1).
+			DoAndReturn(func(_ context.Context, arg []db.RestoreEntriesParams) (int64, error) {
+				entries = arg
+				return int64(len(arg)), nil
+			}),
+		store.EXPECT().ResetLedgerSequences(gomock.Any()).Times(1).Return(nil),
+		store.EXPECT().ListBalanceMismatches(gomock.Any(), gomock.Eq(balanceTolerance)).Times(1).Return(nil, nil),
+	)
+
+	counts, err := Import(context.Background(), store, bytes.NewReader(archive))
+	assert.NoError(t, err)
+	assert.Equal(t, Counts{Accounts: 2, Transfers: 3, Entries: 3}, counts)
+
+	// the records are restored as they were exported
+	assert.Len(t, accounts, len(testAccounts))
+	for i, account := range testAccounts {
+		assert.Equal(t, db.RestoreAccountsParams(account), accounts[i])
+	}
+	assert.Len(t, transfers, len(testTransfers))
+	for i, transfer := range testTransfers {
+		assert.Equal(t, db.RestoreTransfersParams(transfer), transfers[i])
+	}
+	assert.Len(t, entries, len(testEntries))
+	for i, entry := range testEntries {
+		assert.Equal(t, db.RestoreEntriesParams(entry), entries[i])
+	}
+}
+
+func TestImport_NotEmpty(t *testing.T) {
+	store := mockdb.NewMockStore(gomock.NewController(t))
+	store.EXPECT().ExecTransaction(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(inTransaction(store))
+	store.EXPECT().HasLedger(gomock.Any()).Times(1).Return(true, nil)
+
+	_, err := Import(context.Background(), store, bytes.NewReader(export(t)))
+	assert.ErrorIs(t, err, ErrNotEmpty)
+}
+
+func TestImport_BalanceMismatch(t *testing.T) {
+	archive := export(t)
+
+	store := mockdb.NewMockStore(gomock.NewController(t))
+	expectImport(store)
+	store.EXPECT().RestoreAccounts(gomock.Any(), gomock.Any()).Times(1).Return(int64(2), nil)
+	store.EXPECT().RestoreTransfers(gomock.Any(), gomock.Any()).Times(1).Return(int64(3), nil)
+	store.EXPECT().RestoreEntries(gomock.Any(), gomock.Any()).Times(1).Return(int64(3), nil)
+	store.EXPECT().ResetLedgerSequences(gomock.Any()).Times(1).Return(nil)
+	store.EXPECT().ListBalanceMismatches(gomock.Any(), gomock.Any()).Times(1).
+		Return([]db.ListBalanceMismatchesRow{{ID: 2, Balance: 30, EntriesTotal: 20}}, nil)
+
+	_, err := Import(context.Background(), store, bytes.NewReader(archive))
+	assert.ErrorIs(t, err, ErrBalanceMismatch)
+	assert.ErrorContains(t, err, "accounts [2]")
+}
+
+func TestImport_Corrupt(t *testing.T) {
+	archive := string(export(t))
+	lines := strings.SplitAfter(archive, "\n")
+	header := lines[0]
+	trailer := lines[len(lines)-2]
+
+	testCases := []struct {
+		name    string
+		archive string
+		err     error
+	}{
+		{name: "Empty", archive: "", err: ErrCorruptArchive},
+		{name: "NotJSON", archive: "ledger\n", err: ErrCorruptArchive},
+		{name: "MissingHeader", archive: strings.Join(lines[1:], ""), err: ErrCorruptArchive},
+		{
+			name:    "UnsupportedVersion",
+			archive: strings.Replace(archive, `"version":1`, `"version":2`, 1),
+			err:     ErrUnsupportedVersion,
+		},
+		{name: "Truncated", archive: strings.Join(lines[:len(lines)-2], ""), err: ErrCorruptArchive},
+		{
+			name:    "Altered",
+			archive: strings.Replace(archive, `"owner":"bob"`, `"owner":"eve"`, 1),
+			err:     ErrCorruptArchive,
+		},
+		{name: "DataAfterTrailer", archive: archive + lines[1], err: ErrCorruptArchive},
+		{name: "UnknownType", archive: header + `{"type":"user","data":{}}` + "\n" + trailer, err: ErrCorruptArchive},
+		{name: "UnknownField", archive: header + `{"type":"entry","data":{"iban":"x"}}` + "\n" + trailer, err: ErrCorruptArchive},
+		{name: "OutOfOrder", archive: header + lines[7] + lines[1] + trailer, err: ErrCorruptArchive},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// nothing restored is committed, the txn being rolled back on the error
+			store := mockdb.NewMockStore(gomock.NewController(t))
+			expectImport(store)
+			store.EXPECT().RestoreAccounts(gomock.Any(), gomock.Any()).AnyTimes().Return(int64(0), nil)
+			store.EXPECT().RestoreTransfers(gomock.Any(), gomock.Any()).AnyTimes().Return(int64(0), nil)
+			store.EXPECT().RestoreEntries(gomock.Any(), gomock.Any()).AnyTimes().Return(int64(0), nil)
+			store.EXPECT().ResetLedgerSequences(gomock.Any()).Times(0)
+
+			_, err := Import(context.Background(), store, strings.NewReader(tc.archive))
+			assert.ErrorIs(t, err, tc.err)
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package maintenance
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+)
+
+// DefaultArchiveBatch is how many rows of a table an archive or a restore moves per statement
+const DefaultArchiveBatch = 5000
+
+// ArchiveLedger is the job moving the entries and transfers older than Age to entries_archive and
+// transfers_archive. The sum of the archived entries of each account is added to its balance
+// checkpoint, so that balances still reconcile with what is left in entries.
+type ArchiveLedger struct {
+	Age   time.Duration `json:"age"`
+	Batch int32         `json:"batch,omitempty"`
+}
+
+func (ArchiveLedger) Kind() string { return "maintenance.archive_ledger" }
+
+// Run archives the rows older than Age and returns how many it moved
+func (args ArchiveLedger) Run(ctx context.Context, store db.Querier) (ArchiveResult, error) {
+	return ArchiveBefore(ctx, store, time.Now().Add(-args.Age), args.Batch)
+}
+
+// ArchiveResult counts the rows moved by an archive or a restore
+type ArchiveResult struct {
+	Entries   int64 `json:"entries"`
+	Transfers int64 `json:"transfers"`
+}
+
+// ArchiveBefore moves the entries and transfers created before the given time to the archive,
+// batch rows at a time so that no statement holds its locks for long. A zero batch means
+// DefaultArchiveBatch.
+func ArchiveBefore(ctx context.Context, store db.Querier, before time.Time, batch int32) (ArchiveResult, error) {
+	before = before.UTC()
+	var result ArchiveResult
+	var err error
+	result.Entries, err = moveBatches(ctx, "archive entries", batch, func(limit int32) (int64, error) {
+		return store.ArchiveEntries(ctx, db.ArchiveEntriesParams{Before: before, LimitCount: limit})
+	})
+	if err != nil {
+		return result, err
+	}
+	result.Transfers, err = moveBatches(ctx, "archive transfers", batch, func(limit int32) (int64, error) {
+		return store.ArchiveTransfers(ctx, db.ArchiveTransfersParams{Before: before, LimitCount: limit})
+	})
+	if err != nil {
+		return result, err
+	}
+	if result.Entries > 0 || result.Transfers > 0 {
+		log.Printf("archived %d entries and %d transfers created before %s",
+			result.Entries, result.Transfers, before.Format(time.RFC3339))
+	}
+	return result, nil
+}
+
+// RestoreSince moves the archived entries and transfers created at or after the given time back to
+// entries and transfers, taking the entries off the balance checkpoints
+func RestoreSince(ctx context.Context, store db.Querier, since time.Time, batch int32) (ArchiveResult, error) {
+	since = since.UTC()
+	var result ArchiveResult
+	var err error
+	result.Entries, err = moveBatches(ctx, "restore entries", batch, func(limit int32) (int64, error) {
+		return store.RestoreArchivedEntries(ctx, db.RestoreArchivedEntriesParams{Since: since, LimitCount: limit})
+	})
+	if err != nil {
+		return result, err
+	}
+	result.Transfers, err = moveBatches(ctx, "restore transfers", batch, func(limit int32) (int64, error) {
+		return store.RestoreArchivedTransfers(ctx, db.RestoreArchivedTransfersParams{Since: since, LimitCount: limit})
+	})
+	return result, err
+}
+
+// moveBatches calls move until it moves less than a full batch and returns the total moved
+func moveBatches(ctx context.Context, name string, batch int32, move func(limit int32) (int64, error)) (int64, error) {
+	if batch <= 0 {
+		batch = DefaultArchiveBatch
+	}
+	var total int64
+	for {
+		moved, err := move(batch)
+		if err != nil {
+			return total, fmt.Errorf("%s: %w", name, err)
+		}
+		total += moved
+		if moved < int64(batch) {
+			return total, nil
+		}
+		if err := ctx.Err(); err != nil {
+			return total, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	mockdb "github.com/arpangoswami/backend-golang-dev/database/mock"
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestArchiveLedger_Run(t *testing.T) {
+	store := mockdb.NewMockStore(gomock.NewController(t))
+	var before time.Time
+	gomock.InOrder(
+		store.EXPECT().ArchiveEntries(gomock.Any(), gomock.Any()).Times(1).
+			DoAndReturn(func(_ context.Context, arg db.ArchiveEntriesParams) (int64, error) {
+				before = arg.Before
+				assert.Equal(t, int32(2), arg.LimitCount)
+				return 2, nil
+			}),
+		// a batch short of the limit is the last one
+		store.EXPECT().ArchiveEntries(gomock.Any(), gomock.Any()).Times(1).Return(int64(1), nil),
+		store.EXPECT().ArchiveTransfers(gomock.Any(), gomock.Any()).Times(1).
+			DoAndReturn(func(_ context.Context, arg db.ArchiveTransfersParams) (int64, error) {
+				assert.Equal(t, before, arg.Before)
+				return 0, nil
+			}),
+	)
+
+	result, err := ArchiveLedger{Age: 24 * time.Hour, Batch: 2}.Run(context.Background(), store)
+	assert.NoError(t, err)
+	assert.Equal(t, ArchiveResult{Entries: 3}, result)
+	// created_at holds UTC
+	assert.Equal(t, time.UTC, before.Location())
+	assert.WithinDuration(t, time.Now().Add(-24*time.Hour), before, time.Minute)
+}
+
+func TestArchiveLedger_RunFailed(t *testing.T) {
+	store := mockdb.NewMockStore(gomock.NewController(t))
+	failure := errors.New("deadlock detected")
+	store.EXPECT().ArchiveEntries(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), failure)
+	store.EXPECT().ArchiveTransfers(gomock.Any(), gomock.Any()).Times(0)
+
+	_, err := ArchiveLedger{Age: time.Hour}.Run(context.Background(), store)
+	assert.ErrorIs(t, err, failure)
+	assert.ErrorContains(t, err, "archive entries")
+}
+
+func TestRestoreSince(t *testing.T) {
+	store := mockdb.NewMockStore(gomock.NewController(t))
+	since := time.Date(2024, time.March, 1, 1, 0, 0, 0, time.FixedZone("CET", 3600))
+	store.EXPECT().RestoreArchivedEntries(gomock.Any(), gomock.Eq(db.RestoreArchivedEntriesParams{
+		Since:      since.UTC(),
+		LimitCount: DefaultArchiveBatch,
+	})).Times(1).Return(int64(4), nil)
+	store.EXPECT().RestoreArchivedTransfers(gomock.Any(), gomock.Any()).Times(1).Return(int64(2), nil)
+
+	result, err := RestoreSince(context.Background(), store, since, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, ArchiveResult{Entries: 4, Transfers: 2}, result)
+}
+
+func TestArchiveBefore_Cancelled(t *testing.T) {
+	store := mockdb.NewMockStore(gomock.NewController(t))
+	ctx, cancel := context.WithCancel(context.Background())
+	// full batches would go on, the job stops between them once cancelled
+	store.EXPECT().ArchiveEntries(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(context.Context, db.ArchiveEntriesParams) (int64, error) {
+			cancel()
+			return 10, nil
+		})
+
+	result, err := ArchiveBefore(ctx, store, time.Now(), 10)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, int64(10), result.Entries)
+}
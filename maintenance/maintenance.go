@@ -0,0 +1,68 @@
+// Package maintenance runs the recurring jobs keeping the database in shape. A Scheduler enqueues
+// them on the queue every interval, at most one of each kind being queued at once, so that any
+// number of servers can run a scheduler and a worker without running a job twice.
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+	"github.com/arpangoswami/backend-golang-dev/queue"
+)
+
+// DefaultInterval is how often a scheduler enqueues its jobs
+const DefaultInterval = time.Hour
+
+// Register registers the handlers of the maintenance jobs
+func Register(registry *queue.Registry, store db.Store) {
+	queue.Register(registry, func(ctx context.Context, _ db.Job, args CreatePartitions) error {
+		_, err := args.Run(ctx, store)
+		return err
+	})
+	queue.Register(registry, func(ctx context.Context, _ db.Job, args ArchiveLedger) error {
+		_, err := args.Run(ctx, store)
+		return err
+	})
+}
+
+// Scheduler enqueues recurring jobs
+type Scheduler struct {
+	store    db.Store
+	jobs     []queue.Args
+	interval time.Duration
+}
+
+// NewScheduler returns a scheduler enqueueing jobs every interval
+func NewScheduler(store db.Store, interval time.Duration, jobs ...queue.Args) *Scheduler {
+	return &Scheduler{store: store, jobs: jobs, interval: interval}
+}
+
+// ScheduleOnce enqueues the jobs that aren't already pending or running
+func (scheduler *Scheduler) ScheduleOnce(ctx context.Context) error {
+	var errs []error
+	for _, args := range scheduler.jobs {
+		_, err := queue.Enqueue(ctx, scheduler.store, args, queue.Options{UniqueKey: args.Kind()})
+		if err != nil && !errors.Is(err, queue.ErrDuplicateJob) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Run enqueues the jobs right away and then every interval until ctx is cancelled
+func (scheduler *Scheduler) Run(ctx context.Context) {
+	for {
+		if err := scheduler.ScheduleOnce(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("maintenance scheduler: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(scheduler.interval):
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package maintenance
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	mockdb "github.com/arpangoswami/backend-golang-dev/database/mock"
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestScheduler_ScheduleOnce(t *testing.T) {
+	store := mockdb.NewMockStore(gomock.NewController(t))
+	args := CreatePartitions{MonthsAhead: 3}
+
+	store.EXPECT().EnqueueJob(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, arg db.EnqueueJobParams) (db.Job, error) {
+			assert.Equal(t, args.Kind(), arg.Kind)
+			assert.JSONEq(t, `{"months_ahead":3}`, string(arg.Payload))
+			assert.Equal(t, sql.NullString{String: args.Kind(), Valid: true}, arg.UniqueKey)
+			return db.Job{ID: 1, Kind: arg.Kind}, nil
+		})
+	scheduler := NewScheduler(store, DefaultInterval, args)
+	assert.NoError(t, scheduler.ScheduleOnce(context.Background()))
+
+	// the job is still queued from the last time
+	store.EXPECT().EnqueueJob(gomock.Any(), gomock.Any()).Times(1).Return(db.Job{}, sql.ErrNoRows)
+	assert.NoError(t, scheduler.ScheduleOnce(context.Background()))
+
+	store.EXPECT().EnqueueJob(gomock.Any(), gomock.Any()).Times(1).Return(db.Job{}, sql.ErrConnDone)
+	assert.ErrorIs(t, scheduler.ScheduleOnce(context.Background()), sql.ErrConnDone)
+}
+
+func TestCreatePartitions_Run(t *testing.T) {
+	store := mockdb.NewMockStore(gomock.NewController(t))
+	gomock.InOrder(
+		store.EXPECT().CreateMonthlyPartitions(gomock.Any(), gomock.Eq(db.CreateMonthlyPartitionsParams{TableName: "entries", MonthsAhead: 2})).
+			Times(1).Return(int32(1), nil),
+		store.EXPECT().CreateMonthlyPartitions(gomock.Any(), gomock.Eq(db.CreateMonthlyPartitionsParams{TableName: "transfers", MonthsAhead: 2})).
+			Times(1).Return(int32(0), nil),
+	)
+
+	created, err := CreatePartitions{MonthsAhead: 2}.Run(context.Background(), store)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), created)
+}
+
+func TestCreatePartitions_RunFailed(t *testing.T) {
+	store := mockdb.NewMockStore(gomock.NewController(t))
+	failure := errors.New("permission denied")
+	store.EXPECT().CreateMonthlyPartitions(gomock.Any(), gomock.Any()).Times(1).Return(int32(0), failure)
+
+	_, err := CreatePartitions{MonthsAhead: 2}.Run(context.Background(), store)
+	assert.ErrorIs(t, err, failure)
+	assert.ErrorContains(t, err, "entries")
+}
@@ -0,0 +1,39 @@
+package maintenance
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+)
+
+// PartitionedTables are the tables partitioned by month of created_at
+var PartitionedTables = []string{"entries", "transfers"}
+
+// CreatePartitions is the job creating the monthly partitions of PartitionedTables up to
+// MonthsAhead months after the current one, so that new rows never land in the default partition
+type CreatePartitions struct {
+	MonthsAhead int32 `json:"months_ahead"`
+}
+
+func (CreatePartitions) Kind() string { return "maintenance.create_partitions" }
+
+// Run creates the missing partitions and returns how many it created
+func (args CreatePartitions) Run(ctx context.Context, store db.Querier) (int32, error) {
+	var total int32
+	for _, table := range PartitionedTables {
+		created, err := store.CreateMonthlyPartitions(ctx, db.CreateMonthlyPartitionsParams{
+			TableName:   table,
+			MonthsAhead: args.MonthsAhead,
+		})
+		if err != nil {
+			return total, fmt.Errorf("create partitions of %s: %w", table, err)
+		}
+		if created > 0 {
+			log.Printf("created %d partition(s) of %s", created, table)
+		}
+		total += created
+	}
+	return total, nil
+}
@@ -0,0 +1,57 @@
+// Package outbox delivers the domain events the Store writes to outbox_events.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+)
+
+// Publisher delivers an event downstream. It is called at least once per event, in
+// order per aggregate, and must be idempotent as a failed commit can replay an event.
+type Publisher interface {
+	Publish(ctx context.Context, event db.OutboxEvent) error
+}
+
+// WriterPublisher writes every event as a line of JSON, for local use
+type WriterPublisher struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+	closer  io.Closer
+}
+
+// NewWriterPublisher returns a publisher writing to w, such as os.Stdout
+func NewWriterPublisher(w io.Writer) *WriterPublisher {
+	return &WriterPublisher{encoder: json.NewEncoder(w)}
+}
+
+// OpenFilePublisher returns a publisher appending to the file at path, created if missing
+func OpenFilePublisher(path string) (*WriterPublisher, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open outbox file: %w", err)
+	}
+	publisher := NewWriterPublisher(file)
+	publisher.closer = file
+	return publisher, nil
+}
+
+// Publish writes the event on its own line
+func (publisher *WriterPublisher) Publish(_ context.Context, event db.OutboxEvent) error {
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+	return publisher.encoder.Encode(event)
+}
+
+// Close closes the file opened by OpenFilePublisher, it does nothing for other writers
+func (publisher *WriterPublisher) Close() error {
+	if publisher.closer == nil {
+		return nil
+	}
+	return publisher.closer.Close()
+}
@@ -0,0 +1,64 @@
+package outbox
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+	"github.com/stretchr/testify/assert"
+)
+
+func randomEvent(id int64, aggregateID int64) db.OutboxEvent {
+	return db.OutboxEvent{
+		ID:            id,
+		AggregateType: db.EntityTransfer,
+		AggregateID:   aggregateID,
+		EventType:     db.EventTransferCompleted,
+		Payload:       json.RawMessage(`{"amount":10}`),
+	}
+}
+
+func TestWriterPublisher(t *testing.T) {
+	var buf bytes.Buffer
+	publisher := NewWriterPublisher(&buf)
+
+	events := []db.OutboxEvent{randomEvent(1, 7), randomEvent(2, 8)}
+	for _, event := range events {
+		assert.NoError(t, publisher.Publish(context.Background(), event))
+	}
+	assert.NoError(t, publisher.Close())
+
+	scanner := bufio.NewScanner(&buf)
+	for _, want := range events {
+		assert.True(t, scanner.Scan())
+		var got db.OutboxEvent
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &got))
+		assert.Equal(t, want.ID, got.ID)
+		assert.JSONEq(t, string(want.Payload), string(got.Payload))
+	}
+	assert.False(t, scanner.Scan())
+}
+
+func TestOpenFilePublisher(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.jsonl")
+
+	// events are appended across restarts
+	for id := int64(1); id <= 2; id++ {
+		publisher, err := OpenFilePublisher(path)
+		assert.NoError(t, err)
+		assert.NoError(t, publisher.Publish(context.Background(), randomEvent(id, 7)))
+		assert.NoError(t, publisher.Close())
+	}
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, bytes.Count(data, []byte("\n")))
+
+	_, err = OpenFilePublisher(filepath.Join(t.TempDir(), "missing", "outbox.jsonl"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,55 @@
+package outbox
+
+import (
+	"context"
+	"log"
+	"time"
+
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+)
+
+// DefaultBatchSize is the number of events a relay claims at once
+const DefaultBatchSize = 100
+
+// Relay polls the outbox and hands the unpublished events to a publisher
+type Relay struct {
+	store     db.Store
+	publisher Publisher
+	interval  time.Duration
+	batchSize int32
+}
+
+// NewRelay returns a relay checking the outbox every interval while it is empty
+func NewRelay(store db.Store, publisher Publisher, interval time.Duration) *Relay {
+	return &Relay{
+		store:     store,
+		publisher: publisher,
+		interval:  interval,
+		batchSize: DefaultBatchSize,
+	}
+}
+
+// RelayOnce publishes one batch of events and returns how many were published
+func (relay *Relay) RelayOnce(ctx context.Context) (int, error) {
+	return relay.store.RelayOutboxTransaction(ctx, relay.batchSize, relay.publisher.Publish)
+}
+
+// Run relays events until ctx is cancelled. Full batches are followed by the next one right
+// away; failures are logged and retried after the interval, keeping the events in order.
+func (relay *Relay) Run(ctx context.Context) {
+	for {
+		published, err := relay.RelayOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("outbox relay: %v", err)
+		}
+		if err == nil && published == int(relay.batchSize) {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(relay.interval):
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	mockdb "github.com/arpangoswami/backend-golang-dev/database/mock"
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+// publisherFunc adapts a function to the Publisher interface
+type publisherFunc func(ctx context.Context, event db.OutboxEvent) error
+
+func (f publisherFunc) Publish(ctx context.Context, event db.OutboxEvent) error {
+	return f(ctx, event)
+}
+
+// relayEvents makes the mock store hand events to the publisher like the real one
+func relayEvents(events []db.OutboxEvent) func(context.Context, int32, func(context.Context, db.OutboxEvent) error) (int, error) {
+	return func(ctx context.Context, limit int32, publish func(context.Context, db.OutboxEvent) error) (int, error) {
+		var errs []error
+		for _, event := range events {
+			if err := publish(ctx, event); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return len(events) - len(errs), errors.Join(errs...)
+	}
+}
+
+func TestRelay_RelayOnce(t *testing.T) {
+	store := mockdb.NewMockStore(gomock.NewController(t))
+	events := []db.OutboxEvent{randomEvent(1, 7), randomEvent(2, 7)}
+	store.EXPECT().RelayOutboxTransaction(gomock.Any(), gomock.Eq(int32(DefaultBatchSize)), gomock.Any()).
+		Times(1).DoAndReturn(relayEvents(events))
+
+	var got []int64
+	relay := NewRelay(store, publisherFunc(func(ctx context.Context, event db.OutboxEvent) error {
+		got = append(got, event.ID)
+		return nil
+	}), time.Second)
+
+	published, err := relay.RelayOnce(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, published)
+	assert.Equal(t, []int64{1, 2}, got)
+}
+
+func TestRelay_Run(t *testing.T) {
+	store := mockdb.NewMockStore(gomock.NewController(t))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// a full batch is followed by the next one right away, a failure waits for the interval
+	relay := NewRelay(store, publisherFunc(func(ctx context.Context, event db.OutboxEvent) error { return nil }), time.Millisecond)
+	relay.batchSize = 2
+	gomock.InOrder(
+		store.EXPECT().RelayOutboxTransaction(gomock.Any(), gomock.Eq(int32(2)), gomock.Any()).
+			Return(2, nil),
+		store.EXPECT().RelayOutboxTransaction(gomock.Any(), gomock.Eq(int32(2)), gomock.Any()).
+			Return(0, errors.New("publisher unavailable")),
+		store.EXPECT().RelayOutboxTransaction(gomock.Any(), gomock.Eq(int32(2)), gomock.Any()).
+			DoAndReturn(func(context.Context, int32, func(context.Context, db.OutboxEvent) error) (int, error) {
+				cancel()
+				return 0, ctx.Err()
+			}),
+	)
+
+	done := make(chan struct{})
+	go func() {
+		relay.Run(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("relay did not stop after its context was cancelled")
+	}
+}
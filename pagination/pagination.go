@@ -0,0 +1,120 @@
+// Package pagination implements keyset pagination over rows ordered by (created_at, id).
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// ErrInvalidCursor is returned for a cursor that was not produced by this package
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Key is the position of a row in the (created_at, id) order
+type Key struct {
+	CreatedAt time.Time
+	ID        int64
+}
+
+// last is past every row, descending listings start from it
+var last = Key{CreatedAt: time.Date(9999, time.December, 31, 23, 59, 59, 0, time.UTC), ID: math.MaxInt64}
+
+// cursor is serialized into the opaque string handed to clients
+type cursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        int64     `json:"i"`
+	Backward  bool      `json:"b,omitempty"`
+}
+
+func encode(key Key, backward bool) string {
+	data, _ := json.Marshal(cursor{CreatedAt: key.CreatedAt, ID: key.ID, Backward: backward})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decode(value string) (cursor, error) {
+	var c cursor
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return c, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return c, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	if c.ID < 1 {
+		return c, fmt.Errorf("%w: missing position", ErrInvalidCursor)
+	}
+	return c, nil
+}
+
+// Page is a slice of rows in the order of the listing with the cursors of its neighbours.
+// A cursor is empty when there is no page in that direction.
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+}
+
+// Fetch loads up to limit rows after, or before, key. Rows before key come in descending order.
+type Fetch[T any] func(key Key, limit int32) ([]T, error)
+
+// Paginate loads the page designated by an opaque cursor, the first page for an empty one.
+// after and before are the two directions of the same keyset query, keyOf returns the key of a row.
+// A descending listing starts with the newest rows and moves on with before.
+func Paginate[T any](value string, pageSize int32, descending bool, keyOf func(T) Key, after, before Fetch[T]) (Page[T], error) {
+	if descending {
+		after, before = before, after
+	}
+
+	first := value == ""
+	var c cursor
+	if first && descending {
+		c = cursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+	if !first {
+		var err error
+		if c, err = decode(value); err != nil {
+			return Page[T]{}, err
+		}
+	}
+
+	// one extra row tells whether there is another page in the direction of travel
+	fetch := after
+	if c.Backward {
+		fetch = before
+	}
+	items, err := fetch(Key{CreatedAt: c.CreatedAt, ID: c.ID}, pageSize+1)
+	if err != nil {
+		return Page[T]{}, err
+	}
+	more := int32(len(items)) > pageSize
+	if more {
+		items = items[:pageSize]
+	}
+
+	page := Page[T]{Items: items}
+	if len(items) == 0 {
+		return page, nil
+	}
+
+	if c.Backward {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+		if more {
+			page.PrevCursor = encode(keyOf(items[0]), true)
+		}
+		page.NextCursor = encode(keyOf(items[len(items)-1]), false)
+		return page, nil
+	}
+
+	if !first {
+		page.PrevCursor = encode(keyOf(items[0]), true)
+	}
+	if more {
+		page.NextCursor = encode(keyOf(items[len(items)-1]), false)
+	}
+	return page, nil
+}
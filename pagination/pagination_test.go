@@ -0,0 +1,154 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type row struct {
+	ID        int64
+	CreatedAt time.Time
+}
+
+func keyOf(r row) Key {
+	return Key{CreatedAt: r.CreatedAt, ID: r.ID}
+}
+
+func less(a, b Key) bool {
+	if a.CreatedAt.Equal(b.CreatedAt) {
+		return a.ID < b.ID
+	}
+	return a.CreatedAt.Before(b.CreatedAt)
+}
+
+// table emulates the keyset queries over rows sorted by (created_at, id)
+func table(rows []row) (after, before Fetch[row]) {
+	sort.Slice(rows, func(i, j int) bool { return less(keyOf(rows[i]), keyOf(rows[j])) })
+
+	after = func(key Key, limit int32) ([]row, error) {
+		var result []row
+		for _, r := range rows {
+			if less(key, keyOf(r)) && int32(len(result)) < limit {
+				result = append(result, r)
+			}
+		}
+		return result, nil
+	}
+	before = func(key Key, limit int32) ([]row, error) {
+		var result []row
+		for i := len(rows) - 1; i >= 0; i-- {
+			if less(keyOf(rows[i]), key) && int32(len(result)) < limit {
+				result = append(result, rows[i])
+			}
+		}
+		return result, nil
+	}
+	return after, before
+}
+
+func ids(rows []row) []int64 {
+	result := make([]int64, len(rows))
+	for i, r := range rows {
+		result[i] = r.ID
+	}
+	return result
+}
+
+func TestPaginate(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	// rows 3 and 4 share a timestamp, the id breaks the tie
+	after, before := table([]row{
+		{ID: 5, CreatedAt: now.Add(4 * time.Second)},
+		{ID: 1, CreatedAt: now},
+		{ID: 2, CreatedAt: now.Add(time.Second)},
+		{ID: 4, CreatedAt: now.Add(2 * time.Second)},
+		{ID: 3, CreatedAt: now.Add(2 * time.Second)},
+	})
+
+	page1, err := Paginate("", 2, false, keyOf, after, before)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1, 2}, ids(page1.Items))
+	assert.Empty(t, page1.PrevCursor)
+	assert.NotEmpty(t, page1.NextCursor)
+
+	page2, err := Paginate(page1.NextCursor, 2, false, keyOf, after, before)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{3, 4}, ids(page2.Items))
+	assert.NotEmpty(t, page2.PrevCursor)
+
+	page3, err := Paginate(page2.NextCursor, 2, false, keyOf, after, before)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{5}, ids(page3.Items))
+	assert.Empty(t, page3.NextCursor)
+
+	// walking back gives the same pages
+	back2, err := Paginate(page3.PrevCursor, 2, false, keyOf, after, before)
+	assert.NoError(t, err)
+	assert.Equal(t, page2.Items, back2.Items)
+	assert.Equal(t, page2.NextCursor, back2.NextCursor)
+
+	back1, err := Paginate(back2.PrevCursor, 2, false, keyOf, after, before)
+	assert.NoError(t, err)
+	assert.Equal(t, page1.Items, back1.Items)
+	assert.Empty(t, back1.PrevCursor)
+}
+
+func TestPaginate_Descending(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	after, before := table([]row{
+		{ID: 1, CreatedAt: now},
+		{ID: 2, CreatedAt: now.Add(time.Second)},
+		{ID: 3, CreatedAt: now.Add(2 * time.Second)},
+	})
+
+	page1, err := Paginate("", 2, true, keyOf, after, before)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{3, 2}, ids(page1.Items))
+	assert.Empty(t, page1.PrevCursor)
+
+	page2, err := Paginate(page1.NextCursor, 2, true, keyOf, after, before)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1}, ids(page2.Items))
+	assert.Empty(t, page2.NextCursor)
+
+	back1, err := Paginate(page2.PrevCursor, 2, true, keyOf, after, before)
+	assert.NoError(t, err)
+	assert.Equal(t, page1.Items, back1.Items)
+	assert.Empty(t, back1.PrevCursor)
+}
+
+func TestPaginate_Empty(t *testing.T) {
+	after, before := table(nil)
+
+	page, err := Paginate("", 5, false, keyOf, after, before)
+	assert.NoError(t, err)
+	assert.Empty(t, page.Items)
+	assert.Empty(t, page.NextCursor)
+	assert.Empty(t, page.PrevCursor)
+}
+
+func TestPaginate_InvalidCursor(t *testing.T) {
+	after, before := table(nil)
+
+	for _, value := range []string{"not base64!", encodeRaw("not json"), encodeRaw(`{"t":"2024-01-01T00:00:00Z"}`)} {
+		_, err := Paginate(value, 5, false, keyOf, after, before)
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+	}
+}
+
+func TestPaginate_FetchError(t *testing.T) {
+	fetchErr := errors.New("connection reset")
+	failing := func(key Key, limit int32) ([]row, error) { return nil, fetchErr }
+
+	_, err := Paginate("", 5, false, keyOf, failing, failing)
+	assert.ErrorIs(t, err, fetchErr)
+}
+
+func encodeRaw(value string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(value))
+}
@@ -0,0 +1,62 @@
+// Package queue runs background jobs stored in the jobs table. Jobs are enqueued with the
+// queries of a Store, so that a job enqueued on the store handed out by ExecTransaction is
+// only run if the rest of the transaction commits.
+package queue
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+)
+
+// DefaultMaxAttempts is the number of attempts of a job enqueued without MaxAttempts
+const DefaultMaxAttempts = 10
+
+// ErrDuplicateJob is returned when enqueueing a job whose unique key is held by a pending or running job of the same kind
+var ErrDuplicateJob = errors.New("a job with the same unique key is already queued")
+
+// Args are the arguments of a job, stored as JSON. Their kind selects the handler running the job.
+type Args interface {
+	Kind() string
+}
+
+// Options tune how a job is run, the zero value runs it once as soon as possible with up to DefaultMaxAttempts attempts
+type Options struct {
+	// Priority orders the due jobs, higher first
+	Priority int32
+	// RunAt delays the job until then
+	RunAt time.Time
+	// UniqueKey, when set, drops the job if another pending or running job of the same kind has the same key
+	UniqueKey   string
+	MaxAttempts int32
+}
+
+// Enqueue adds a job running args. Pass a Store bound to a transaction to enqueue it atomically with other changes.
+func Enqueue(ctx context.Context, q db.Querier, args Args, opts Options) (db.Job, error) {
+	payload, err := json.Marshal(args)
+	if err != nil {
+		return db.Job{}, fmt.Errorf("marshal %s job: %w", args.Kind(), err)
+	}
+	if opts.MaxAttempts == 0 {
+		opts.MaxAttempts = DefaultMaxAttempts
+	}
+
+	job, err := q.EnqueueJob(ctx, db.EnqueueJobParams{
+		Kind:        args.Kind(),
+		Payload:     payload,
+		Priority:    opts.Priority,
+		UniqueKey:   sql.NullString{String: opts.UniqueKey, Valid: opts.UniqueKey != ""},
+		MaxAttempts: opts.MaxAttempts,
+		RunAt:       sql.NullTime{Time: opts.RunAt, Valid: !opts.RunAt.IsZero()},
+	})
+	// the insert is skipped on a unique key conflict, so no row is returned
+	if errors.Is(err, sql.ErrNoRows) {
+		return db.Job{}, fmt.Errorf("%w: %s %q", ErrDuplicateJob, args.Kind(), opts.UniqueKey)
+	}
+	return job, err
+}
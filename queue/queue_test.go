@@ -0,0 +1,68 @@
+package queue
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	mockdb "github.com/arpangoswami/backend-golang-dev/database/mock"
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+type accrueInterest struct {
+	AccountID int64   `json:"account_id"`
+	Rate      float64 `json:"rate"`
+}
+
+func (accrueInterest) Kind() string { return "accrue_interest" }
+
+func TestEnqueue(t *testing.T) {
+	store := mockdb.NewMockStore(gomock.NewController(t))
+	runAt := time.Now().Add(time.Hour)
+
+	store.EXPECT().EnqueueJob(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, arg db.EnqueueJobParams) (db.Job, error) {
+			assert.Equal(t, "accrue_interest", arg.Kind)
+			assert.JSONEq(t, `{"account_id":7,"rate":0.02}`, string(arg.Payload))
+			assert.Equal(t, int32(5), arg.Priority)
+			assert.Equal(t, sql.NullString{String: "account-7", Valid: true}, arg.UniqueKey)
+			assert.Equal(t, int32(DefaultMaxAttempts), arg.MaxAttempts)
+			assert.Equal(t, sql.NullTime{Time: runAt, Valid: true}, arg.RunAt)
+			return db.Job{ID: 1, Kind: arg.Kind, Payload: arg.Payload}, nil
+		})
+
+	job, err := Enqueue(context.Background(), store, accrueInterest{AccountID: 7, Rate: 0.02},
+		Options{Priority: 5, RunAt: runAt, UniqueKey: "account-7"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), job.ID)
+}
+
+func TestEnqueue_Duplicate(t *testing.T) {
+	store := mockdb.NewMockStore(gomock.NewController(t))
+	store.EXPECT().EnqueueJob(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, arg db.EnqueueJobParams) (db.Job, error) {
+			assert.False(t, arg.RunAt.Valid)
+			return db.Job{}, sql.ErrNoRows
+		})
+
+	_, err := Enqueue(context.Background(), store, accrueInterest{AccountID: 7}, Options{UniqueKey: "account-7"})
+	assert.ErrorIs(t, err, ErrDuplicateJob)
+}
+
+func TestRegister_Twice(t *testing.T) {
+	registry := NewRegistry()
+	handle := func(context.Context, db.Job, accrueInterest) error { return nil }
+	Register(registry, handle)
+	assert.Panics(t, func() { Register(registry, handle) })
+}
+
+func randomJob(t *testing.T, args Args, attempts int32) db.Job {
+	t.Helper()
+	payload, err := json.Marshal(args)
+	assert.NoError(t, err)
+	return db.Job{ID: 3, Kind: args.Kind(), Payload: payload, Status: db.JobRunning, Attempts: attempts, MaxAttempts: 3}
+}
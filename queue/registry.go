@@ -0,0 +1,53 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+)
+
+// handler decodes the payload of a job and runs it
+type handler func(ctx context.Context, job db.Job) error
+
+// Registry maps the kind of a job to the handler running it
+type Registry struct {
+	handlers map[string]handler
+}
+
+// NewRegistry returns an empty registry
+func NewRegistry() *Registry {
+	return &Registry{handlers: make(map[string]handler)}
+}
+
+// Register makes the jobs of kind T.Kind() run with handle, which gets their decoded arguments.
+// Registering a kind twice panics, as it is a programming error.
+func Register[T Args](registry *Registry, handle func(ctx context.Context, job db.Job, args T) error) {
+	var zero T
+	kind := zero.Kind()
+	if _, ok := registry.handlers[kind]; ok {
+		panic(fmt.Sprintf("queue: handler of %s jobs registered twice", kind))
+	}
+	registry.handlers[kind] = func(ctx context.Context, job db.Job) error {
+		var args T
+		if err := json.Unmarshal(job.Payload, &args); err != nil {
+			return fmt.Errorf("decode %s job: %w", kind, err)
+		}
+		return handle(ctx, job, args)
+	}
+}
+
+// run runs the handler of the job's kind, turning a panic into an error
+func (registry *Registry) run(ctx context.Context, job db.Job) (err error) {
+	handle, ok := registry.handlers[job.Kind]
+	if !ok {
+		return fmt.Errorf("no handler registered for %s jobs", job.Kind)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%s job panicked: %v", job.Kind, r)
+		}
+	}()
+	return handle(ctx, job)
+}
@@ -0,0 +1,140 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+)
+
+// DefaultBatchSize is the number of jobs a worker claims, and runs concurrently, at once
+const DefaultBatchSize = 10
+
+// maxErrorLength bounds the error kept on a failed job
+const maxErrorLength = 512
+
+// Backoff returns the delay before retrying a job that failed the given number of attempts
+type Backoff func(attempts int32) time.Duration
+
+// DefaultBackoff waits attempts^4 seconds plus a second, i.e. 2s, 17s, 82s, ... capped at a day
+func DefaultBackoff(attempts int32) time.Duration {
+	seconds := int64(attempts) * int64(attempts) * int64(attempts) * int64(attempts)
+	return min(time.Duration(seconds+1)*time.Second, 24*time.Hour)
+}
+
+// Worker claims due jobs and runs them with the handlers of a registry
+type Worker struct {
+	store    db.Store
+	registry *Registry
+	// timeout is the visibility timeout: a job is claimed by another worker if this one
+	// hasn't finished it by then, and its handler's context is cancelled
+	timeout   time.Duration
+	interval  time.Duration
+	batchSize int32
+	backoff   Backoff
+	now       func() time.Time
+}
+
+// NewWorker returns a worker checking for due jobs every interval while there are none.
+// Every job must finish within timeout or it is run again.
+func NewWorker(store db.Store, registry *Registry, timeout, interval time.Duration) *Worker {
+	return &Worker{
+		store:     store,
+		registry:  registry,
+		timeout:   timeout,
+		interval:  interval,
+		batchSize: DefaultBatchSize,
+		backoff:   DefaultBackoff,
+		now:       time.Now,
+	}
+}
+
+// WorkOnce runs one batch of due jobs and returns how many were claimed. Job
+// failures are recorded on the jobs, only store errors are returned.
+func (worker *Worker) WorkOnce(ctx context.Context) (int, error) {
+	jobs, err := worker.store.ClaimJobs(ctx, db.ClaimJobsParams{
+		LockedUntil: worker.now().Add(worker.timeout),
+		LimitCount:  worker.batchSize,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(jobs))
+	for i, job := range jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = worker.work(ctx, job)
+		}()
+	}
+	wg.Wait()
+	return len(jobs), errors.Join(errs...)
+}
+
+// Run works on jobs until ctx is cancelled
+func (worker *Worker) Run(ctx context.Context) {
+	for {
+		claimed, err := worker.WorkOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("job worker: %v", err)
+		}
+		if err == nil && claimed == int(worker.batchSize) {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(worker.interval):
+		}
+	}
+}
+
+// work runs a claimed job and records its outcome. A job out of attempts fails
+// for good, otherwise it is retried after the backoff.
+func (worker *Worker) work(ctx context.Context, job db.Job) error {
+	runCtx, cancel := context.WithTimeout(ctx, worker.timeout)
+	runErr := worker.registry.run(runCtx, job)
+	cancel()
+
+	// the outcome is recorded even while shutting down, and the job's attempts tell
+	// whether it is still ours: once its lock expired it may have been claimed again
+	ctx = context.WithoutCancel(ctx)
+	if runErr == nil {
+		_, err := worker.store.CompleteJob(ctx, db.CompleteJobParams{ID: job.ID, Attempts: job.Attempts})
+		return err
+	}
+
+	status := db.JobPending
+	if job.Attempts >= job.MaxAttempts {
+		status = db.JobFailed
+	}
+	message := truncateError(runErr.Error())
+	_, err := worker.store.FailJob(ctx, db.FailJobParams{
+		ID:        job.ID,
+		Attempts:  job.Attempts,
+		Status:    status,
+		RunAt:     worker.now().Add(worker.backoff(job.Attempts)),
+		LastError: message,
+	})
+	return err
+}
+
+// truncateError cuts message to maxErrorLength bytes on a character boundary, as postgres
+// refuses text holding half of a multi-byte UTF-8 character
+func truncateError(message string) string {
+	if len(message) <= maxErrorLength {
+		return message
+	}
+	end := maxErrorLength
+	for end > 0 && !utf8.RuneStart(message[end]) {
+		end--
+	}
+	return message[:end]
+}
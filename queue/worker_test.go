@@ -0,0 +1,130 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	mockdb "github.com/arpangoswami/backend-golang-dev/database/mock"
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestWorker_WorkOnce(t *testing.T) {
+	store := mockdb.NewMockStore(gomock.NewController(t))
+	job := randomJob(t, accrueInterest{AccountID: 7, Rate: 0.02}, 1)
+
+	var got accrueInterest
+	registry := NewRegistry()
+	Register(registry, func(ctx context.Context, job db.Job, args accrueInterest) error {
+		_, ok := ctx.Deadline()
+		assert.True(t, ok, "handlers run within the visibility timeout")
+		got = args
+		return nil
+	})
+
+	worker := NewWorker(store, registry, time.Minute, time.Second)
+	now := time.Now()
+	worker.now = func() time.Time { return now }
+
+	store.EXPECT().ClaimJobs(gomock.Any(), gomock.Eq(db.ClaimJobsParams{
+		LockedUntil: now.Add(time.Minute),
+		LimitCount:  DefaultBatchSize,
+	})).Times(1).Return([]db.Job{job}, nil)
+	store.EXPECT().CompleteJob(gomock.Any(), gomock.Eq(db.CompleteJobParams{ID: job.ID, Attempts: 1})).Times(1).Return(int64(1), nil)
+
+	claimed, err := worker.WorkOnce(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, claimed)
+	assert.Equal(t, accrueInterest{AccountID: 7, Rate: 0.02}, got)
+}
+
+func TestWorker_WorkOnceFailure(t *testing.T) {
+	testCases := []struct {
+		name     string
+		handle   func(context.Context, db.Job, accrueInterest) error
+		kind     string
+		attempts int32
+		status   string
+		message  string
+	}{
+		{
+			name:     "Retried",
+			handle:   func(context.Context, db.Job, accrueInterest) error { return errors.New("ledger locked") },
+			attempts: 1,
+			status:   db.JobPending,
+			message:  "ledger locked",
+		},
+		{
+			name:     "FailedAfterMaxAttempts",
+			handle:   func(context.Context, db.Job, accrueInterest) error { return errors.New("ledger locked") },
+			attempts: 3,
+			status:   db.JobFailed,
+			message:  "ledger locked",
+		},
+		{
+			name:     "Panic",
+			handle:   func(context.Context, db.Job, accrueInterest) error { panic("nil account") },
+			attempts: 1,
+			status:   db.JobPending,
+			message:  "accrue_interest job panicked: nil account",
+		},
+		{
+			name:     "UnknownKind",
+			handle:   func(context.Context, db.Job, accrueInterest) error { return nil },
+			kind:     "send_statement",
+			attempts: 2,
+			status:   db.JobPending,
+			message:  "no handler registered for send_statement jobs",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := mockdb.NewMockStore(gomock.NewController(t))
+			registry := NewRegistry()
+			Register(registry, tc.handle)
+			worker := NewWorker(store, registry, time.Minute, time.Second)
+			now := time.Now()
+			worker.now = func() time.Time { return now }
+
+			job := randomJob(t, accrueInterest{AccountID: 7}, tc.attempts)
+			if tc.kind != "" {
+				job.Kind = tc.kind
+			}
+			store.EXPECT().ClaimJobs(gomock.Any(), gomock.Any()).Times(1).Return([]db.Job{job}, nil)
+			store.EXPECT().FailJob(gomock.Any(), gomock.Eq(db.FailJobParams{
+				ID:        job.ID,
+				Attempts:  tc.attempts,
+				Status:    tc.status,
+				RunAt:     now.Add(DefaultBackoff(tc.attempts)),
+				LastError: tc.message,
+			})).Times(1).Return(int64(1), nil)
+
+			_, err := worker.WorkOnce(context.Background())
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestDefaultBackoff(t *testing.T) {
+	assert.Equal(t, 2*time.Second, DefaultBackoff(1))
+	assert.Equal(t, 17*time.Second, DefaultBackoff(2))
+	assert.Equal(t, 24*time.Hour, DefaultBackoff(100))
+}
+
+func TestTruncateError(t *testing.T) {
+	assert.Equal(t, "boom", truncateError("boom"))
+
+	// a 3 bytes character straddling the limit is dropped whole
+	message := strings.Repeat("a", maxErrorLength-1) + "€€"
+	truncated := truncateError(message)
+	assert.True(t, utf8.ValidString(truncated))
+	assert.Equal(t, strings.Repeat("a", maxErrorLength-1), truncated)
+
+	assert.Len(t, truncateError(strings.Repeat("é", maxErrorLength)), maxErrorLength)
+}
@@ -0,0 +1,296 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+	"github.com/arpangoswami/backend-golang-dev/pagination"
+	"github.com/arpangoswami/backend-golang-dev/token"
+	"github.com/arpangoswami/backend-golang-dev/util"
+)
+
+// Bank wraps the Store with owner-scoped authorization. Every method reads the
+// authenticated user from the context and only lets it touch accounts it owns.
+type Bank struct {
+	store db.Store
+}
+
+// NewBank returns a Bank authorizing calls before they reach the store
+func NewBank(store db.Store) *Bank {
+	return &Bank{store: store}
+}
+
+// caller returns the username of the authenticated user stored in ctx
+func caller(ctx context.Context) (string, error) {
+	payload, ok := token.FromContext(ctx)
+	if !ok {
+		return "", ErrUnauthenticated
+	}
+	return payload.Username, nil
+}
+
+// ownedAccount loads the account and checks it belongs to the caller
+func (bank *Bank) ownedAccount(ctx context.Context, username string, accountID int64) (db.Account, error) {
+	account, err := bank.store.GetAccount(ctx, accountID)
+	if err != nil {
+		return db.Account{}, err
+	}
+	if account.Owner != username {
+		return db.Account{}, fmt.Errorf("%w: account [%d] doesn't belong to %s", ErrForbidden, accountID, username)
+	}
+	return account, nil
+}
+
+// CreateAccount opens an account in the given currency owned by the caller
+func (bank *Bank) CreateAccount(ctx context.Context, currency string) (db.Account, error) {
+	username, err := caller(ctx)
+	if err != nil {
+		return db.Account{}, err
+	}
+	return bank.store.CreateAccount(ctx, db.CreateAccountParams{
+		Owner:       username,
+		Balance:     0,
+		Currency:    currency,
+		CountryCode: util.CountryCodeForCurrency(currency),
+	})
+}
+
+// GetAccount returns an account owned by the caller
+func (bank *Bank) GetAccount(ctx context.Context, id int64) (db.Account, error) {
+	username, err := caller(ctx)
+	if err != nil {
+		return db.Account{}, err
+	}
+	return bank.ownedAccount(ctx, username, id)
+}
+
+// ListAccounts returns a page of the caller's accounts, oldest first
+func (bank *Bank) ListAccounts(ctx context.Context, cursor string, pageSize int32) (pagination.Page[db.Account], error) {
+	username, err := caller(ctx)
+	if err != nil {
+		return pagination.Page[db.Account]{}, err
+	}
+	return pagination.Paginate(cursor, pageSize, false, accountKey,
+		func(key pagination.Key, limit int32) ([]db.Account, error) {
+			return bank.store.ListAccountsByOwnerAfter(ctx, db.ListAccountsByOwnerAfterParams{
+				Owner:          username,
+				AfterCreatedAt: key.CreatedAt,
+				AfterID:        key.ID,
+				LimitCount:     limit,
+			})
+		},
+		func(key pagination.Key, limit int32) ([]db.Account, error) {
+			return bank.store.ListAccountsByOwnerBefore(ctx, db.ListAccountsByOwnerBeforeParams{
+				Owner:           username,
+				BeforeCreatedAt: key.CreatedAt,
+				BeforeID:        key.ID,
+				LimitCount:      limit,
+			})
+		},
+	)
+}
+
+// GetEntry returns an entry booked on one of the caller's accounts
+func (bank *Bank) GetEntry(ctx context.Context, id int64) (db.Entry, error) {
+	username, err := caller(ctx)
+	if err != nil {
+		return db.Entry{}, err
+	}
+	entry, err := bank.store.GetEntry(ctx, id)
+	if err != nil {
+		return db.Entry{}, err
+	}
+	if _, err := bank.ownedAccount(ctx, username, entry.AccountID); err != nil {
+		return db.Entry{}, err
+	}
+	return entry, nil
+}
+
+// ListEntriesParams selects a page of the entries of some accounts
+type ListEntriesParams struct {
+	AccountIDs []int64
+	Filter     ListFilter
+	Cursor     string
+	PageSize   int32
+}
+
+// ListEntries returns a page of entries of the given accounts, all of which must belong to the caller
+func (bank *Bank) ListEntries(ctx context.Context, arg ListEntriesParams) (pagination.Page[db.Entry], error) {
+	username, err := caller(ctx)
+	if err != nil {
+		return pagination.Page[db.Entry]{}, err
+	}
+	for _, accountID := range arg.AccountIDs {
+		if _, err := bank.ownedAccount(ctx, username, accountID); err != nil {
+			return pagination.Page[db.Entry]{}, err
+		}
+	}
+	return pagination.Paginate(arg.Cursor, arg.PageSize, arg.Filter.Descending, entryKey,
+		func(key pagination.Key, limit int32) ([]db.Entry, error) {
+			params := db.ListEntriesAfterParams{
+				AccountIds:     arg.AccountIDs,
+				Direction:      string(arg.Filter.Direction),
+				StartTime:      nullTime(arg.Filter.StartTime),
+				EndTime:        nullTime(arg.Filter.EndTime),
+				MinAmount:      nullAmount(arg.Filter.MinAmount),
+				MaxAmount:      nullAmount(arg.Filter.MaxAmount),
+				AfterCreatedAt: key.CreatedAt,
+				AfterID:        key.ID,
+				LimitCount:     limit,
+			}
+			if arg.Filter.IncludeArchived {
+				return bank.store.ListEntriesWithArchiveAfter(ctx, db.ListEntriesWithArchiveAfterParams(params))
+			}
+			return bank.store.ListEntriesAfter(ctx, params)
+		},
+		func(key pagination.Key, limit int32) ([]db.Entry, error) {
+			params := db.ListEntriesBeforeParams{
+				AccountIds:      arg.AccountIDs,
+				Direction:       string(arg.Filter.Direction),
+				StartTime:       nullTime(arg.Filter.StartTime),
+				EndTime:         nullTime(arg.Filter.EndTime),
+				MinAmount:       nullAmount(arg.Filter.MinAmount),
+				MaxAmount:       nullAmount(arg.Filter.MaxAmount),
+				BeforeCreatedAt: key.CreatedAt,
+				BeforeID:        key.ID,
+				LimitCount:      limit,
+			}
+			if arg.Filter.IncludeArchived {
+				return bank.store.ListEntriesWithArchiveBefore(ctx, db.ListEntriesWithArchiveBeforeParams(params))
+			}
+			return bank.store.ListEntriesBefore(ctx, params)
+		},
+	)
+}
+
+// GetTransfer returns a transfer sent from or received by one of the caller's accounts. A transfer
+// moved to the archive is only returned with includeArchived, otherwise db.ErrTransferArchived is.
+func (bank *Bank) GetTransfer(ctx context.Context, id int64, includeArchived bool) (db.Transfer, error) {
+	username, err := caller(ctx)
+	if err != nil {
+		return db.Transfer{}, err
+	}
+	transfer, err := bank.store.GetTransfer(ctx, id)
+	archived := false
+	if errors.Is(err, sql.ErrNoRows) {
+		var archivedTransfer db.TransfersArchive
+		if archivedTransfer, err = bank.store.GetArchivedTransfer(ctx, id); err == nil {
+			transfer, archived = db.Transfer(archivedTransfer), true
+		}
+	}
+	if err != nil {
+		return db.Transfer{}, err
+	}
+	for _, accountID := range []int64{transfer.FromAccountID, transfer.ToAccountID} {
+		account, err := bank.store.GetAccount(ctx, accountID)
+		if err != nil {
+			return db.Transfer{}, err
+		}
+		if account.Owner != username {
+			continue
+		}
+		if archived && !includeArchived {
+			return db.Transfer{}, fmt.Errorf("%w: transfer [%d], ask to include archived transfers", db.ErrTransferArchived, id)
+		}
+		return transfer, nil
+	}
+	return db.Transfer{}, fmt.Errorf("%w: transfer [%d] doesn't involve an account of %s", ErrForbidden, id, username)
+}
+
+// ListTransfersParams selects a page of the transfers sent from or received by an account
+type ListTransfersParams struct {
+	AccountID int64
+	Filter    ListFilter
+	Cursor    string
+	PageSize  int32
+}
+
+// ListTransfers returns a page of transfers involving an account owned by the caller.
+// The filter's direction keeps only the transfers the account received (in) or sent (out).
+func (bank *Bank) ListTransfers(ctx context.Context, arg ListTransfersParams) (pagination.Page[db.Transfer], error) {
+	username, err := caller(ctx)
+	if err != nil {
+		return pagination.Page[db.Transfer]{}, err
+	}
+	if _, err := bank.ownedAccount(ctx, username, arg.AccountID); err != nil {
+		return pagination.Page[db.Transfer]{}, err
+	}
+	return pagination.Paginate(arg.Cursor, arg.PageSize, arg.Filter.Descending, transferKey,
+		func(key pagination.Key, limit int32) ([]db.Transfer, error) {
+			params := db.ListTransfersAfterParams{
+				AccountID:      arg.AccountID,
+				Direction:      string(arg.Filter.Direction),
+				StartTime:      nullTime(arg.Filter.StartTime),
+				EndTime:        nullTime(arg.Filter.EndTime),
+				MinAmount:      nullAmount(arg.Filter.MinAmount),
+				MaxAmount:      nullAmount(arg.Filter.MaxAmount),
+				AfterCreatedAt: key.CreatedAt,
+				AfterID:        key.ID,
+				LimitCount:     limit,
+			}
+			if arg.Filter.IncludeArchived {
+				return bank.store.ListTransfersWithArchiveAfter(ctx, db.ListTransfersWithArchiveAfterParams(params))
+			}
+			return bank.store.ListTransfersAfter(ctx, params)
+		},
+		func(key pagination.Key, limit int32) ([]db.Transfer, error) {
+			params := db.ListTransfersBeforeParams{
+				AccountID:       arg.AccountID,
+				Direction:       string(arg.Filter.Direction),
+				StartTime:       nullTime(arg.Filter.StartTime),
+				EndTime:         nullTime(arg.Filter.EndTime),
+				MinAmount:       nullAmount(arg.Filter.MinAmount),
+				MaxAmount:       nullAmount(arg.Filter.MaxAmount),
+				BeforeCreatedAt: key.CreatedAt,
+				BeforeID:        key.ID,
+				LimitCount:      limit,
+			}
+			if arg.Filter.IncludeArchived {
+				return bank.store.ListTransfersWithArchiveBefore(ctx, db.ListTransfersWithArchiveBeforeParams(params))
+			}
+			return bank.store.ListTransfersBefore(ctx, params)
+		},
+	)
+}
+
+// TransferParams contains the input of a transfer requested by a user
+type TransferParams struct {
+	FromAccountID int64
+	ToAccountID   int64
+	Amount        float64
+	Currency      string
+}
+
+// Transfer moves money from an account owned by the caller to any account in the same currency
+func (bank *Bank) Transfer(ctx context.Context, arg TransferParams) (db.TransferTransactionResult, error) {
+	username, err := caller(ctx)
+	if err != nil {
+		return db.TransferTransactionResult{}, err
+	}
+	// the accounts may have just been opened, a replica may not know them yet
+	ctx = db.WithPrimary(ctx)
+
+	fromAccount, err := bank.ownedAccount(ctx, username, arg.FromAccountID)
+	if err != nil {
+		return db.TransferTransactionResult{}, err
+	}
+	toAccount, err := bank.store.GetAccount(ctx, arg.ToAccountID)
+	if err != nil {
+		return db.TransferTransactionResult{}, err
+	}
+	for _, account := range []db.Account{fromAccount, toAccount} {
+		if account.Currency != arg.Currency {
+			return db.TransferTransactionResult{}, fmt.Errorf("%w: account [%d] holds %s, transfer is in %s",
+				ErrCurrencyMismatch, account.ID, account.Currency, arg.Currency)
+		}
+	}
+
+	return bank.store.TransferTransaction(ctx, db.TransferTransactionParams{
+		FromAccountID: arg.FromAccountID,
+		ToAccountID:   arg.ToAccountID,
+		Amount:        arg.Amount,
+	})
+}
@@ -0,0 +1,290 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	mockdb "github.com/arpangoswami/backend-golang-dev/database/mock"
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+	"github.com/arpangoswami/backend-golang-dev/pagination"
+	"github.com/arpangoswami/backend-golang-dev/token"
+	"github.com/arpangoswami/backend-golang-dev/util"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func contextAs(t *testing.T, username string) context.Context {
+	t.Helper()
+	payload, err := token.NewPayload(username, token.TokenTypeAccess, time.Minute)
+	assert.NoError(t, err)
+	return token.NewContext(context.Background(), payload)
+}
+
+func randomAccount(owner string, currency string) db.Account {
+	return db.Account{
+		ID:       util.RandomInt(1, 1000),
+		Owner:    owner,
+		Balance:  util.RandomMoney(1000),
+		Currency: currency,
+	}
+}
+
+func TestBank_Unauthenticated(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	bank := NewBank(store)
+
+	_, err := bank.GetAccount(context.Background(), 1)
+	assert.ErrorIs(t, err, ErrUnauthenticated)
+	_, err = bank.ListAccounts(context.Background(), "", 5)
+	assert.ErrorIs(t, err, ErrUnauthenticated)
+	_, err = bank.Transfer(context.Background(), TransferParams{FromAccountID: 1, ToAccountID: 2, Amount: 1})
+	assert.ErrorIs(t, err, ErrUnauthenticated)
+}
+
+func TestBank_CreateAccount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	username := util.RandomOwner()
+
+	arg := db.CreateAccountParams{
+		Owner:       username,
+		Currency:    util.EUR,
+		CountryCode: util.CountryCodeForCurrency(util.EUR),
+	}
+	store.EXPECT().CreateAccount(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.Account{Owner: username}, nil)
+
+	account, err := NewBank(store).CreateAccount(contextAs(t, username), util.EUR)
+	assert.NoError(t, err)
+	assert.Equal(t, username, account.Owner)
+}
+
+func TestBank_GetAccount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	account := randomAccount(util.RandomOwner(), util.USD)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(2).Return(account, nil)
+	bank := NewBank(store)
+
+	got, err := bank.GetAccount(contextAs(t, account.Owner), account.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, account, got)
+
+	_, err = bank.GetAccount(contextAs(t, "someoneelse"), account.ID)
+	assert.ErrorIs(t, err, ErrForbidden)
+}
+
+func TestBank_ListAccounts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	username := util.RandomOwner()
+
+	accounts := make([]db.Account, 6)
+	for i := range accounts {
+		accounts[i] = randomAccount(username, util.USD)
+		accounts[i].ID = int64(i + 1)
+		accounts[i].CreatedAt = time.Now().UTC().Truncate(time.Microsecond).Add(time.Duration(i) * time.Second)
+	}
+
+	// the first page asks for one extra row to know whether there is a next page
+	arg := db.ListAccountsByOwnerAfterParams{Owner: username, LimitCount: 6}
+	store.EXPECT().ListAccountsByOwnerAfter(gomock.Any(), gomock.Eq(arg)).Times(1).Return(accounts, nil)
+
+	bank := NewBank(store)
+	page, err := bank.ListAccounts(contextAs(t, username), "", 5)
+	assert.NoError(t, err)
+	assert.Len(t, page.Items, 5)
+	assert.Empty(t, page.PrevCursor)
+	assert.NotEmpty(t, page.NextCursor)
+
+	arg = db.ListAccountsByOwnerAfterParams{
+		Owner:          username,
+		AfterCreatedAt: accounts[4].CreatedAt,
+		AfterID:        accounts[4].ID,
+		LimitCount:     6,
+	}
+	store.EXPECT().ListAccountsByOwnerAfter(gomock.Any(), gomock.Eq(arg)).Times(1).Return(accounts[5:], nil)
+
+	page, err = bank.ListAccounts(contextAs(t, username), page.NextCursor, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, accounts[5:], page.Items)
+	assert.Empty(t, page.NextCursor)
+	assert.NotEmpty(t, page.PrevCursor)
+
+	_, err = bank.ListAccounts(contextAs(t, username), "garbage", 5)
+	assert.ErrorIs(t, err, pagination.ErrInvalidCursor)
+}
+
+func TestBank_ListEntries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	owned := randomAccount(util.RandomOwner(), util.USD)
+	foreign := randomAccount(util.RandomOwner(), util.USD)
+	foreign.ID = owned.ID + 1
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(owned.ID)).AnyTimes().Return(owned, nil)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(foreign.ID)).AnyTimes().Return(foreign, nil)
+	bank := NewBank(store)
+	ctx := contextAs(t, owned.Owner)
+
+	arg := db.ListEntriesAfterParams{AccountIds: []int64{owned.ID}, LimitCount: 6}
+	store.EXPECT().ListEntriesAfter(gomock.Any(), gomock.Eq(arg)).Times(1).Return([]db.Entry{}, nil)
+	_, err := bank.ListEntries(ctx, ListEntriesParams{AccountIDs: []int64{owned.ID}, PageSize: 5})
+	assert.NoError(t, err)
+
+	// the archive is read only when asked
+	archived := db.ListEntriesWithArchiveAfterParams(arg)
+	store.EXPECT().ListEntriesWithArchiveAfter(gomock.Any(), gomock.Eq(archived)).Times(1).Return([]db.Entry{}, nil)
+	_, err = bank.ListEntries(ctx, ListEntriesParams{
+		AccountIDs: []int64{owned.ID},
+		Filter:     ListFilter{IncludeArchived: true},
+		PageSize:   5,
+	})
+	assert.NoError(t, err)
+
+	_, err = bank.ListEntries(ctx, ListEntriesParams{AccountIDs: []int64{owned.ID, foreign.ID}, PageSize: 5})
+	assert.ErrorIs(t, err, ErrForbidden)
+}
+
+func TestBank_ListTransfers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	owned := randomAccount(util.RandomOwner(), util.USD)
+	foreign := randomAccount(util.RandomOwner(), util.USD)
+	foreign.ID = owned.ID + 1
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(owned.ID)).AnyTimes().Return(owned, nil)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(foreign.ID)).AnyTimes().Return(foreign, nil)
+	bank := NewBank(store)
+	ctx := contextAs(t, owned.Owner)
+
+	start := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.FixedZone("CET", 3600))
+	filter := ListFilter{
+		Direction:  DirectionIn,
+		StartTime:  start,
+		MinAmount:  10,
+		Descending: true,
+	}
+
+	// a descending listing starts from the newest rows, filter times are passed on in UTC and unset bounds as NULL
+	store.EXPECT().ListTransfersBefore(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, arg db.ListTransfersBeforeParams) ([]db.Transfer, error) {
+			assert.Equal(t, owned.ID, arg.AccountID)
+			assert.Equal(t, "in", arg.Direction)
+			assert.True(t, arg.StartTime.Valid)
+			assert.Equal(t, time.UTC, arg.StartTime.Time.Location())
+			assert.True(t, arg.StartTime.Time.Equal(start))
+			assert.False(t, arg.EndTime.Valid)
+			assert.Equal(t, float64(10), arg.MinAmount.Float64)
+			assert.False(t, arg.MaxAmount.Valid)
+			assert.Greater(t, arg.BeforeID, int64(0))
+			assert.Equal(t, int32(6), arg.LimitCount)
+			return []db.Transfer{}, nil
+		})
+	_, err := bank.ListTransfers(ctx, ListTransfersParams{AccountID: owned.ID, Filter: filter, PageSize: 5})
+	assert.NoError(t, err)
+
+	_, err = bank.ListTransfers(ctx, ListTransfersParams{AccountID: foreign.ID, PageSize: 5})
+	assert.ErrorIs(t, err, ErrForbidden)
+}
+
+func TestBank_GetTransfer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	from := randomAccount(util.RandomOwner(), util.USD)
+	to := randomAccount(util.RandomOwner(), util.USD)
+	to.ID = from.ID + 1
+	transfer := db.Transfer{ID: 1, FromAccountID: from.ID, ToAccountID: to.ID, Amount: 10}
+	store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).AnyTimes().Return(transfer, nil)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(from.ID)).AnyTimes().Return(from, nil)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(to.ID)).AnyTimes().Return(to, nil)
+	bank := NewBank(store)
+
+	_, err := bank.GetTransfer(contextAs(t, from.Owner), transfer.ID, false)
+	assert.NoError(t, err)
+	_, err = bank.GetTransfer(contextAs(t, to.Owner), transfer.ID, false)
+	assert.NoError(t, err)
+	_, err = bank.GetTransfer(contextAs(t, "stranger"), transfer.ID, false)
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	// an archived transfer is told apart from a missing one, and read when asked
+	archived := db.TransfersArchive{ID: 2, FromAccountID: from.ID, ToAccountID: to.ID, Amount: 5}
+	store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(archived.ID)).AnyTimes().Return(db.Transfer{}, sql.ErrNoRows)
+	store.EXPECT().GetArchivedTransfer(gomock.Any(), gomock.Eq(archived.ID)).AnyTimes().Return(archived, nil)
+	_, err = bank.GetTransfer(contextAs(t, from.Owner), archived.ID, false)
+	assert.ErrorIs(t, err, db.ErrTransferArchived)
+	got, err := bank.GetTransfer(contextAs(t, from.Owner), archived.ID, true)
+	assert.NoError(t, err)
+	assert.Equal(t, db.Transfer(archived), got)
+	_, err = bank.GetTransfer(contextAs(t, "stranger"), archived.ID, true)
+	assert.ErrorIs(t, err, ErrForbidden)
+}
+
+func TestBank_Transfer(t *testing.T) {
+	from := randomAccount(util.RandomOwner(), util.USD)
+	to := randomAccount(util.RandomOwner(), util.USD)
+	other := randomAccount(util.RandomOwner(), util.INR)
+	to.ID = from.ID + 1
+	other.ID = from.ID + 2
+
+	testCases := []struct {
+		name       string
+		username   string
+		arg        TransferParams
+		buildStubs func(store *mockdb.MockStore)
+		checkError func(t *testing.T, err error)
+	}{
+		{
+			name:     "OK",
+			username: from.Owner,
+			arg:      TransferParams{FromAccountID: from.ID, ToAccountID: to.ID, Amount: 10, Currency: util.USD},
+			buildStubs: func(store *mockdb.MockStore) {
+				// the owner and currency checks must see accounts opened just before
+				onPrimary := gomock.Cond(db.OnPrimary)
+				store.EXPECT().GetAccount(onPrimary, gomock.Eq(from.ID)).Times(1).Return(from, nil)
+				store.EXPECT().GetAccount(onPrimary, gomock.Eq(to.ID)).Times(1).Return(to, nil)
+				arg := db.TransferTransactionParams{FromAccountID: from.ID, ToAccountID: to.ID, Amount: 10}
+				store.EXPECT().TransferTransaction(gomock.Any(), gomock.Eq(arg)).Times(1)
+			},
+			checkError: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name:     "DebitForeignAccount",
+			username: to.Owner,
+			arg:      TransferParams{FromAccountID: from.ID, ToAccountID: to.ID, Amount: 10, Currency: util.USD},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(from.ID)).Times(1).Return(from, nil)
+				store.EXPECT().TransferTransaction(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkError: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, ErrForbidden)
+			},
+		},
+		{
+			name:     "CurrencyMismatch",
+			username: from.Owner,
+			arg:      TransferParams{FromAccountID: from.ID, ToAccountID: other.ID, Amount: 10, Currency: util.USD},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(from.ID)).Times(1).Return(from, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(other.ID)).Times(1).Return(other, nil)
+				store.EXPECT().TransferTransaction(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkError: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, ErrCurrencyMismatch)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			_, err := NewBank(store).Transfer(contextAs(t, tc.username), tc.arg)
+			tc.checkError(t, err)
+		})
+	}
+}
@@ -0,0 +1,10 @@
+package service
+
+import "errors"
+
+// Errors returned when the caller is not allowed to perform an operation
+var (
+	ErrUnauthenticated  = errors.New("caller is not authenticated")
+	ErrForbidden        = errors.New("caller is not allowed to access the resource")
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+)
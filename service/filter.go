@@ -0,0 +1,48 @@
+package service
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Direction narrows a listing down to the money coming into or going out of the accounts
+type Direction string
+
+const (
+	// DirectionAny keeps both incoming and outgoing money
+	DirectionAny Direction = ""
+	// DirectionIn keeps transfers received by the account and entries crediting it
+	DirectionIn Direction = "in"
+	// DirectionOut keeps transfers sent from the account and entries debiting it
+	DirectionOut Direction = "out"
+)
+
+// ListFilter narrows a listing down. Zero values leave the corresponding bound open.
+type ListFilter struct {
+	Direction Direction
+	// StartTime is inclusive and EndTime exclusive
+	StartTime time.Time
+	EndTime   time.Time
+	// MinAmount and MaxAmount are inclusive and compare with the absolute amount of entries
+	MinAmount float64
+	MaxAmount float64
+	// Descending lists the newest rows first
+	Descending bool
+	// IncludeArchived also reads the rows moved to the archive tables, which are slower to list
+	IncludeArchived bool
+}
+
+// created_at is a timestamp without time zone holding UTC
+func nullTime(t time.Time) sql.NullTime {
+	if t.IsZero() {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: t.UTC(), Valid: true}
+}
+
+func nullAmount(amount float64) sql.NullFloat64 {
+	if amount == 0 {
+		return sql.NullFloat64{}
+	}
+	return sql.NullFloat64{Float64: amount, Valid: true}
+}
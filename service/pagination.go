@@ -0,0 +1,18 @@
+package service
+
+import (
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+	"github.com/arpangoswami/backend-golang-dev/pagination"
+)
+
+func accountKey(account db.Account) pagination.Key {
+	return pagination.Key{CreatedAt: account.CreatedAt, ID: account.ID}
+}
+
+func entryKey(entry db.Entry) pagination.Key {
+	return pagination.Key{CreatedAt: entry.CreatedAt, ID: entry.ID}
+}
+
+func transferKey(transfer db.Transfer) pagination.Key {
+	return pagination.Key{CreatedAt: transfer.CreatedAt, ID: transfer.ID}
+}
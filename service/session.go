@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+	"github.com/arpangoswami/backend-golang-dev/token"
+	"github.com/google/uuid"
+)
+
+// ListSessions returns a page of the caller's sessions, newest first
+func (bank *Bank) ListSessions(ctx context.Context, limit, offset int32) ([]db.Session, error) {
+	username, err := caller(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return bank.store.ListSessions(ctx, db.ListSessionsParams{
+		Username: username,
+		Limit:    limit,
+		Offset:   offset,
+	})
+}
+
+// RevokeSession revokes one of the caller's active sessions. Sessions of other users
+// are reported as missing so their IDs can't be probed
+func (bank *Bank) RevokeSession(ctx context.Context, id uuid.UUID) (db.Session, error) {
+	username, err := caller(ctx)
+	if err != nil {
+		return db.Session{}, err
+	}
+	return bank.store.RevokeSession(ctx, db.RevokeSessionParams{
+		ID:       id,
+		Username: username,
+	})
+}
+
+// RefreshSession returns the session of a refresh token, once it made sure that the token is a valid
+// refresh token and that its session can still renew an access token. Every reason to refuse the
+// token is an ErrUnauthenticated.
+func (bank *Bank) RefreshSession(ctx context.Context, tokenMaker token.Maker, refreshToken string) (db.Session, error) {
+	payload, err := tokenMaker.VerifyToken(refreshToken)
+	if err == nil && payload.TokenType != token.TokenTypeRefresh {
+		err = token.ErrWrongTokenType
+	}
+	if err != nil {
+		return db.Session{}, fmt.Errorf("%w: %w", ErrUnauthenticated, err)
+	}
+
+	session, err := bank.store.GetSession(ctx, payload.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return db.Session{}, fmt.Errorf("%w: session not found", ErrUnauthenticated)
+	}
+	if err != nil {
+		return db.Session{}, err
+	}
+	if err := checkSession(session, payload.Username, refreshToken); err != nil {
+		return db.Session{}, fmt.Errorf("%w: %w", ErrUnauthenticated, err)
+	}
+	return session, nil
+}
+
+// checkSession makes sure the session can still be used to renew an access token
+func checkSession(session db.Session, username string, refreshToken string) error {
+	switch {
+	case session.IsRevoked:
+		return errors.New("session is revoked")
+	case session.Username != username:
+		return errors.New("incorrect session user")
+	case session.RefreshToken != refreshToken:
+		return errors.New("mismatched session token")
+	case time.Now().After(session.ExpiresAt):
+		return errors.New("session has expired")
+	}
+	return nil
+}
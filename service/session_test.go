@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	mockdb "github.com/arpangoswami/backend-golang-dev/database/mock"
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+	"github.com/arpangoswami/backend-golang-dev/token"
+	"github.com/arpangoswami/backend-golang-dev/util"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestBank_ListSessions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	username := util.RandomOwner()
+
+	arg := db.ListSessionsParams{Username: username, Limit: 5, Offset: 0}
+	store.EXPECT().ListSessions(gomock.Any(), gomock.Eq(arg)).Times(1).Return([]db.Session{}, nil)
+
+	_, err := NewBank(store).ListSessions(contextAs(t, username), 5, 0)
+	assert.NoError(t, err)
+
+	_, err = NewBank(store).ListSessions(context.Background(), 5, 0)
+	assert.ErrorIs(t, err, ErrUnauthenticated)
+}
+
+func TestBank_RevokeSession(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	username := util.RandomOwner()
+	sessionID := uuid.New()
+
+	arg := db.RevokeSessionParams{ID: sessionID, Username: username}
+	store.EXPECT().RevokeSession(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.Session{}, sql.ErrNoRows)
+
+	_, err := NewBank(store).RevokeSession(contextAs(t, username), sessionID)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestBank_RefreshSession(t *testing.T) {
+	username := util.RandomOwner()
+
+	testCases := []struct {
+		name         string
+		tokenType    token.TokenType
+		duration     time.Duration
+		buildSession func(session *db.Session)
+		getErr       error
+		checkError   func(t *testing.T, err error)
+	}{
+		{
+			name:      "OK",
+			tokenType: token.TokenTypeRefresh,
+			duration:  time.Hour,
+			checkError: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name:      "AccessToken",
+			tokenType: token.TokenTypeAccess,
+			duration:  time.Hour,
+			checkError: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, ErrUnauthenticated)
+				assert.ErrorIs(t, err, token.ErrWrongTokenType)
+			},
+		},
+		{
+			name:      "ExpiredToken",
+			tokenType: token.TokenTypeRefresh,
+			duration:  -time.Minute,
+			checkError: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, ErrUnauthenticated)
+			},
+		},
+		{
+			name:      "SessionNotFound",
+			tokenType: token.TokenTypeRefresh,
+			duration:  time.Hour,
+			getErr:    sql.ErrNoRows,
+			checkError: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, ErrUnauthenticated)
+			},
+		},
+		{
+			name:      "RevokedSession",
+			tokenType: token.TokenTypeRefresh,
+			duration:  time.Hour,
+			buildSession: func(session *db.Session) {
+				session.IsRevoked = true
+			},
+			checkError: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, ErrUnauthenticated)
+				assert.ErrorContains(t, err, "session is revoked")
+			},
+		},
+		{
+			name:      "MismatchedToken",
+			tokenType: token.TokenTypeRefresh,
+			duration:  time.Hour,
+			buildSession: func(session *db.Session) {
+				session.RefreshToken = "another-token"
+			},
+			checkError: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, ErrUnauthenticated)
+			},
+		},
+		{
+			name:      "StoreError",
+			tokenType: token.TokenTypeRefresh,
+			duration:  time.Hour,
+			getErr:    sql.ErrConnDone,
+			checkError: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, sql.ErrConnDone)
+				assert.NotErrorIs(t, err, ErrUnauthenticated)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tokenMaker, err := token.NewPasetoMaker(util.RandomString(32))
+			require.NoError(t, err)
+
+			refreshToken, payload, err := tokenMaker.CreateToken(username, tc.tokenType, tc.duration)
+			require.NoError(t, err)
+			session := db.Session{ID: payload.ID, Username: username, RefreshToken: refreshToken, ExpiresAt: payload.ExpiredAt}
+			if tc.buildSession != nil {
+				tc.buildSession(&session)
+			}
+			if tc.getErr != nil {
+				session = db.Session{}
+			}
+			store.EXPECT().GetSession(gomock.Any(), gomock.Eq(payload.ID)).AnyTimes().Return(session, tc.getErr)
+
+			_, err = NewBank(store).RefreshSession(context.Background(), tokenMaker, refreshToken)
+			tc.checkError(t, err)
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+	"github.com/arpangoswami/backend-golang-dev/webhook"
+)
+
+// ownedSubscription loads the webhook subscription and checks it belongs to the caller
+func (bank *Bank) ownedSubscription(ctx context.Context, username string, id int64) (db.WebhookSubscription, error) {
+	subscription, err := bank.store.GetWebhookSubscription(ctx, id)
+	if err != nil {
+		return db.WebhookSubscription{}, err
+	}
+	if subscription.Owner != username {
+		return db.WebhookSubscription{}, fmt.Errorf("%w: webhook subscription [%d] doesn't belong to %s", ErrForbidden, id, username)
+	}
+	return subscription, nil
+}
+
+// CreateWebhookSubscription subscribes url to the given events of the caller's accounts and
+// transfers. The returned subscription holds the signing secret, which is only handed out once.
+// url must be https and not point into the server's network, see webhook.CheckURL.
+func (bank *Bank) CreateWebhookSubscription(ctx context.Context, url string, eventTypes []string) (db.WebhookSubscription, error) {
+	username, err := caller(ctx)
+	if err != nil {
+		return db.WebhookSubscription{}, err
+	}
+	if err := webhook.CheckURL(ctx, url); err != nil {
+		return db.WebhookSubscription{}, err
+	}
+	secret, err := webhook.NewSecret()
+	if err != nil {
+		return db.WebhookSubscription{}, err
+	}
+	return bank.store.CreateWebhookSubscription(ctx, db.CreateWebhookSubscriptionParams{
+		Owner:      username,
+		Url:        url,
+		EventTypes: eventTypes,
+		Secret:     secret,
+	})
+}
+
+// ListWebhookSubscriptions returns the caller's webhook subscriptions, oldest first
+func (bank *Bank) ListWebhookSubscriptions(ctx context.Context) ([]db.WebhookSubscription, error) {
+	username, err := caller(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return bank.store.ListWebhookSubscriptions(ctx, username)
+}
+
+// DeleteWebhookSubscription deletes one of the caller's webhook subscriptions with its deliveries
+func (bank *Bank) DeleteWebhookSubscription(ctx context.Context, id int64) (db.WebhookSubscription, error) {
+	username, err := caller(ctx)
+	if err != nil {
+		return db.WebhookSubscription{}, err
+	}
+	if _, err := bank.ownedSubscription(ctx, username, id); err != nil {
+		return db.WebhookSubscription{}, err
+	}
+	return bank.store.DeleteWebhookSubscription(ctx, db.DeleteWebhookSubscriptionParams{
+		ID:    id,
+		Owner: username,
+	})
+}
+
+// ListWebhookDeliveriesParams selects a page of the delivery history of a subscription
+type ListWebhookDeliveriesParams struct {
+	SubscriptionID int64
+	// Status keeps only the deliveries in this status, all of them when empty
+	Status   string
+	AfterID  int64
+	PageSize int32
+}
+
+// ListWebhookDeliveries returns the deliveries of a subscription owned by the caller, oldest first
+func (bank *Bank) ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]db.WebhookDelivery, error) {
+	username, err := caller(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := bank.ownedSubscription(ctx, username, arg.SubscriptionID); err != nil {
+		return nil, err
+	}
+	return bank.store.ListWebhookDeliveries(ctx, db.ListWebhookDeliveriesParams{
+		SubscriptionID: arg.SubscriptionID,
+		Status:         sql.NullString{String: arg.Status, Valid: arg.Status != ""},
+		AfterID:        arg.AfterID,
+		LimitCount:     arg.PageSize,
+	})
+}
+
+// ReplayWebhookDelivery sends a delivery of a subscription owned by the caller again, with a
+// fresh set of attempts, whether it succeeded or is dead
+func (bank *Bank) ReplayWebhookDelivery(ctx context.Context, subscriptionID, deliveryID int64) (db.WebhookDelivery, error) {
+	username, err := caller(ctx)
+	if err != nil {
+		return db.WebhookDelivery{}, err
+	}
+	if _, err := bank.ownedSubscription(ctx, username, subscriptionID); err != nil {
+		return db.WebhookDelivery{}, err
+	}
+	delivery, err := bank.store.GetWebhookDelivery(ctx, deliveryID)
+	if err != nil {
+		return db.WebhookDelivery{}, err
+	}
+	if delivery.SubscriptionID != subscriptionID {
+		return db.WebhookDelivery{}, fmt.Errorf("delivery [%d] of subscription [%d]: %w", deliveryID, subscriptionID, sql.ErrNoRows)
+	}
+	return bank.store.ReplayWebhookDelivery(ctx, deliveryID)
+}
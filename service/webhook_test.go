@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	mockdb "github.com/arpangoswami/backend-golang-dev/database/mock"
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+	"github.com/arpangoswami/backend-golang-dev/util"
+	"github.com/arpangoswami/backend-golang-dev/webhook"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestBank_CreateWebhookSubscription(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	username := util.RandomOwner()
+	eventTypes := []string{db.EventTransferCompleted}
+
+	store.EXPECT().CreateWebhookSubscription(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, arg db.CreateWebhookSubscriptionParams) (db.WebhookSubscription, error) {
+			assert.Equal(t, username, arg.Owner)
+			assert.Equal(t, "https://example.com/hook", arg.Url)
+			assert.Equal(t, eventTypes, arg.EventTypes)
+			assert.NotEmpty(t, arg.Secret)
+			return db.WebhookSubscription{ID: 1, Owner: arg.Owner, Secret: arg.Secret}, nil
+		})
+
+	subscription, err := NewBank(store).CreateWebhookSubscription(contextAs(t, username), "https://example.com/hook", eventTypes)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, subscription.Secret)
+
+	_, err = NewBank(store).CreateWebhookSubscription(context.Background(), "https://example.com/hook", eventTypes)
+	assert.ErrorIs(t, err, ErrUnauthenticated)
+
+	// deliveries are never sent in clear or into the server's network
+	for _, url := range []string{"http://example.com/hook", "https://127.0.0.1/hook", "https://169.254.169.254/latest/meta-data"} {
+		_, err = NewBank(store).CreateWebhookSubscription(contextAs(t, username), url, eventTypes)
+		assert.ErrorIs(t, err, webhook.ErrForbiddenDestination, url)
+	}
+}
+
+func TestBank_ListWebhookDeliveries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	subscription := db.WebhookSubscription{ID: 3, Owner: util.RandomOwner()}
+	store.EXPECT().GetWebhookSubscription(gomock.Any(), gomock.Eq(subscription.ID)).Times(2).Return(subscription, nil)
+
+	arg := db.ListWebhookDeliveriesParams{
+		SubscriptionID: subscription.ID,
+		Status:         sql.NullString{String: db.DeliveryDead, Valid: true},
+		AfterID:        10,
+		LimitCount:     5,
+	}
+	store.EXPECT().ListWebhookDeliveries(gomock.Any(), gomock.Eq(arg)).Times(1).Return([]db.WebhookDelivery{}, nil)
+
+	bank := NewBank(store)
+	params := ListWebhookDeliveriesParams{SubscriptionID: subscription.ID, Status: db.DeliveryDead, AfterID: 10, PageSize: 5}
+	_, err := bank.ListWebhookDeliveries(contextAs(t, subscription.Owner), params)
+	assert.NoError(t, err)
+
+	_, err = bank.ListWebhookDeliveries(contextAs(t, "someoneelse"), params)
+	assert.ErrorIs(t, err, ErrForbidden)
+}
+
+func TestBank_ReplayWebhookDelivery(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	subscription := db.WebhookSubscription{ID: 3, Owner: util.RandomOwner()}
+	store.EXPECT().GetWebhookSubscription(gomock.Any(), gomock.Eq(subscription.ID)).AnyTimes().Return(subscription, nil)
+	store.EXPECT().GetWebhookDelivery(gomock.Any(), gomock.Eq(int64(8))).Times(1).
+		Return(db.WebhookDelivery{ID: 8, SubscriptionID: subscription.ID, Status: db.DeliveryDead}, nil)
+	store.EXPECT().GetWebhookDelivery(gomock.Any(), gomock.Eq(int64(9))).Times(1).
+		Return(db.WebhookDelivery{ID: 9, SubscriptionID: subscription.ID + 1}, nil)
+	store.EXPECT().ReplayWebhookDelivery(gomock.Any(), gomock.Eq(int64(8))).Times(1).
+		Return(db.WebhookDelivery{ID: 8, SubscriptionID: subscription.ID, Status: db.DeliveryPending}, nil)
+
+	bank := NewBank(store)
+	ctx := contextAs(t, subscription.Owner)
+	delivery, err := bank.ReplayWebhookDelivery(ctx, subscription.ID, 8)
+	assert.NoError(t, err)
+	assert.Equal(t, db.DeliveryPending, delivery.Status)
+
+	// deliveries of other subscriptions are reported as missing
+	_, err = bank.ReplayWebhookDelivery(ctx, subscription.ID, 9)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	_, err = bank.ReplayWebhookDelivery(contextAs(t, "someoneelse"), subscription.ID, 8)
+	assert.ErrorIs(t, err, ErrForbidden)
+}
@@ -0,0 +1,16 @@
+package token
+
+import "context"
+
+type payloadContextKey struct{}
+
+// NewContext returns a copy of ctx carrying the payload of the authenticated user
+func NewContext(ctx context.Context, payload *Payload) context.Context {
+	return context.WithValue(ctx, payloadContextKey{}, payload)
+}
+
+// FromContext returns the payload of the authenticated user stored in ctx, if any
+func FromContext(ctx context.Context) (*Payload, bool) {
+	payload, ok := ctx.Value(payloadContextKey{}).(*Payload)
+	return payload, ok && payload != nil
+}
@@ -0,0 +1,74 @@
+package token
+
+import (
+	"testing"
+	"time"
+
+	"github.com/arpangoswami/backend-golang-dev/util"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJWTMaker(t *testing.T) {
+	maker, err := NewJWTMaker(util.RandomString(32))
+	assert.NoError(t, err)
+
+	username := util.RandomOwner()
+	duration := time.Minute
+	issuedAt := time.Now()
+	expiredAt := issuedAt.Add(duration)
+
+	token, payload, err := maker.CreateToken(username, TokenTypeAccess, duration)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, token)
+	assert.NotEmpty(t, payload)
+
+	payload, err = maker.VerifyToken(token)
+	assert.NoError(t, err)
+	assert.NotZero(t, payload.ID)
+	assert.Equal(t, username, payload.Username)
+	assert.Equal(t, TokenTypeAccess, payload.TokenType)
+	assert.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
+	assert.WithinDuration(t, expiredAt, payload.ExpiredAt, time.Second)
+}
+
+func TestJWTMaker_ExpiredToken(t *testing.T) {
+	maker, err := NewJWTMaker(util.RandomString(32))
+	assert.NoError(t, err)
+
+	token, _, err := maker.CreateToken(util.RandomOwner(), TokenTypeAccess, -time.Minute)
+	assert.NoError(t, err)
+
+	payload, err := maker.VerifyToken(token)
+	assert.EqualError(t, err, ErrExpiredToken.Error())
+	assert.Nil(t, payload)
+}
+
+func TestJWTMaker_InvalidAlgNone(t *testing.T) {
+	payload, err := NewPayload(util.RandomOwner(), TokenTypeAccess, time.Minute)
+	assert.NoError(t, err)
+
+	claims := jwtClaims{
+		Username: payload.Username,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        payload.ID.String(),
+			IssuedAt:  jwt.NewNumericDate(payload.IssuedAt),
+			ExpiresAt: jwt.NewNumericDate(payload.ExpiredAt),
+		},
+	}
+	jwtToken := jwt.NewWithClaims(jwt.SigningMethodNone, claims)
+	token, err := jwtToken.SignedString(jwt.UnsafeAllowNoneSignatureType)
+	assert.NoError(t, err)
+
+	maker, err := NewJWTMaker(util.RandomString(32))
+	assert.NoError(t, err)
+
+	payload, err = maker.VerifyToken(token)
+	assert.EqualError(t, err, ErrInvalidToken.Error())
+	assert.Nil(t, payload)
+}
+
+func TestNewJWTMaker_ShortKey(t *testing.T) {
+	_, err := NewJWTMaker(util.RandomString(minSecretKeySize - 1))
+	assert.Error(t, err)
+}
@@ -0,0 +1,58 @@
+package token
+
+import (
+	"testing"
+	"time"
+
+	"github.com/arpangoswami/backend-golang-dev/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPasetoMaker(t *testing.T) {
+	maker, err := NewPasetoMaker(util.RandomString(32))
+	assert.NoError(t, err)
+
+	username := util.RandomOwner()
+	duration := time.Minute
+	issuedAt := time.Now()
+	expiredAt := issuedAt.Add(duration)
+
+	token, payload, err := maker.CreateToken(username, TokenTypeAccess, duration)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, token)
+	assert.NotEmpty(t, payload)
+
+	payload, err = maker.VerifyToken(token)
+	assert.NoError(t, err)
+	assert.NotZero(t, payload.ID)
+	assert.Equal(t, username, payload.Username)
+	assert.Equal(t, TokenTypeAccess, payload.TokenType)
+	assert.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
+	assert.WithinDuration(t, expiredAt, payload.ExpiredAt, time.Second)
+}
+
+func TestPasetoMaker_ExpiredToken(t *testing.T) {
+	maker, err := NewPasetoMaker(util.RandomString(32))
+	assert.NoError(t, err)
+
+	token, _, err := maker.CreateToken(util.RandomOwner(), TokenTypeAccess, -time.Minute)
+	assert.NoError(t, err)
+
+	payload, err := maker.VerifyToken(token)
+	assert.EqualError(t, err, ErrExpiredToken.Error())
+	assert.Nil(t, payload)
+}
+
+func TestPasetoMaker_WrongKey(t *testing.T) {
+	maker1, err := NewPasetoMaker(util.RandomString(32))
+	assert.NoError(t, err)
+	maker2, err := NewPasetoMaker(util.RandomString(32))
+	assert.NoError(t, err)
+
+	token, _, err := maker1.CreateToken(util.RandomOwner(), TokenTypeAccess, time.Minute)
+	assert.NoError(t, err)
+
+	payload, err := maker2.VerifyToken(token)
+	assert.EqualError(t, err, ErrInvalidToken.Error())
+	assert.Nil(t, payload)
+}
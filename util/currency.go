@@ -0,0 +1,33 @@
+package util
+
+import "database/sql"
+
+// Constants for all supported currencies
+const (
+	USD = "USD"
+	INR = "INR"
+	EUR = "EUR"
+	GBP = "GBP"
+	JPY = "JPY"
+)
+
+// countryCodes maps every supported currency to the country code stored alongside an account
+var countryCodes = map[string]int32{
+	USD: 0,
+	INR: 1,
+	EUR: 2,
+	GBP: 3,
+	JPY: 4,
+}
+
+// IsSupportedCurrency returns true if the currency is supported
+func IsSupportedCurrency(currency string) bool {
+	_, ok := countryCodes[currency]
+	return ok
+}
+
+// CountryCodeForCurrency returns the country code of a supported currency
+func CountryCodeForCurrency(currency string) sql.NullInt32 {
+	code, ok := countryCodes[currency]
+	return sql.NullInt32{Int32: code, Valid: ok}
+}
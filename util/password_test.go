@@ -0,0 +1,24 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestPassword(t *testing.T) {
+	password := RandomString(6)
+
+	hashedPassword1, err := HashPassword(password)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, hashedPassword1)
+	assert.NoError(t, CheckPassword(password, hashedPassword1))
+
+	wrongPassword := RandomString(6)
+	assert.EqualError(t, CheckPassword(wrongPassword, hashedPassword1), bcrypt.ErrMismatchedHashAndPassword.Error())
+
+	hashedPassword2, err := HashPassword(password)
+	assert.NoError(t, err)
+	assert.NotEqual(t, hashedPassword1, hashedPassword2)
+}
@@ -55,28 +55,15 @@ type CurrencyCountryCode struct {
 }
 
 func RandomCurrencyCodeCountryCode() CurrencyCountryCode {
-	currencyCodes := []CurrencyCountryCode{
-		{
-			CurrencyCode: "USD",
-			CountryCode:  sql.NullInt32{Int32: 0, Valid: true},
-		},
-		{
-			CurrencyCode: "INR",
-			CountryCode:  sql.NullInt32{Int32: 1, Valid: true},
-		},
-		{
-			CurrencyCode: "EUR",
-			CountryCode:  sql.NullInt32{Int32: 2, Valid: true},
-		},
-		{
-			CurrencyCode: "GBP",
-			CountryCode:  sql.NullInt32{Int32: 3, Valid: true},
-		},
-		{
-			CurrencyCode: "JPY",
-			CountryCode:  sql.NullInt32{Int32: 4, Valid: true},
-		},
+	currencies := []string{USD, INR, EUR, GBP, JPY}
+	currency := currencies[rand.Intn(len(currencies))]
+	return CurrencyCountryCode{
+		CurrencyCode: currency,
+		CountryCode:  CountryCodeForCurrency(currency),
 	}
-	n := len(currencyCodes)
-	return currencyCodes[rand.Intn(n)]
+}
+
+// RandomEmail generates a random email
+func RandomEmail() string {
+	return RandomString(6) + "@email.com"
 }
@@ -0,0 +1,47 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckURL(t *testing.T) {
+	ctx := context.Background()
+	assert.NoError(t, CheckURL(ctx, "https://93.184.215.14/hook"))
+	assert.NoError(t, CheckURL(ctx, "https://[2606:2800:21f:cb07:6820:80da:af6b:8b2c]:8443/hook"))
+
+	for _, url := range []string{
+		"http://93.184.215.14/hook",
+		"/hook",
+		"https://127.0.0.1/hook",
+		"https://localhost/hook",
+		"https://10.1.2.3/hook",
+		"https://192.168.0.1/hook",
+		"https://169.254.169.254/latest/meta-data",
+		"https://0.0.0.0/hook",
+		"https://[::1]/hook",
+		"https://[::ffff:127.0.0.1]/hook",
+		"https://[fe80::1]/hook",
+	} {
+		assert.ErrorIs(t, CheckURL(ctx, url), ErrForbiddenDestination, url)
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer receiver.Close()
+
+	// the receiver listens on loopback, which the connection check refuses
+	client := NewClient(time.Second)
+	_, err := client.Post(receiver.URL, "application/json", nil)
+	assert.ErrorIs(t, err, ErrForbiddenDestination)
+
+	assert.ErrorIs(t, client.CheckRedirect(nil, nil), http.ErrUseLastResponse)
+}
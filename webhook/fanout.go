@@ -0,0 +1,97 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+)
+
+// Event is the JSON body posted to the subscribers
+type Event struct {
+	// ID is the id of the outbox event, receivers can use it to drop duplicates
+	ID        int64           `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// Fanout is an outbox publisher queueing a delivery of every event for each subscription
+// of the users it concerns. The deliveries are sent later by a Worker.
+type Fanout struct {
+	store db.Store
+}
+
+// NewFanout returns a publisher writing the deliveries to the store
+func NewFanout(store db.Store) *Fanout {
+	return &Fanout{store: store}
+}
+
+// Publish queues the deliveries of event. Publishing an event again doesn't queue it twice.
+func (fanout *Fanout) Publish(ctx context.Context, event db.OutboxEvent) error {
+	owners, data, err := subject(event)
+	if err != nil {
+		return err
+	}
+	if len(owners) == 0 {
+		return nil
+	}
+
+	// the event is published right after its txn committed, read the subscriptions from the
+	// primary so that one made just before it isn't missed on a lagging replica
+	subscriptions, err := fanout.store.ListWebhookSubscriptionsForEvent(db.WithPrimary(ctx), db.ListWebhookSubscriptionsForEventParams{
+		Owners:    owners,
+		EventType: event.EventType,
+	})
+	if err != nil || len(subscriptions) == 0 {
+		return err
+	}
+
+	payload, err := json.Marshal(Event{
+		ID:        event.ID,
+		Type:      event.EventType,
+		CreatedAt: event.CreatedAt,
+		Data:      data,
+	})
+	if err != nil {
+		return fmt.Errorf("marshal webhook event: %w", err)
+	}
+	for _, subscription := range subscriptions {
+		err := fanout.store.CreateWebhookDelivery(ctx, db.CreateWebhookDeliveryParams{
+			SubscriptionID: subscription.ID,
+			EventID:        event.ID,
+			EventType:      event.EventType,
+			Payload:        payload,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// subject returns the users an event concerns and the data sent to them. A transfer
+// concerns the owners of both accounts, who only get the transfer and not each other's balance.
+func subject(event db.OutboxEvent) ([]string, json.RawMessage, error) {
+	switch event.AggregateType {
+	case db.EntityAccount:
+		var account db.Account
+		if err := json.Unmarshal(event.Payload, &account); err != nil {
+			return nil, nil, fmt.Errorf("event [%d]: %w", event.ID, err)
+		}
+		return []string{account.Owner}, event.Payload, nil
+	case db.EntityTransfer:
+		var result db.TransferTransactionResult
+		if err := json.Unmarshal(event.Payload, &result); err != nil {
+			return nil, nil, fmt.Errorf("event [%d]: %w", event.ID, err)
+		}
+		data, err := json.Marshal(result.Transfer)
+		if err != nil {
+			return nil, nil, fmt.Errorf("event [%d]: %w", event.ID, err)
+		}
+		return []string{result.FromAccount.Owner, result.ToAccount.Owner}, data, nil
+	}
+	return nil, nil, nil
+}
@@ -0,0 +1,80 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	mockdb "github.com/arpangoswami/backend-golang-dev/database/mock"
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+	"github.com/arpangoswami/backend-golang-dev/util"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func transferEvent(t *testing.T, from, to string) (db.OutboxEvent, db.TransferTransactionResult) {
+	t.Helper()
+	result := db.TransferTransactionResult{
+		Transfer:    db.Transfer{ID: util.RandomInt(1, 1000), FromAccountID: 1, ToAccountID: 2, Amount: 10},
+		FromAccount: db.Account{ID: 1, Owner: from, Balance: 90},
+		ToAccount:   db.Account{ID: 2, Owner: to, Balance: 110},
+	}
+	payload, err := json.Marshal(result)
+	assert.NoError(t, err)
+	return db.OutboxEvent{
+		ID:            util.RandomInt(1, 1000),
+		AggregateType: db.EntityTransfer,
+		AggregateID:   result.Transfer.ID,
+		EventType:     db.EventTransferCompleted,
+		Payload:       payload,
+		CreatedAt:     time.Now().UTC().Truncate(time.Second),
+	}, result
+}
+
+func TestFanout_Publish(t *testing.T) {
+	store := mockdb.NewMockStore(gomock.NewController(t))
+	event, result := transferEvent(t, "alice", "bob")
+	subscriptions := []db.WebhookSubscription{{ID: 3, Owner: "alice"}, {ID: 4, Owner: "bob"}}
+
+	store.EXPECT().ListWebhookSubscriptionsForEvent(gomock.Cond(db.OnPrimary), gomock.Eq(db.ListWebhookSubscriptionsForEventParams{
+		Owners:    []string{"alice", "bob"},
+		EventType: db.EventTransferCompleted,
+	})).Times(1).Return(subscriptions, nil)
+
+	var queued []db.CreateWebhookDeliveryParams
+	store.EXPECT().CreateWebhookDelivery(gomock.Any(), gomock.Any()).Times(2).
+		DoAndReturn(func(_ context.Context, arg db.CreateWebhookDeliveryParams) error {
+			queued = append(queued, arg)
+			return nil
+		})
+
+	assert.NoError(t, NewFanout(store).Publish(context.Background(), event))
+	assert.Len(t, queued, 2)
+	for i, arg := range queued {
+		assert.Equal(t, subscriptions[i].ID, arg.SubscriptionID)
+		assert.Equal(t, event.ID, arg.EventID)
+		assert.Equal(t, event.EventType, arg.EventType)
+
+		// the owners get the transfer, not the balance of the other account
+		var body Event
+		assert.NoError(t, json.Unmarshal(arg.Payload, &body))
+		assert.Equal(t, event.ID, body.ID)
+		assert.Equal(t, event.EventType, body.Type)
+		assert.True(t, event.CreatedAt.Equal(body.CreatedAt))
+		var transfer db.Transfer
+		assert.NoError(t, json.Unmarshal(body.Data, &transfer))
+		assert.Equal(t, result.Transfer, transfer)
+		assert.NotContains(t, string(arg.Payload), "balance")
+	}
+}
+
+func TestFanout_PublishNoSubscription(t *testing.T) {
+	store := mockdb.NewMockStore(gomock.NewController(t))
+	event, _ := transferEvent(t, "alice", "bob")
+
+	store.EXPECT().ListWebhookSubscriptionsForEvent(gomock.Any(), gomock.Any()).Times(1).Return(nil, nil)
+	store.EXPECT().CreateWebhookDelivery(gomock.Any(), gomock.Any()).Times(0)
+
+	assert.NoError(t, NewFanout(store).Publish(context.Background(), event))
+}
@@ -0,0 +1,40 @@
+package webhook
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignVerify(t *testing.T) {
+	secret, err := NewSecret()
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(secret, secretPrefix))
+
+	body := []byte(`{"id":1,"type":"transfer.completed"}`)
+	sentAt := time.Now()
+	header := Sign(secret, sentAt, body)
+	assert.NoError(t, Verify(secret, header, body, time.Minute, sentAt.Add(time.Second)))
+
+	testCases := []struct {
+		name   string
+		secret string
+		header string
+		body   string
+		now    time.Time
+		err    error
+	}{
+		{"TamperedBody", secret, header, `{"id":2,"type":"transfer.completed"}`, sentAt, ErrInvalidSignature},
+		{"WrongSecret", "whsec_other", header, string(body), sentAt, ErrInvalidSignature},
+		{"Expired", secret, header, string(body), sentAt.Add(2 * time.Minute), ErrExpiredSignature},
+		{"MissingTimestamp", secret, header[strings.Index(header, ",")+1:], string(body), sentAt, ErrInvalidSignature},
+		{"Malformed", secret, "garbage", string(body), sentAt, ErrInvalidSignature},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.ErrorIs(t, Verify(tc.secret, tc.header, []byte(tc.body), time.Minute, tc.now), tc.err)
+		})
+	}
+}
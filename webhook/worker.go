@@ -0,0 +1,180 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+)
+
+// DefaultBatchSize is the number of deliveries a worker claims, and sends concurrently, at once
+const DefaultBatchSize = 10
+
+// maxErrorLength bounds the receiver error kept in the delivery history
+const maxErrorLength = 512
+
+// RetryPolicy decides when a failed delivery is tried again
+type RetryPolicy struct {
+	// MaxAttempts is the number of attempts after which a delivery is dead
+	MaxAttempts int32
+	MinBackoff  time.Duration
+	MaxBackoff  time.Duration
+}
+
+// Backoff returns the delay after the given number of failed attempts,
+// doubling from MinBackoff up to MaxBackoff
+func (policy RetryPolicy) Backoff(attempts int32) time.Duration {
+	backoff := policy.MinBackoff
+	for i := int32(1); i < attempts && backoff < policy.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, policy.MaxBackoff)
+}
+
+// Worker sends the pending deliveries, signed with the secret of their subscription
+type Worker struct {
+	store     db.Store
+	client    *http.Client
+	policy    RetryPolicy
+	interval  time.Duration
+	batchSize int32
+	now       func() time.Time
+}
+
+// NewWorker returns a worker checking for due deliveries every interval while there are none.
+// The client's timeout bounds every attempt.
+func NewWorker(store db.Store, client *http.Client, policy RetryPolicy, interval time.Duration) *Worker {
+	return &Worker{
+		store:     store,
+		client:    client,
+		policy:    policy,
+		interval:  interval,
+		batchSize: DefaultBatchSize,
+		now:       time.Now,
+	}
+}
+
+// DeliverOnce sends one batch of due deliveries and returns how many were attempted.
+// Failed attempts are recorded on the deliveries, only store errors are returned.
+func (worker *Worker) DeliverOnce(ctx context.Context) (int, error) {
+	// claimed deliveries are hidden from other workers until the lease ends, if this one
+	// stops before recording the attempt they are sent again after it
+	deliveries, err := worker.store.ClaimWebhookDeliveries(ctx, db.ClaimWebhookDeliveriesParams{
+		LeaseUntil: worker.now().Add(worker.client.Timeout + time.Minute),
+		LimitCount: worker.batchSize,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(deliveries))
+	for i, delivery := range deliveries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = worker.deliver(ctx, delivery)
+		}()
+	}
+	wg.Wait()
+	return len(deliveries), errors.Join(errs...)
+}
+
+// Run sends deliveries until ctx is cancelled
+func (worker *Worker) Run(ctx context.Context) {
+	for {
+		attempted, err := worker.DeliverOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("webhook worker: %v", err)
+		}
+		if err == nil && attempted == int(worker.batchSize) {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(worker.interval):
+		}
+	}
+}
+
+// deliver makes one attempt and records its outcome. A delivery running out of
+// attempts is dead, otherwise it is retried after the policy's backoff.
+func (worker *Worker) deliver(ctx context.Context, delivery db.WebhookDelivery) error {
+	subscription, err := worker.store.GetWebhookSubscription(ctx, delivery.SubscriptionID)
+	if err != nil {
+		return fmt.Errorf("delivery [%d]: %w", delivery.ID, err)
+	}
+
+	code, sendErr := worker.send(ctx, subscription, delivery)
+	if sendErr == nil {
+		_, err = worker.store.MarkWebhookDeliverySucceeded(ctx, db.MarkWebhookDeliverySucceededParams{
+			ID:           delivery.ID,
+			ResponseCode: int32(code),
+		})
+		return err
+	}
+
+	attempts := delivery.Attempts + 1
+	status := db.DeliveryPending
+	if attempts >= worker.policy.MaxAttempts {
+		status = db.DeliveryDead
+	}
+	message := truncateError(sendErr.Error())
+	_, err = worker.store.MarkWebhookDeliveryFailed(ctx, db.MarkWebhookDeliveryFailedParams{
+		ID:            delivery.ID,
+		Status:        status,
+		NextAttemptAt: worker.now().Add(worker.policy.Backoff(attempts)),
+		ResponseCode:  int32(code),
+		LastError:     message,
+	})
+	return err
+}
+
+// truncateError cuts message to maxErrorLength bytes on a character boundary, as postgres
+// refuses text holding half of a multi-byte UTF-8 character
+func truncateError(message string) string {
+	if len(message) <= maxErrorLength {
+		return message
+	}
+	end := maxErrorLength
+	for end > 0 && !utf8.RuneStart(message[end]) {
+		end--
+	}
+	return message[:end]
+}
+
+// send posts the payload and returns the response status code, any status but 2xx is a failure
+func (worker *Worker) send(ctx context.Context, subscription db.WebhookSubscription, delivery db.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.Url, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(IDHeader, strconv.FormatInt(delivery.EventID, 10))
+	req.Header.Set(EventHeader, delivery.EventType)
+	req.Header.Set(SignatureHeader, Sign(subscription.Secret, worker.now(), delivery.Payload))
+
+	rsp, err := worker.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer rsp.Body.Close()
+	// read a bit of the body so that the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(rsp.Body, 4096))
+
+	if rsp.StatusCode < 200 || rsp.StatusCode > 299 {
+		return rsp.StatusCode, fmt.Errorf("receiver responded %s", rsp.Status)
+	}
+	return rsp.StatusCode, nil
+}
@@ -0,0 +1,162 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	mockdb "github.com/arpangoswami/backend-golang-dev/database/mock"
+	db "github.com/arpangoswami/backend-golang-dev/database/sqlc"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+var testPolicy = RetryPolicy{MaxAttempts: 3, MinBackoff: time.Second, MaxBackoff: 10 * time.Second}
+
+// receiver is a local subscriber verifying the signature of every request
+type receiver struct {
+	t      *testing.T
+	secret string
+	status int
+	events []Event
+}
+
+func (receiver *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	assert.NoError(receiver.t, err)
+	assert.NoError(receiver.t, Verify(receiver.secret, r.Header.Get(SignatureHeader), body, time.Minute, time.Now()))
+	assert.Equal(receiver.t, "application/json", r.Header.Get("Content-Type"))
+
+	var event Event
+	assert.NoError(receiver.t, json.Unmarshal(body, &event))
+	assert.Equal(receiver.t, strconv.FormatInt(event.ID, 10), r.Header.Get(IDHeader))
+	assert.Equal(receiver.t, event.Type, r.Header.Get(EventHeader))
+	receiver.events = append(receiver.events, event)
+	w.WriteHeader(receiver.status)
+}
+
+func newTestWorker(t *testing.T, status int) (*Worker, *mockdb.MockStore, *receiver, db.WebhookSubscription) {
+	t.Helper()
+	secret, err := NewSecret()
+	assert.NoError(t, err)
+	receiver := &receiver{t: t, secret: secret, status: status}
+	server := httptest.NewServer(receiver)
+	t.Cleanup(server.Close)
+
+	store := mockdb.NewMockStore(gomock.NewController(t))
+	subscription := db.WebhookSubscription{ID: 5, Owner: "alice", Url: server.URL, Secret: secret}
+	worker := NewWorker(store, &http.Client{Timeout: time.Second}, testPolicy, time.Second)
+	return worker, store, receiver, subscription
+}
+
+func randomDelivery(t *testing.T, subscriptionID int64, attempts int32) db.WebhookDelivery {
+	t.Helper()
+	payload, err := json.Marshal(Event{ID: 42, Type: db.EventTransferCompleted, Data: json.RawMessage(`{"id":7}`)})
+	assert.NoError(t, err)
+	return db.WebhookDelivery{
+		ID:             9,
+		SubscriptionID: subscriptionID,
+		EventID:        42,
+		EventType:      db.EventTransferCompleted,
+		Payload:        payload,
+		Status:         db.DeliveryPending,
+		Attempts:       attempts,
+	}
+}
+
+func TestWorker_DeliverOnce(t *testing.T) {
+	worker, store, receiver, subscription := newTestWorker(t, http.StatusNoContent)
+	delivery := randomDelivery(t, subscription.ID, 0)
+
+	store.EXPECT().ClaimWebhookDeliveries(gomock.Any(), gomock.Any()).Times(1).Return([]db.WebhookDelivery{delivery}, nil)
+	store.EXPECT().GetWebhookSubscription(gomock.Any(), gomock.Eq(subscription.ID)).Times(1).Return(subscription, nil)
+	store.EXPECT().MarkWebhookDeliverySucceeded(gomock.Any(), gomock.Eq(db.MarkWebhookDeliverySucceededParams{
+		ID:           delivery.ID,
+		ResponseCode: http.StatusNoContent,
+	})).Times(1)
+
+	attempted, err := worker.DeliverOnce(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, attempted)
+	if assert.Len(t, receiver.events, 1) {
+		assert.Equal(t, int64(42), receiver.events[0].ID)
+		assert.JSONEq(t, `{"id":7}`, string(receiver.events[0].Data))
+	}
+}
+
+func TestWorker_DeliverOnceFailure(t *testing.T) {
+	testCases := []struct {
+		name     string
+		attempts int32
+		status   string
+		backoff  time.Duration
+	}{
+		{"Retried", 0, db.DeliveryPending, time.Second},
+		{"BackoffDoubles", 1, db.DeliveryPending, 2 * time.Second},
+		{"DeadAfterMaxAttempts", 2, db.DeliveryDead, 4 * time.Second},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			worker, store, _, subscription := newTestWorker(t, http.StatusServiceUnavailable)
+			now := time.Now()
+			worker.now = func() time.Time { return now }
+			delivery := randomDelivery(t, subscription.ID, tc.attempts)
+
+			store.EXPECT().ClaimWebhookDeliveries(gomock.Any(), gomock.Any()).Times(1).Return([]db.WebhookDelivery{delivery}, nil)
+			store.EXPECT().GetWebhookSubscription(gomock.Any(), gomock.Eq(subscription.ID)).Times(1).Return(subscription, nil)
+			store.EXPECT().MarkWebhookDeliveryFailed(gomock.Any(), gomock.Eq(db.MarkWebhookDeliveryFailedParams{
+				ID:            delivery.ID,
+				Status:        tc.status,
+				NextAttemptAt: now.Add(tc.backoff),
+				ResponseCode:  http.StatusServiceUnavailable,
+				LastError:     "receiver responded 503 Service Unavailable",
+			})).Times(1)
+
+			_, err := worker.DeliverOnce(context.Background())
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestWorker_DeliverOnceUnreachable(t *testing.T) {
+	worker, store, _, subscription := newTestWorker(t, http.StatusOK)
+	subscription.Url = "http://127.0.0.1:1/hook"
+	delivery := randomDelivery(t, subscription.ID, 0)
+
+	store.EXPECT().ClaimWebhookDeliveries(gomock.Any(), gomock.Any()).Times(1).Return([]db.WebhookDelivery{delivery}, nil)
+	store.EXPECT().GetWebhookSubscription(gomock.Any(), gomock.Any()).Times(1).Return(subscription, nil)
+	store.EXPECT().MarkWebhookDeliveryFailed(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, arg db.MarkWebhookDeliveryFailedParams) (db.WebhookDelivery, error) {
+			assert.Equal(t, db.DeliveryPending, arg.Status)
+			assert.Zero(t, arg.ResponseCode)
+			assert.Contains(t, arg.LastError, "connection refused")
+			return db.WebhookDelivery{}, nil
+		})
+
+	_, err := worker.DeliverOnce(context.Background())
+	assert.NoError(t, err)
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	assert.Equal(t, time.Second, testPolicy.Backoff(1))
+	assert.Equal(t, 8*time.Second, testPolicy.Backoff(4))
+	assert.Equal(t, 10*time.Second, testPolicy.Backoff(5))
+	assert.Equal(t, 10*time.Second, testPolicy.Backoff(60))
+}
+
+func TestTruncateError(t *testing.T) {
+	message := "receiver responded 503 " + strings.Repeat("服务不可用", maxErrorLength/10)
+	truncated := truncateError(message)
+	assert.LessOrEqual(t, len(truncated), maxErrorLength)
+	assert.True(t, utf8.ValidString(truncated))
+	assert.True(t, strings.HasPrefix(message, truncated))
+
+	assert.Equal(t, "receiver responded 503", truncateError("receiver responded 503"))
+}